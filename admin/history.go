@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// historyPrefix is the key prefix of the entries of the rule history.
+const historyPrefix = "rules/history/"
+
+// RuleChange is an entry of the rule history of Rules: the rules created,
+// or the IDs of the rules deleted, by a request.
+type RuleChange struct {
+	Time    time.Time      `json:"time"`
+	Added   []*stream.Rule `json:"added,omitempty"`
+	Deleted []string       `json:"deleted,omitempty"`
+}
+
+// record stores change in the history, keyed by its time so that keys sort
+// in the order of changes.
+func (a *Rules) record(ctx context.Context, change RuleChange) error {
+	value, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%020d", historyPrefix, change.Time.UnixNano())
+	return a.History.Set(ctx, key, value, 0)
+}
+
+// history returns the rule history, oldest first.
+func (a *Rules) history(ctx context.Context) ([]RuleChange, error) {
+	var keys []string
+	changes := make(map[string]RuleChange)
+	err := a.History.Scan(ctx, historyPrefix, func(key string, value []byte) error {
+		var change RuleChange
+		if err := json.Unmarshal(value, &change); err != nil {
+			return fmt.Errorf("rule history entry %s: %w", strings.TrimPrefix(key, historyPrefix), err)
+		}
+		keys = append(keys, key)
+		changes[key] = change
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	history := make([]RuleChange, 0, len(keys))
+	for _, key := range keys {
+		history = append(history, changes[key])
+	}
+	return history, nil
+}
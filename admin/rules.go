@@ -0,0 +1,165 @@
+// Package admin serves HTTP APIs for operating a stream at runtime.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// maxBodySize bounds the bodies of requests.
+const maxBodySize = 1 << 20
+
+// Rules serves the rules of the filtered stream at /rules, proxying to the
+// rules endpoints of Twitter:
+//
+//	GET /rules                                   lists the rules
+//	POST /rules {"add":[{"value":..,"tag":..}]}  adds rules
+//	DELETE /rules {"ids":[..]} or ?id=..&id=..   deletes rules
+//
+// POST and DELETE take ?dry_run=true to validate a change without applying
+// it. Responses are the RulesResponse of Twitter, with 400 Bad Request if
+// Twitter refused any of the rules and 502 Bad Gateway if the request
+// failed.
+//
+// With a History store, each change applied is recorded as a RuleChange,
+// and GET /rules?history=true lists them, oldest first, as {"data":[..]}.
+type Rules struct {
+	srv   *stream.StreamService
+	token string
+	// OnChange, if set, is called after rules were added or deleted, e.g.
+	// to refresh the stream with Stream.Rotate or Stream.Reconnect.
+	OnChange func(ctx context.Context)
+	// History, if set, keeps the rule history. A change whose recording
+	// fails is answered with 500 Internal Server Error, although applied.
+	History state.Store
+}
+
+// NewRules returns a Rules API changing the rules of srv, for requests
+// with the bearer token. An empty token disables authentication.
+func NewRules(srv *stream.StreamService, token string) *Rules {
+	return &Rules{srv: srv, token: token}
+}
+
+func (a *Rules) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+a.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	params := &stream.RulesParams{DryRun: dryRun}
+	var resp *stream.RulesResponse
+	var err error
+	var change RuleChange
+	switch r.Method {
+	case http.MethodGet:
+		if history, _ := strconv.ParseBool(r.URL.Query().Get("history")); history {
+			a.serveHistory(w, r)
+			return
+		}
+		var rules []*stream.Rule
+		if rules, err = a.srv.GetRules(r.Context()); err == nil {
+			resp = &stream.RulesResponse{Data: rules}
+		}
+	case http.MethodPost:
+		var body struct {
+			Add []*stream.Rule `json:"add"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Add) == 0 {
+			writeError(w, http.StatusBadRequest, errors.New(`expected {"add":[{"value":...,"tag":...}]}`))
+			return
+		}
+		resp, err = a.srv.AddRules(r.Context(), body.Add, params)
+		if resp != nil {
+			change.Added = resp.Data
+		}
+	case http.MethodDelete:
+		ids := r.URL.Query()["id"]
+		if len(ids) == 0 {
+			var body struct {
+				IDs []string `json:"ids"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.IDs) == 0 {
+				writeError(w, http.StatusBadRequest, errors.New(`expected {"ids":[...]} or ?id=`))
+				return
+			}
+			ids = body.IDs
+		}
+		resp, err = a.srv.DeleteRules(r.Context(), ids, params)
+		change.Deleted = ids
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	var ruleErrs stream.RuleErrors
+	var quotaErr *stream.QuotaError
+	if errors.As(err, &quotaErr) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil && !errors.As(err, &ruleErrs) {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !dryRun && changed(resp) {
+		if a.OnChange != nil {
+			a.OnChange(r.Context())
+		}
+		if a.History != nil {
+			change.Time = time.Now()
+			if err := a.record(r.Context(), change); err != nil {
+				writeError(w, http.StatusInternalServerError, fmt.Errorf("rules changed, but recording the change failed: %w", err))
+				return
+			}
+		}
+	}
+	if ruleErrs != nil {
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// serveHistory answers GET /rules?history=true.
+func (a *Rules) serveHistory(w http.ResponseWriter, r *http.Request) {
+	if a.History == nil {
+		writeError(w, http.StatusNotFound, errors.New("rule history is not kept"))
+		return
+	}
+	history, err := a.history(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]RuleChange{"data": history})
+}
+
+// changed reports whether rules were created or deleted, which they may be
+// even if Twitter refused others.
+func changed(resp *stream.RulesResponse) bool {
+	if resp == nil || resp.Meta == nil || resp.Meta.Summary == nil {
+		return false
+	}
+	return resp.Meta.Summary.Created > 0 || resp.Meta.Summary.Deleted > 0
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,100 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/admin"
+	"github.com/kalvin807/twitter-v2-stream/state"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// twitter answers rule changes like the rules endpoint, creating or
+// deleting every rule.
+func twitter(t *testing.T) *stream.StreamService {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Add    []*stream.Rule `json:"add"`
+			Delete struct {
+				IDs []string `json:"ids"`
+			} `json:"delete"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		resp := stream.RulesResponse{Meta: &stream.RulesMeta{Summary: &stream.RulesSummary{}}}
+		for i, rule := range body.Add {
+			resp.Data = append(resp.Data, &stream.Rule{ID: string(rune('1' + i)), Value: rule.Value, Tag: rule.Tag})
+			resp.Meta.Summary.Created++
+		}
+		resp.Meta.Summary.Deleted = len(body.Delete.IDs)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return stream.NewStreamService(srv.Client(), "token", stream.WithBaseURL(srv.URL))
+}
+
+func TestRulesHistory(t *testing.T) {
+	rules := admin.NewRules(twitter(t), "")
+	rules.History = state.NewMemory()
+	requests := []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPost, "/rules", `{"add":[{"value":"golang","tag":"go"}]}`},
+		{http.MethodPost, "/rules?dry_run=true", `{"add":[{"value":"rust"}]}`},
+		{http.MethodDelete, "/rules?id=1", ""},
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		rules.ServeHTTP(rec, httptest.NewRequest(req.method, req.target, strings.NewReader(req.body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: status %d: %s", req.method, req.target, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	rules.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rules?history=true", nil))
+	var resp struct {
+		Data []admin.RuleChange `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		wantAdded   string
+		wantDeleted string
+	}{
+		{"add", "golang", ""},
+		{"delete", "", "1"},
+	}
+	if len(resp.Data) != len(tests) {
+		t.Fatalf("history of %d changes, want %d: %+v", len(resp.Data), len(tests), resp.Data)
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := resp.Data[i]
+			var added, deleted string
+			if len(change.Added) > 0 {
+				added = change.Added[0].Value
+			}
+			if len(change.Deleted) > 0 {
+				deleted = change.Deleted[0]
+			}
+			if added != tt.wantAdded || deleted != tt.wantDeleted || change.Time.IsZero() {
+				t.Errorf("change %+v, want added %q, deleted %q", change, tt.wantAdded, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestRulesHistoryDisabled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	admin.NewRules(twitter(t), "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rules?history=true", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
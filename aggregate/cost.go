@@ -0,0 +1,179 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// billingPeriod approximates the month the project Tweet cap applies to.
+const billingPeriod = 30 * 24 * time.Hour
+
+// UsageSource returns the Tweet consumption of the project, such as
+// stream.StreamService.
+type UsageSource interface {
+	Usage(ctx context.Context) (*stream.Usage, error)
+}
+
+// RuleCost is the Tweet consumption of a rule tag. A Tweet matching several
+// rules counts towards each of them, but only once towards the cap.
+type RuleCost struct {
+	Tag     string `json:"tag"`
+	Matches int64  `json:"matches"`
+	// Share is the share of the matches of all rules.
+	Share float64 `json:"share"`
+	// CapShare is the share of the project cap consumed so far.
+	CapShare float64 `json:"cap_share"`
+	// ProjectedCapShare extrapolates CapShare to a full billing period at the
+	// rate observed since Start.
+	ProjectedCapShare float64 `json:"projected_cap_share"`
+}
+
+// CostReport is the Tweet consumption of the rules since Start, in relation
+// to the project Tweet cap. Rules are sorted by descending Matches.
+type CostReport struct {
+	Start        time.Time   `json:"start"`
+	End          time.Time   `json:"end"`
+	ProjectCap   int64       `json:"project_cap"`
+	ProjectUsage int64       `json:"project_usage"`
+	CapResetDay  int         `json:"cap_reset_day"`
+	Rules        []*RuleCost `json:"rules"`
+}
+
+// Record returns the report as a stream message without a Tweet, annotated
+// with the report under "cost_report", for writing the report to a sink.
+func (r *CostReport) Record() *stream.StreamData {
+	msg := &stream.StreamData{}
+	msg.SetAnnotation("cost_report", r)
+	return msg
+}
+
+// CostReporter counts the matches of each rule tag and periodically reports
+// their share of the project Tweet cap, to find expensive rules worth
+// pruning. Safe for concurrent use.
+type CostReporter struct {
+	usage UsageSource
+	emit  func(*CostReport)
+
+	mu      sync.Mutex
+	start   time.Time
+	matches map[string]int64
+	last    *CostReport
+}
+
+// NewCostReporter returns a CostReporter querying the cap from usage. emit
+// may be nil.
+func NewCostReporter(usage UsageSource, emit func(*CostReport)) *CostReporter {
+	return &CostReporter{
+		usage:   usage,
+		emit:    emit,
+		start:   time.Now(),
+		matches: make(map[string]int64),
+	}
+}
+
+// Add counts the message towards each of its tags.
+func (c *CostReporter) Add(msg *stream.StreamData) {
+	if msg.Tweet == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rule := range msg.MatchingRules {
+		c.matches[rule.Tag]++
+	}
+}
+
+// Run reports every interval until ctx is done. Failed usage queries are
+// skipped until the next interval.
+func (c *CostReporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Report(ctx)
+		}
+	}
+}
+
+// Report queries the project usage, emits the report and returns it.
+func (c *CostReporter) Report(ctx context.Context) (*CostReport, error) {
+	usage, err := c.usage.Usage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	report := c.snapshot(usage, time.Now())
+	c.last = report
+	c.mu.Unlock()
+	if c.emit != nil {
+		c.emit(report)
+	}
+	return report, nil
+}
+
+// Reset restarts counting, e.g. when the cap resets.
+func (c *CostReporter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = time.Now()
+	c.matches = make(map[string]int64)
+}
+
+// Last returns the last report, or nil.
+func (c *CostReporter) Last() *CostReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// ServeHTTP serves the last report as JSON, for mounting on an admin server.
+func (c *CostReporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.Last())
+}
+
+// snapshot returns the report of the counts. c.mu must be held.
+func (c *CostReporter) snapshot(usage *stream.Usage, end time.Time) *CostReport {
+	report := &CostReport{
+		Start:        c.start,
+		End:          end,
+		ProjectCap:   usage.ProjectCap,
+		ProjectUsage: usage.ProjectUsage,
+		CapResetDay:  usage.CapResetDay,
+		Rules:        make([]*RuleCost, 0, len(c.matches)),
+	}
+	var total int64
+	for _, n := range c.matches {
+		total += n
+	}
+	elapsed := end.Sub(c.start)
+	for tag, n := range c.matches {
+		cost := &RuleCost{Tag: tag, Matches: n}
+		if total > 0 {
+			cost.Share = float64(n) / float64(total)
+		}
+		if usage.ProjectCap > 0 {
+			cost.CapShare = float64(n) / float64(usage.ProjectCap)
+			if elapsed > 0 {
+				cost.ProjectedCapShare = cost.CapShare * float64(billingPeriod) / float64(elapsed)
+			}
+		}
+		report.Rules = append(report.Rules, cost)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].Matches != report.Rules[j].Matches {
+			return report.Rules[i].Matches > report.Rules[j].Matches
+		}
+		return report.Rules[i].Tag < report.Rules[j].Tag
+	})
+	return report
+}
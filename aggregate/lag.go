@@ -0,0 +1,106 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// LagStats summarizes the lag between the creation of Tweets and their
+// delivery, corrected for clock skew.
+type LagStats struct {
+	Count int           `json:"count"`
+	Last  time.Duration `json:"last"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Mean  time.Duration `json:"mean"`
+	// Clamped counts lags which were negative within the tolerance and
+	// counted as zero.
+	Clamped int `json:"clamped"`
+	// Skewed counts lags which were negative beyond the tolerance, which
+	// means the clock offset is wrong. They are not counted otherwise.
+	Skewed int `json:"skewed"`
+}
+
+// LagTracker measures how long Tweets took from creation to delivery. The
+// local clock is corrected by Offset, e.g. skew.Fixed or the Offset method of
+// a skew.SNTP, and negative lags up to Tolerance, due to residual skew, are
+// counted as zero. Requires the created_at Tweet field. Safe for concurrent
+// use.
+type LagTracker struct {
+	Offset    func() time.Duration
+	Tolerance time.Duration
+	// OnAlert, if set, is called with lags above AlertAbove.
+	AlertAbove time.Duration
+	OnAlert    func(lag time.Duration, msg *stream.StreamData)
+
+	mu    sync.Mutex
+	stats LagStats
+	total time.Duration
+}
+
+// Observe records the lag of the message at the time of the call and
+// returns it. ok is false for messages without created_at or with a lag
+// beyond the skew tolerance.
+func (t *LagTracker) Observe(msg *stream.StreamData) (lag time.Duration, ok bool) {
+	if msg.Tweet == nil || msg.Tweet.CreatedAt.IsZero() {
+		return 0, false
+	}
+	now := time.Now()
+	if t.Offset != nil {
+		now = now.Add(t.Offset())
+	}
+	lag = now.Sub(msg.Tweet.CreatedAt)
+
+	t.mu.Lock()
+	if lag < 0 {
+		if -lag > t.Tolerance {
+			t.stats.Skewed++
+			t.mu.Unlock()
+			return lag, false
+		}
+		lag = 0
+		t.stats.Clamped++
+	}
+	if t.stats.Count == 0 || lag < t.stats.Min {
+		t.stats.Min = lag
+	}
+	if lag > t.stats.Max {
+		t.stats.Max = lag
+	}
+	t.stats.Count++
+	t.stats.Last = lag
+	t.total += lag
+	t.stats.Mean = t.total / time.Duration(t.stats.Count)
+	t.mu.Unlock()
+
+	if t.OnAlert != nil && t.AlertAbove > 0 && lag > t.AlertAbove {
+		t.OnAlert(lag, msg)
+	}
+	return lag, true
+}
+
+// Stats returns the lag statistics since the tracker was created or reset.
+func (t *LagTracker) Stats() LagStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// Reset clears the statistics.
+func (t *LagTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = LagStats{}
+	t.total = 0
+}
+
+// ServeHTTP serves the lag statistics as JSON, for mounting on an admin
+// server.
+func (t *LagTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.Stats())
+}
@@ -0,0 +1,160 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// hllPrecision is the precision of the HyperLogLog of each tag and window.
+const hllPrecision = 14
+
+// Reach is the aggregate of a rule tag over a window.
+type Reach struct {
+	Tag   string    `json:"tag"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Tweets is the number of Tweets matching the tag.
+	Tweets int `json:"tweets"`
+	// UniqueAuthors is the estimated number of distinct authors.
+	UniqueAuthors uint64 `json:"unique_authors"`
+	// EstimatedReach estimates the number of accounts the Tweets reached: the
+	// unique authors weighted by the mean follower count of the authors seen.
+	// Requires the author_id expansion and the public_metrics user field.
+	EstimatedReach uint64 `json:"estimated_reach"`
+}
+
+type reachBucket struct {
+	tweets        int
+	authors       *HyperLogLog
+	followers     uint64
+	followerCount int
+}
+
+// ReachAggregator aggregates unique authors and reach per matching rule tag
+// over fixed windows. Closed windows are passed to the emit function as
+// aggregate events. Safe for concurrent use.
+type ReachAggregator struct {
+	window time.Duration
+	emit   func([]*Reach)
+
+	mu      sync.Mutex
+	start   time.Time
+	buckets map[string]*reachBucket
+	last    []*Reach
+}
+
+// NewReachAggregator returns a ReachAggregator with the window length. emit
+// may be nil.
+func NewReachAggregator(window time.Duration, emit func([]*Reach)) *ReachAggregator {
+	return &ReachAggregator{
+		window:  window,
+		emit:    emit,
+		start:   time.Now(),
+		buckets: make(map[string]*reachBucket),
+	}
+}
+
+// Add counts the message towards the current window of each of its tags.
+func (a *ReachAggregator) Add(msg *stream.StreamData) {
+	if msg.Tweet == nil {
+		return
+	}
+	author := msg.Author()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, rule := range msg.MatchingRules {
+		b, ok := a.buckets[rule.Tag]
+		if !ok {
+			b = &reachBucket{authors: NewHyperLogLog(hllPrecision)}
+			a.buckets[rule.Tag] = b
+		}
+		b.tweets++
+		if msg.Tweet.AuthorID != "" {
+			b.authors.Add(msg.Tweet.AuthorID)
+		}
+		if author != nil && author.PublicMetrics != nil {
+			b.followers += uint64(author.PublicMetrics.FollowersCount)
+			b.followerCount++
+		}
+	}
+}
+
+// Run closes a window every window length until ctx is done.
+func (a *ReachAggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Flush()
+		}
+	}
+}
+
+// Flush closes the current window, emits its aggregates and returns them.
+func (a *ReachAggregator) Flush() []*Reach {
+	a.mu.Lock()
+	end := time.Now()
+	reaches := a.snapshot(end)
+	a.start = end
+	a.buckets = make(map[string]*reachBucket)
+	a.last = reaches
+	a.mu.Unlock()
+	if a.emit != nil {
+		a.emit(reaches)
+	}
+	return reaches
+}
+
+// Current returns the aggregates of the window in progress.
+func (a *ReachAggregator) Current() []*Reach {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.snapshot(time.Now())
+}
+
+// Last returns the aggregates of the last closed window.
+func (a *ReachAggregator) Last() []*Reach {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+// ServeHTTP serves the aggregates of the current and last closed window as
+// JSON, for mounting on an admin server.
+func (a *ReachAggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Current []*Reach `json:"current"`
+		Last    []*Reach `json:"last"`
+	}{a.Current(), a.Last()})
+}
+
+// snapshot returns the aggregates of the buckets, sorted by tag. a.mu must be
+// held.
+func (a *ReachAggregator) snapshot(end time.Time) []*Reach {
+	reaches := make([]*Reach, 0, len(a.buckets))
+	for tag, b := range a.buckets {
+		r := &Reach{
+			Tag:           tag,
+			Start:         a.start,
+			End:           end,
+			Tweets:        b.tweets,
+			UniqueAuthors: b.authors.Count(),
+		}
+		if b.followerCount > 0 {
+			r.EstimatedReach = r.UniqueAuthors * (b.followers / uint64(b.followerCount))
+		}
+		reaches = append(reaches, r)
+	}
+	sort.Slice(reaches, func(i, j int) bool { return reaches[i].Tag < reaches[j].Tag })
+	return reaches
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// runBackfill implements the backfill command: it searches the Tweets of the
+// stream rules within a time range and prints them like streamed Tweets,
+// e.g.
+//
+//	twstream backfill --rule-tag x --from 2024-05-01 --to 2024-05-02
+func runBackfill(ctx context.Context, client *http.Client, args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	tag := flags.String("rule-tag", "", "only backfill rules with this tag")
+	fromFlag := flags.String("from", "", "start of the range, as date or RFC 3339 time")
+	toFlag := flags.String("to", "", "end of the range, as date or RFC 3339 time (default now)")
+	fields := addFieldFlags(flags)
+	output := flags.String("output", "id", "output format: "+strings.Join(outputFormats, ", "))
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	params, err := fields.params()
+	if err != nil {
+		return err
+	}
+	out, err := newPrinter(*output, os.Stdout, tweetColumns...)
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	srv, err := newService(ctx, client)
+	if err != nil {
+		return err
+	}
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	to := time.Now().Add(-10 * time.Second)
+	if *toFlag != "" {
+		if to, err = parseTime(*toFlag); err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+	}
+
+	rules, err := srv.GetRules(ctx)
+	if err != nil {
+		return err
+	}
+	var selected []*stream.Rule
+	for _, rule := range rules {
+		if *tag == "" || rule.Tag == *tag {
+			selected = append(selected, rule)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no rules with tag %q", *tag)
+	}
+
+	return srv.Backfill(ctx, selected, from, to, params, func(msg *stream.StreamData) error {
+		return printTweet(out, msg)
+	})
+}
+
+// parseTime parses a date such as 2024-05-01 or an RFC 3339 time.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
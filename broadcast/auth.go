@@ -0,0 +1,117 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+)
+
+// Authorizer authorizes a client of a Hub by its request, returning the rule
+// tags whose messages the client may receive, or false to reject it.
+type Authorizer func(r *http.Request) (tags []string, ok bool)
+
+var (
+	// errUnauthorized rejects clients the Authorizer does not authorize.
+	errUnauthorized = errors.New("unauthorized")
+	// errForbiddenTag rejects subscriptions to tags outside of those
+	// authorized.
+	errForbiddenTag = errors.New("tag not permitted")
+)
+
+// Authorize makes the hub serve only the clients authorized by a, e.g.
+// tenant.Registry.Authorizer, over SSE, WebSocket and gRPC alike. Authorized
+// clients subscribe to all of their tags by default, may only subscribe to
+// those, and receive the messages matching them with only their own
+// matching rules. Authorize must be called before serving clients.
+func (h *Hub) Authorize(a Authorizer) {
+	h.authorizer = a
+}
+
+// authorize returns the tags a client requesting the tags subscribes to, and
+// those it may receive, nil if not restricted.
+func (h *Hub) authorize(r *http.Request, requested map[string]bool) (tags, allowed map[string]bool, err error) {
+	if h.authorizer == nil {
+		return requested, nil, nil
+	}
+	permitted, ok := h.authorizer(r)
+	if !ok {
+		return nil, nil, errUnauthorized
+	}
+	allowed = make(map[string]bool, len(permitted))
+	for _, tag := range permitted {
+		allowed[tag] = true
+	}
+	if requested == nil {
+		tags = make(map[string]bool, len(allowed))
+		for tag := range allowed {
+			tags[tag] = true
+		}
+		return tags, allowed, nil
+	}
+	for tag := range requested {
+		if !allowed[tag] {
+			return nil, nil, errForbiddenTag
+		}
+	}
+	return requested, allowed, nil
+}
+
+// authError answers a plain HTTP request rejected by authorize.
+func authError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnauthorized) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusForbidden)
+}
+
+// BearerToken returns the token of the Authorization: Bearer header of r, or
+// of its token query parameter, which browsers have to use since they cannot
+// set headers on EventSource and WebSocket connections.
+func BearerToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// restricted returns the event of msg for a client allowed only some of its
+// tags, which lists only the matching rules of those tags. Events are
+// encoded once per set of visible rules, in views.
+func restricted(msg *stream.StreamData, e *event, allowed map[string]bool, views map[string]*event) (*event, error) {
+	var key strings.Builder
+	var rules []*stream.MatchingRule
+	for _, rule := range msg.MatchingRules {
+		if allowed[rule.Tag] {
+			rules = append(rules, rule)
+			key.WriteString(rule.Id)
+			key.WriteByte(',')
+		}
+	}
+	if len(rules) == len(msg.MatchingRules) {
+		return e, nil
+	}
+	if view, ok := views[key.String()]; ok {
+		return view, nil
+	}
+	copied := *msg
+	copied.MatchingRules = rules
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		return nil, err
+	}
+	view := &event{id: e.id, data: data}
+	if e.proto != nil {
+		view.proto = streampb.NewTweet(&copied)
+	}
+	for _, rule := range rules {
+		view.tags = append(view.tags, rule.Tag)
+	}
+	views[key.String()] = view
+	return view, nil
+}
@@ -0,0 +1,286 @@
+// Package broadcast rebroadcasts stream messages to HTTP clients, such as
+// web frontends, as Server-Sent Events or over WebSockets, and to gRPC
+// clients.
+package broadcast
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+	"google.golang.org/grpc"
+)
+
+// keepAliveInterval is the interval of comments keeping idle connections
+// open through proxies.
+const keepAliveInterval = 15 * time.Second
+
+// event is a message encoded once for all clients.
+type event struct {
+	id   string
+	tags []string
+	data []byte
+	// proto is the Tweet message of the message, set while gRPC clients
+	// are connected
+	proto *streampb.Tweet
+}
+
+// client is a connected client, receiving the events matching its tags.
+type client struct {
+	// tags are the tags the client subscribed to, nil for all, guarded by
+	// the mutex of the hub
+	tags   map[string]bool
+	events chan *event
+	// done is closed once the hub closes
+	done chan struct{}
+	// evict clients are disconnected, closing evicted, once their buffer
+	// is full, instead of missing messages
+	evict   bool
+	evicted chan struct{}
+	// proto clients receive the Tweet messages of events
+	proto bool
+	// allowed are the tags an authorized client may receive, nil if the hub
+	// does not authorize clients
+	allowed map[string]bool
+}
+
+// clientKind is how a client is served.
+type clientKind int
+
+const (
+	// dropClient misses messages while its buffer is full.
+	dropClient clientKind = iota
+	// evictClient is disconnected once its buffer is full.
+	evictClient
+	// protoClient misses messages while its buffer is full, and receives
+	// them as Tweet messages.
+	protoClient
+)
+
+// accepts reports whether the client subscribed to any of the tags.
+func (c *client) accepts(tags []string) bool {
+	if c.tags == nil {
+		return true
+	}
+	for _, tag := range tags {
+		if c.tags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// Hub rebroadcasts the messages written to it to its connected clients. It
+// is a sink.Sink, so it can be pumped from a subscription of a stream:
+//
+//	hub := broadcast.NewHub(64)
+//	http.HandleFunc("/sse", hub.ServeSSE)
+//	go sink.Pump(s.Subscribe(16, stream.OverflowDropOldest).Messages, hub, nil)
+//
+// Each client has its own buffer, and misses messages while it is full, so
+// slow clients never hold back the stream or each other.
+type Hub struct {
+	buffer  int
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+	done    chan struct{}
+	dropped uint64
+	evicted uint64
+	// protoClients is the number of connected protobuf clients
+	protoClients int64
+	// authorizer authorizes clients, if set by Authorize
+	authorizer Authorizer
+	// origins are the origins allowed to open WebSockets, see AllowOrigins
+	origins []string
+	// grpcServer serves the Relay service for ServeGRPC, once created
+	grpcOnce   sync.Once
+	grpcServer *grpc.Server
+}
+
+// NewHub returns a Hub buffering up to buffer messages per client.
+func NewHub(buffer int) *Hub {
+	return &Hub{buffer: buffer, clients: make(map[*client]struct{}), done: make(chan struct{})}
+}
+
+// Write sends the message to the clients subscribed to any of its tags.
+// Messages without a Tweet are skipped.
+func (h *Hub) Write(msg *stream.StreamData) error {
+	if msg.Tweet == nil {
+		return nil
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	e := &event{id: msg.Tweet.ID, data: data}
+	if atomic.LoadInt64(&h.protoClients) > 0 {
+		e.proto = streampb.NewTweet(msg)
+	}
+	for _, rule := range msg.MatchingRules {
+		e.tags = append(e.tags, rule.Tag)
+	}
+	var views map[string]*event
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if !c.accepts(e.tags) || c.proto && e.proto == nil {
+			continue
+		}
+		ce := e
+		if c.allowed != nil {
+			if views == nil {
+				views = make(map[string]*event)
+			}
+			if ce, err = restricted(msg, e, c.allowed, views); err != nil {
+				return err
+			}
+		}
+		select {
+		case c.events <- ce:
+		default:
+			if c.evict {
+				delete(h.clients, c)
+				close(c.evicted)
+				atomic.AddUint64(&h.evicted, 1)
+				continue
+			}
+			atomic.AddUint64(&h.dropped, 1)
+		}
+	}
+	return nil
+}
+
+// Close disconnects all clients and refuses new ones.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Dropped returns the number of messages clients missed because their
+// buffer was full.
+func (h *Hub) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Evicted returns the number of WebSocket clients disconnected for falling
+// behind.
+func (h *Hub) Evicted() uint64 {
+	return atomic.LoadUint64(&h.evicted)
+}
+
+// queryTags returns the tags of the tag query parameters, repeated or comma
+// separated, or nil without.
+func queryTags(r *http.Request) map[string]bool {
+	var tags map[string]bool
+	for _, values := range r.URL.Query()["tag"] {
+		for _, tag := range strings.Split(values, ",") {
+			if tag == "" {
+				continue
+			}
+			if tags == nil {
+				tags = make(map[string]bool)
+			}
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// connect registers a client subscribed to the tags, or to all messages if
+// nil, and allowed to receive the allowed tags, or all if nil.
+func (h *Hub) connect(tags, allowed map[string]bool, kind clientKind) (*client, bool) {
+	c := &client{
+		tags:    tags,
+		allowed: allowed,
+		events:  make(chan *event, h.buffer),
+		done:    h.done,
+		proto:   kind == protoClient,
+		evict:   kind == evictClient,
+		evicted: make(chan struct{}),
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	h.clients[c] = struct{}{}
+	if c.proto {
+		atomic.AddInt64(&h.protoClients, 1)
+	}
+	return c, true
+}
+
+func (h *Hub) disconnect(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+	if c.proto {
+		atomic.AddInt64(&h.protoClients, -1)
+	}
+}
+
+// ServeSSE streams messages to the client as Server-Sent Events until it
+// disconnects, each a "tweet" event with the Tweet ID as event ID and the
+// message as data. Clients may subscribe to rule tags with tag query
+// parameters, e.g. /sse?tag=news&tag=sports.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	tags, allowed, err := h.authorize(r, queryTags(r))
+	if err != nil {
+		authError(w, err)
+		return
+	}
+	c, ok := h.connect(tags, allowed, dropClient)
+	if !ok {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.disconnect(c)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.done:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case e := <-c.events:
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: tweet\ndata: %s\n\n", e.id, e.data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
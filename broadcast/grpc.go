@@ -0,0 +1,111 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCSubscribePath is the path of the Relay.Subscribe method of
+// streampb.proto.
+const GRPCSubscribePath = streampb.Relay_Subscribe_FullMethodName
+
+// ServeGRPC serves the Relay service of streampb.proto, see Relay, on an
+// HTTP server. gRPC requires HTTP/2, so the server must serve HTTP/2, over
+// TLS or unencrypted, e.g. with http.Protocols.SetUnencryptedHTTP2.
+func (h *Hub) ServeGRPC(w http.ResponseWriter, r *http.Request) {
+	h.grpcOnce.Do(func() {
+		h.grpcServer = grpc.NewServer()
+		streampb.RegisterRelayServer(h.grpcServer, h.Relay())
+	})
+	// the Authorizer is passed the request itself
+	ctx := context.WithValue(r.Context(), requestKey{}, r)
+	h.grpcServer.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Relay returns the Relay service of streampb.proto, to register with a
+// grpc.Server:
+//
+//	streampb.RegisterRelayServer(server, hub.Relay())
+//
+// It streams the Tweets matching the tags of the FilterRequest of a client
+// until it cancels. Like ServeSSE clients, gRPC clients miss Tweets while
+// they fall behind. Hubs which Authorize clients pass the Authorizer a
+// request with the metadata of the call as headers.
+func (h *Hub) Relay() streampb.RelayServer {
+	return relay{hub: h}
+}
+
+// requestKey is the context key of the HTTP request of calls served by
+// ServeGRPC.
+type requestKey struct{}
+
+// relay implements the Relay service of a hub.
+type relay struct {
+	streampb.UnimplementedRelayServer
+	hub *Hub
+}
+
+func (rs relay) Subscribe(req *streampb.FilterRequest, ss grpc.ServerStreamingServer[streampb.Tweet]) error {
+	h := rs.hub
+	ctx := ss.Context()
+	var subscribed map[string]bool
+	for _, tag := range req.GetTags() {
+		if subscribed == nil {
+			subscribed = make(map[string]bool)
+		}
+		subscribed[tag] = true
+	}
+	subscribed, allowed, err := h.authorize(callRequest(ctx), subscribed)
+	switch {
+	case errors.Is(err, errUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	c, ok := h.connect(subscribed, allowed, protoClient)
+	if !ok {
+		return status.Error(codes.Unavailable, "shutting down")
+	}
+	defer h.disconnect(c)
+	// send the headers right away, so clients know they are subscribed
+	if err := ss.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-c.done:
+			return status.Error(codes.Unavailable, "shutting down")
+		case e := <-c.events:
+			if err := ss.Send(e.proto); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// callRequest returns the HTTP request of a call served by ServeGRPC, or one
+// with the metadata of the call as headers.
+func callRequest(ctx context.Context) *http.Request {
+	if r, ok := ctx.Value(requestKey{}).(*http.Request); ok {
+		return r
+	}
+	header := make(http.Header)
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	r := &http.Request{Method: http.MethodPost, URL: &url.URL{Path: GRPCSubscribePath}, Header: header}
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,140 @@
+package broadcast_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/broadcast"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokens authorizes the bearer token "news" for the news tag only.
+func tokens(r *http.Request) ([]string, bool) {
+	if broadcast.BearerToken(r) == "news" {
+		return []string{"news"}, true
+	}
+	return nil, false
+}
+
+// serveHTTP serves the hub with ServeGRPC over HTTP/2 without TLS.
+func serveHTTP(t *testing.T, hub *broadcast.Hub) string {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(hub.ServeGRPC))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+// serveRelay serves the Relay of the hub on a grpc.Server.
+func serveRelay(t *testing.T, hub *broadcast.Hub) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	streampb.RegisterRelayServer(server, hub.Relay())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestRelaySubscribe(t *testing.T) {
+	servers := []struct {
+		name  string
+		serve func(*testing.T, *broadcast.Hub) string
+	}{
+		{"ServeGRPC", serveHTTP},
+		{"Relay", serveRelay},
+	}
+	tests := []struct {
+		name      string
+		authorize bool
+		token     string
+		tags      []string
+		wantCode  codes.Code
+		wantIDs   []string
+		wantRules []string
+	}{
+		{name: "open all tags", wantIDs: []string{"1", "2", "3"}, wantRules: []string{"news", "sports"}},
+		{name: "open filtered", tags: []string{"sports"}, wantIDs: []string{"2"}, wantRules: []string{"news", "sports"}},
+		{name: "no token", authorize: true, wantCode: codes.Unauthenticated},
+		{name: "wrong token", authorize: true, token: "sports", wantCode: codes.Unauthenticated},
+		{name: "forbidden tag", authorize: true, token: "news", tags: []string{"sports"}, wantCode: codes.PermissionDenied},
+		{name: "authorized", authorize: true, token: "news", wantIDs: []string{"1", "2"}, wantRules: []string{"news"}},
+	}
+	for _, server := range servers {
+		for _, tt := range tests {
+			t.Run(server.name+"/"+tt.name, func(t *testing.T) {
+				hub := broadcast.NewHub(8)
+				if tt.authorize {
+					hub.Authorize(tokens)
+				}
+				defer hub.Close()
+				conn, err := grpc.NewClient(server.serve(t, hub), grpc.WithTransportCredentials(insecure.NewCredentials()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer conn.Close()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if tt.token != "" {
+					ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+				}
+				sub, err := streampb.NewRelayClient(conn).Subscribe(ctx, &streampb.FilterRequest{Tags: tt.tags})
+				if err == nil {
+					// the headers arrive once the client is subscribed
+					_, err = sub.Header()
+				}
+				if err == nil && tt.wantCode != codes.OK {
+					_, err = sub.Recv()
+				}
+				if got := status.Code(err); got != tt.wantCode {
+					t.Fatalf("code %v (%v), want %v", got, err, tt.wantCode)
+				}
+				if tt.wantCode != codes.OK {
+					return
+				}
+
+				hub.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "1"}, MatchingRules: []*stream.MatchingRule{{Id: "10", Tag: "news"}}})
+				hub.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "2"}, MatchingRules: []*stream.MatchingRule{{Id: "10", Tag: "news"}, {Id: "11", Tag: "sports"}}})
+				hub.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "3"}, MatchingRules: []*stream.MatchingRule{{Id: "12", Tag: "weather"}}})
+				for _, id := range tt.wantIDs {
+					tweet, err := sub.Recv()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if tweet.GetId() != id {
+						t.Fatalf("Tweet %s, want %s", tweet.GetId(), id)
+					}
+					if id == "2" {
+						var rules []string
+						for _, rule := range tweet.GetMatchingRules() {
+							rules = append(rules, rule.GetTag())
+						}
+						if !reflect.DeepEqual(rules, tt.wantRules) {
+							t.Fatalf("rules %q, want %q", rules, tt.wantRules)
+						}
+					}
+				}
+
+				hub.Close()
+				if _, err := sub.Recv(); status.Code(err) != codes.Unavailable {
+					t.Fatalf("after Close: %v, want %v", err, codes.Unavailable)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	// pingInterval is the interval of pings to clients. Clients which do
+	// not answer a ping within an interval are disconnected.
+	pingInterval = 15 * time.Second
+	// writeTimeout bounds writing a message to a client.
+	writeTimeout = 10 * time.Second
+	// maxCommandSize bounds the messages of clients.
+	maxCommandSize = 4096
+)
+
+// command is a message of a WebSocket client.
+type command struct {
+	// Type is subscribe, unsubscribe or ping.
+	Type string   `json:"type"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// reply is a message to a WebSocket client other than a Tweet.
+type reply struct {
+	// Type is subscribed, pong or error.
+	Type  string   `json:"type"`
+	Tags  []string `json:"tags,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// AllowOrigins lets browsers open WebSockets from pages of the origins
+// matching the patterns, such as "app.example.com" or "*.example.com", see
+// path.Match, in addition to pages of the host of the hub. AllowOrigins
+// must be called before serving clients.
+func (h *Hub) AllowOrigins(patterns ...string) {
+	h.origins = append(h.origins, patterns...)
+}
+
+// ServeWebSocket upgrades the request to a WebSocket and streams messages
+// to the client as text messages {"type":"tweet","id":...,"data":...}
+// until it disconnects. Clients start subscribed to the tags of the tag
+// query parameters, or to all messages without, and manage their
+// subscriptions with JSON commands:
+//
+//	{"type":"subscribe","tags":["news"]}
+//	{"type":"unsubscribe","tags":["news"]}
+//	{"type":"ping"}
+//
+// which are answered with the tags subscribed to after the command, as
+// {"type":"subscribed","tags":[...]}, or {"type":"pong"}. The first
+// subscribe of a client subscribed to all messages narrows it to the tags.
+// Unlike ServeSSE clients, clients falling behind by more than the buffer
+// of the hub are disconnected, as are clients not answering pings. Browsers
+// may only connect from pages of the host of the hub, see AllowOrigins.
+func (h *Hub) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	tags, allowed, err := h.authorize(r, queryTags(r))
+	if err != nil {
+		authError(w, err)
+		return
+	}
+	c, ok := h.connect(tags, allowed, evictClient)
+	if !ok {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.disconnect(c)
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.origins})
+	if err != nil {
+		// Accept answered the request
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(maxCommandSize)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		h.readCommands(ctx, conn, c)
+	}()
+	pingFailed := make(chan struct{})
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case <-c.done:
+			conn.Close(websocket.StatusGoingAway, "shutting down")
+			return
+		case <-c.evicted:
+			conn.Close(websocket.StatusPolicyViolation, "too slow")
+			return
+		case <-pingFailed:
+			conn.Close(websocket.StatusPolicyViolation, "ping timeout")
+			return
+		case <-ping.C:
+			// pongs are read by readCommands
+			go func() {
+				pingCtx, cancel := context.WithTimeout(ctx, pingInterval)
+				defer cancel()
+				if conn.Ping(pingCtx) != nil && ctx.Err() == nil {
+					select {
+					case pingFailed <- struct{}{}:
+					default:
+					}
+				}
+			}()
+		case e := <-c.events:
+			msg := make([]byte, 0, len(e.data)+64)
+			msg = append(msg, `{"type":"tweet","id":`...)
+			msg = appendJSONString(msg, e.id)
+			msg = append(msg, `,"data":`...)
+			msg = append(msg, e.data...)
+			msg = append(msg, '}')
+			err = write(ctx, conn, msg)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readCommands answers the commands of the client until it disconnects,
+// closes the connection or sends a message over maxCommandSize, which the
+// connection answers with a close frame.
+func (h *Hub) readCommands(ctx context.Context, conn *websocket.Conn, c *client) {
+	for {
+		typ, payload, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ != websocket.MessageText {
+			continue
+		}
+		data, err := json.Marshal(h.command(c, payload))
+		if err == nil {
+			err = write(ctx, conn, data)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// write writes a text message within writeTimeout.
+func write(ctx context.Context, conn *websocket.Conn, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, msg)
+}
+
+// command applies a command of the client and returns the reply.
+func (h *Hub) command(c *client, payload []byte) reply {
+	var cmd command
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		return reply{Type: "error", Error: "invalid command: " + err.Error()}
+	}
+	switch cmd.Type {
+	case "ping":
+		return reply{Type: "pong"}
+	case "subscribe", "unsubscribe":
+	default:
+		return reply{Type: "error", Error: fmt.Sprintf("unknown command %q", cmd.Type)}
+	}
+	if cmd.Type == "subscribe" && c.allowed != nil {
+		for _, tag := range cmd.Tags {
+			if !c.allowed[tag] {
+				return reply{Type: "error", Error: fmt.Sprintf("%v: %q", errForbiddenTag, tag)}
+			}
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c.tags == nil {
+		c.tags = make(map[string]bool)
+	}
+	for _, tag := range cmd.Tags {
+		if cmd.Type == "subscribe" {
+			c.tags[tag] = true
+		} else {
+			delete(c.tags, tag)
+		}
+	}
+	tags := make([]string, 0, len(c.tags))
+	for tag := range c.tags {
+		tags = append(tags, tag)
+	}
+	return reply{Type: "subscribed", Tags: tags}
+}
+
+// appendJSONString appends s as a JSON string.
+func appendJSONString(dst []byte, s string) []byte {
+	data, _ := json.Marshal(s)
+	return append(dst, data...)
+}
@@ -0,0 +1,205 @@
+package broadcast_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/kalvin807/twitter-v2-stream/broadcast"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// wsClient is a WebSocket client of a hub whose messages are read in the
+// background, so it can ping meanwhile.
+type wsClient struct {
+	conn     *websocket.Conn
+	messages chan string
+	err      chan error
+}
+
+func dialHub(t *testing.T, ctx context.Context, hub *broadcast.Hub, query string, header http.Header) (*wsClient, *http.Response, error) {
+	srv := httptest.NewServer(http.HandlerFunc(hub.ServeWebSocket))
+	t.Cleanup(srv.Close)
+	conn, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+query, &websocket.DialOptions{HTTPHeader: header})
+	if err != nil {
+		return nil, resp, err
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+	c := &wsClient{conn: conn, messages: make(chan string, 16), err: make(chan error, 1)}
+	go func() {
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				c.err <- err
+				return
+			}
+			c.messages <- string(data)
+		}
+	}()
+	return c, resp, nil
+}
+
+func (c *wsClient) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case msg := <-c.messages:
+		return msg
+	case err := <-c.err:
+		t.Fatalf("connection failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+	}
+	return ""
+}
+
+func TestWebSocketCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragments []string
+		want      string
+	}{
+		{"ping", []string{`{"type":"ping"}`}, `{"type":"pong"}`},
+		{"fragmented ping", []string{`{"type":`, `"ping"`, `}`}, `{"type":"pong"}`},
+		{"subscribe", []string{`{"type":"subscribe","tags":["news"]}`}, `{"type":"subscribed","tags":["news"]}`},
+		{"unsubscribe", []string{`{"type":"unsubscribe","tags":["news"]}`}, `{"type":"subscribed"}`},
+		{"unknown command", []string{`{"type":"publish"}`}, `{"type":"error","error":"unknown command \"publish\""}`},
+		{"invalid command", []string{`ping`}, `"type":"error"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			c, _, err := dialHub(t, ctx, broadcast.NewHub(8), "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := c.conn.Writer(ctx, websocket.MessageText)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fragment := range tt.fragments {
+				if _, err := w.Write([]byte(fragment)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.next(t); !strings.Contains(got, tt.want) {
+				t.Errorf("reply %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketTweets(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hub := broadcast.NewHub(8)
+	c, _, err := dialHub(t, ctx, hub, "?tag=news", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a command round trip makes sure the client is connected
+	c.conn.Write(ctx, websocket.MessageText, []byte(`{"type":"ping"}`))
+	c.next(t)
+	hub.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "1"}, MatchingRules: []*stream.MatchingRule{{Id: "10", Tag: "sports"}}})
+	hub.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "2"}, MatchingRules: []*stream.MatchingRule{{Id: "11", Tag: "news"}}})
+	var msg struct {
+		Type string            `json:"type"`
+		ID   string            `json:"id"`
+		Data stream.StreamData `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(c.next(t)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "tweet" || msg.ID != "2" || msg.Data.Tweet.ID != "2" {
+		t.Errorf("received %+v, want Tweet 2", msg)
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	tests := []struct {
+		name string
+		do   func(ctx context.Context, hub *broadcast.Hub, c *wsClient) error
+		want websocket.StatusCode
+	}{
+		{"hub closed", func(ctx context.Context, hub *broadcast.Hub, c *wsClient) error {
+			return hub.Close()
+		}, websocket.StatusGoingAway},
+		{"message too big", func(ctx context.Context, hub *broadcast.Hub, c *wsClient) error {
+			return c.conn.Write(ctx, websocket.MessageText, []byte(strings.Repeat(" ", 8192)))
+		}, websocket.StatusMessageTooBig},
+		{"closed by client", func(ctx context.Context, hub *broadcast.Hub, c *wsClient) error {
+			return c.conn.Close(websocket.StatusNormalClosure, "bye")
+		}, websocket.StatusNormalClosure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			hub := broadcast.NewHub(8)
+			c, _, err := dialHub(t, ctx, hub, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// pongs answer pings of the client while connected
+			if err := c.conn.Ping(ctx); err != nil {
+				t.Fatalf("ping: %v", err)
+			}
+			if err := tt.do(ctx, hub, c); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case err := <-c.err:
+				if got := websocket.CloseStatus(err); got != tt.want {
+					t.Errorf("closed with %v (%v), want %v", got, err, tt.want)
+				}
+			case <-ctx.Done():
+				t.Fatal("not closed")
+			}
+		})
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorize  bool
+		origins    []string
+		header     http.Header
+		wantStatus int
+	}{
+		{name: "open", wantStatus: http.StatusSwitchingProtocols},
+		{name: "unauthorized", authorize: true, wantStatus: http.StatusUnauthorized},
+		{name: "authorized", authorize: true, header: http.Header{"Authorization": {"Bearer news"}}, wantStatus: http.StatusSwitchingProtocols},
+		{name: "foreign origin", header: http.Header{"Origin": {"https://app.example.com"}}, wantStatus: http.StatusForbidden},
+		{name: "allowed origin", origins: []string{"*.example.com"}, header: http.Header{"Origin": {"https://app.example.com"}}, wantStatus: http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			hub := broadcast.NewHub(8)
+			if tt.authorize {
+				hub.Authorize(tokens)
+			}
+			hub.AllowOrigins(tt.origins...)
+			_, resp, err := dialHub(t, ctx, hub, "", tt.header)
+			if resp == nil {
+				t.Fatalf("no response: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d (%v), want %d", resp.StatusCode, err, tt.wantStatus)
+			}
+			if (err == nil) != (tt.wantStatus == http.StatusSwitchingProtocols) {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
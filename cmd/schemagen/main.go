@@ -0,0 +1,66 @@
+// Command schemagen writes the JSON Schema of the records sinks write, see
+// sink.EnvelopeSchema, and optionally matching TypeScript and Python types.
+// With -check it instead fails if the generated schema breaks the existing
+// file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kalvin807/twitter-v2-stream/codegen"
+	"github.com/kalvin807/twitter-v2-stream/sink"
+)
+
+func main() {
+	out := flag.String("o", "envelope.schema.json", "output file")
+	ts := flag.String("ts", "", "TypeScript output file")
+	py := flag.String("py", "", "Python output file")
+	check := flag.Bool("check", false, "check the output file for breaking changes instead of writing it")
+	flag.Parse()
+
+	schema := sink.EnvelopeSchema()
+	if *check {
+		data, err := os.ReadFile(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		old := &sink.Schema{}
+		if err := json.Unmarshal(data, old); err != nil {
+			log.Fatal(err)
+		}
+		if err := schema.Compatible(old); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if *ts != "" {
+		writeFile(*ts, schema, codegen.TypeScript)
+	}
+	if *py != "" {
+		writeFile(*py, schema, codegen.Python)
+	}
+}
+
+func writeFile(path string, schema *sink.Schema, generate func(io.Writer, *sink.Schema) error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := generate(f, schema); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
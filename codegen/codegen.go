@@ -0,0 +1,190 @@
+// Package codegen emits TypeScript and Python types matching a JSON Schema
+// generated by the sink package, such as sink.EnvelopeSchema, so consumers
+// of the records and fan-out endpoints in other languages stay in sync with
+// the Go structs. Each titled object schema becomes a named type.
+package codegen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+)
+
+// definition is a named object type of a schema.
+type definition struct {
+	name   string
+	schema *sink.Schema
+}
+
+// definitions returns the named object types of the schema, with the types
+// a type refers to before it.
+func definitions(root *sink.Schema) []definition {
+	var defs []definition
+	done := map[string]bool{}
+	var walk func(s *sink.Schema)
+	walk = func(s *sink.Schema) {
+		if s == nil {
+			return
+		}
+		walk(s.Items)
+		walk(s.AdditionalProperties)
+		if s.Properties == nil || s.Title == "" || done[s.Title] {
+			return
+		}
+		done[s.Title] = true
+		for _, name := range propertyNames(s) {
+			walk(s.Properties[name])
+		}
+		defs = append(defs, definition{name: s.Title, schema: s})
+	}
+	walk(root)
+	return defs
+}
+
+func propertyNames(s *sink.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func required(s *sink.Schema, name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNull returns the types of s other than null and whether null is one.
+func nonNull(s *sink.Schema) ([]string, bool) {
+	var types []string
+	null := false
+	for _, t := range s.Type {
+		if t == "null" {
+			null = true
+		} else {
+			types = append(types, t)
+		}
+	}
+	return types, null
+}
+
+// TypeScript writes TypeScript interfaces of the schema to w.
+func TypeScript(w io.Writer, schema *sink.Schema) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "// Code generated by codegen. DO NOT EDIT.")
+	for _, def := range definitions(schema) {
+		fmt.Fprintf(b, "\nexport interface %s {\n", def.name)
+		for _, name := range propertyNames(def.schema) {
+			optional := "?"
+			if required(def.schema, name) {
+				optional = ""
+			}
+			fmt.Fprintf(b, "  %q%s: %s;\n", name, optional, tsType(def.schema.Properties[name]))
+		}
+		fmt.Fprintln(b, "}")
+	}
+	return b.Flush()
+}
+
+func tsType(s *sink.Schema) string {
+	types, null := nonNull(s)
+	var ts []string
+	for _, t := range types {
+		switch t {
+		case "string":
+			ts = append(ts, "string")
+		case "integer", "number":
+			ts = append(ts, "number")
+		case "boolean":
+			ts = append(ts, "boolean")
+		case "array":
+			item := "unknown"
+			if s.Items != nil {
+				item = tsType(s.Items)
+			}
+			if strings.ContainsAny(item, " |") {
+				item = "(" + item + ")"
+			}
+			ts = append(ts, item+"[]")
+		case "object":
+			switch {
+			case s.Title != "":
+				ts = append(ts, s.Title)
+			case s.AdditionalProperties != nil:
+				ts = append(ts, "Record<string, "+tsType(s.AdditionalProperties)+">")
+			default:
+				ts = append(ts, "Record<string, unknown>")
+			}
+		}
+	}
+	if len(ts) == 0 {
+		ts = append(ts, "unknown")
+	}
+	if null {
+		ts = append(ts, "null")
+	}
+	return strings.Join(ts, " | ")
+}
+
+// Python writes TypedDict classes of the schema to w. Optional properties
+// are marked NotRequired, which needs Python 3.11 or typing_extensions.
+func Python(w io.Writer, schema *sink.Schema) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "# Code generated by codegen. DO NOT EDIT.")
+	fmt.Fprintln(b, "from typing import Any, Dict, List, Optional, TypedDict")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "from typing_extensions import NotRequired")
+	for _, def := range definitions(schema) {
+		fmt.Fprintf(b, "\n\n%s = TypedDict(%q, {\n", def.name, def.name)
+		for _, name := range propertyNames(def.schema) {
+			typ := pyType(def.schema.Properties[name])
+			if !required(def.schema, name) {
+				typ = "NotRequired[" + typ + "]"
+			}
+			fmt.Fprintf(b, "    %q: %s,\n", name, typ)
+		}
+		fmt.Fprintln(b, "})")
+	}
+	return b.Flush()
+}
+
+func pyType(s *sink.Schema) string {
+	types, null := nonNull(s)
+	var py string
+	switch {
+	case len(types) != 1:
+		py = "Any"
+	case types[0] == "string":
+		py = "str"
+	case types[0] == "integer":
+		py = "int"
+	case types[0] == "number":
+		py = "float"
+	case types[0] == "boolean":
+		py = "bool"
+	case types[0] == "array" && s.Items != nil:
+		py = "List[" + pyType(s.Items) + "]"
+	case types[0] == "array":
+		py = "List[Any]"
+	case s.Title != "":
+		// forward reference, types may refer to each other
+		py = fmt.Sprintf("%q", s.Title)
+	case s.AdditionalProperties != nil:
+		py = "Dict[str, " + pyType(s.AdditionalProperties) + "]"
+	default:
+		py = "Dict[str, Any]"
+	}
+	if null && py != "Any" {
+		py = "Optional[" + py + "]"
+	}
+	return py
+}
@@ -0,0 +1,386 @@
+// Package config loads the configuration of a stream from a file: the token
+// source, the fields and expansions of Tweets, the rules, the sinks, buffer
+// sizes and backoff settings, e.g.
+//
+//	[auth]
+//	token_env = "TWITTER_TOKEN"
+//
+//	[stream]
+//	preset = "standard"
+//	tweet_fields = ["lang"]
+//	buffer = 100
+//
+//	[backoff.rate_limit]
+//	initial_interval = "1m"
+//	multiplier = 2
+//	max_interval = "16m"
+//
+//	[[rules]]
+//	value = "cat has:images"
+//	tag = "cats"
+//
+//	[[sinks]]
+//	type = "file"
+//	dir = "archive"
+//	max_age = "1h"
+//
+// Files ending in .json are decoded as JSON with the same keys, files ending
+// in .yaml or .yml as YAML, and other files as TOML. Load reports every
+// problem of a file at once, each with the key it concerns.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Config is the configuration of a stream.
+type Config struct {
+	Auth    Auth     `json:"auth"`
+	Stream  Stream   `json:"stream"`
+	BackOff BackOffs `json:"backoff"`
+	Rules   []Rule   `json:"rules"`
+	Sinks   []Sink   `json:"sinks"`
+}
+
+// Auth is the token source: a bearer token, or the app-only token of a
+// consumer key and secret. Each is read from the environment variable named
+// by its _env key unless set in the file, so secrets can be kept out of it.
+// The variables default to TWITTER_TOKEN, TWITTER_CONSUMER_KEY and
+// TWITTER_CONSUMER_SECRET.
+type Auth struct {
+	Token             string `json:"token,omitempty"`
+	TokenEnv          string `json:"token_env,omitempty"`
+	ConsumerKey       string `json:"consumer_key,omitempty"`
+	ConsumerKeyEnv    string `json:"consumer_key_env,omitempty"`
+	ConsumerSecret    string `json:"consumer_secret,omitempty"`
+	ConsumerSecretEnv string `json:"consumer_secret_env,omitempty"`
+}
+
+// Stream are the parameters of the connection. Preset names a preset of
+// fields and expansions, see stream.Preset, to which the lists are added.
+// BaseURL overrides the API host, e.g. for a fake API, and Addr is the
+// address serving metrics, probes and rebroadcasts, 127.0.0.1:8080 by
+// default and disabled if empty.
+type Stream struct {
+	BaseURL         string   `json:"base_url,omitempty"`
+	Preset          string   `json:"preset,omitempty"`
+	TweetFields     []string `json:"tweet_fields,omitempty"`
+	Expansions      []string `json:"expansions,omitempty"`
+	UserFields      []string `json:"user_fields,omitempty"`
+	MediaFields     []string `json:"media_fields,omitempty"`
+	PlaceFields     []string `json:"place_fields,omitempty"`
+	PollFields      []string `json:"poll_fields,omitempty"`
+	BackfillMinutes int      `json:"backfill_minutes,omitempty"`
+	Buffer          int      `json:"buffer,omitempty"`
+	Addr            string   `json:"addr,omitempty"`
+}
+
+// BackOffs are the backoffs of the error classes, see stream.WithBackOff.
+// Classes which are not configured keep the default backoff.
+type BackOffs struct {
+	Network   *BackOff `json:"network,omitempty"`
+	HTTP      *BackOff `json:"http,omitempty"`
+	RateLimit *BackOff `json:"rate_limit,omitempty"`
+}
+
+// BackOff are the parameters of an exponential backoff, see
+// stream.BackOffConfig.
+type BackOff struct {
+	InitialInterval Duration `json:"initial_interval"`
+	Multiplier      float64  `json:"multiplier"`
+	MaxInterval     Duration `json:"max_interval"`
+	MaxElapsedTime  Duration `json:"max_elapsed_time,omitempty"`
+	Jitter          float64  `json:"jitter,omitempty"`
+}
+
+// Rule is a rule of the filtered stream.
+type Rule struct {
+	Value string `json:"value"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// Sink is a destination of the messages, by Type:
+//
+//   - file: NDJSON files in Dir, named by Prefix and rotated at MaxBytes or
+//     MaxAge, see sink.File
+//   - webhook: POSTs to URL signed with the secret in SecretEnv, in batches
+//     of BatchSize flushed every FlushInterval, see sink.Webhook
+//   - kafka: the Topic on Brokers, see sink.Kafka
+//   - redis: the Channel, or the Stream, of the server at Addr, see
+//     sink.Redis
+//   - sql: the database of DSN in the Dialect postgres or sqlite, see
+//     sink.SQL
+//
+// Format is the encoding of the records of webhook, kafka and redis sinks:
+// json, the default, or protobuf for the StreamData message of
+// streampb.proto.
+type Sink struct {
+	Type          string   `json:"type"`
+	Dir           string   `json:"dir,omitempty"`
+	Prefix        string   `json:"prefix,omitempty"`
+	MaxBytes      int64    `json:"max_bytes,omitempty"`
+	MaxAge        Duration `json:"max_age,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	SecretEnv     string   `json:"secret_env,omitempty"`
+	BatchSize     int      `json:"batch_size,omitempty"`
+	FlushInterval Duration `json:"flush_interval,omitempty"`
+	DeadLetter    string   `json:"dead_letter,omitempty"`
+	Brokers       []string `json:"brokers,omitempty"`
+	Topic         string   `json:"topic,omitempty"`
+	Addr          string   `json:"addr,omitempty"`
+	Channel       string   `json:"channel,omitempty"`
+	Stream        string   `json:"stream,omitempty"`
+	DSN           string   `json:"dsn,omitempty"`
+	Dialect       string   `json:"dialect,omitempty"`
+	Format        string   `json:"format,omitempty"`
+}
+
+// SinkTypes are the types of sinks.
+var SinkTypes = []string{"file", "webhook", "kafka", "redis", "sql"}
+
+// Duration is a time.Duration written as a string such as "1m30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Error lists the problems of a configuration, each prefixed with the key
+// it concerns. Path is the file, if any.
+type Error struct {
+	Path     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("config:")
+	if e.Path != "" {
+		fmt.Fprintf(&b, " %s:", e.Path)
+	}
+	if len(e.Problems) == 1 {
+		fmt.Fprintf(&b, " %s", e.Problems[0])
+		return b.String()
+	}
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&b, "\n  %s", problem)
+	}
+	return b.String()
+}
+
+// Load reads and decodes the configuration file at path over the defaults,
+// overrides it with the environment variables which are set, see FromEnv,
+// and validates it.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	cfg, problems, err := parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, cfg.applyEnv()...)
+	names := cfg.envNames(func(name string) bool { return os.Getenv(name) != "" })
+	if problems = append(problems, renameProblems(cfg.problems(), names)...); len(problems) > 0 {
+		return nil, &Error{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Parse decodes a configuration over the defaults, as JSON if name ends in
+// .json, as YAML if it ends in .yaml or .yml and as TOML otherwise, without
+// validating it.
+func Parse(name string, data []byte) (*Config, error) {
+	cfg, problems, err := parse(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, &Error{Path: name, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// parse decodes a configuration, returning the problems of the keys and
+// values along with what could be decoded, or an error for syntax errors.
+func parse(name string, data []byte) (*Config, []string, error) {
+	parse := parseTOML
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		parse = parseJSON
+	case ".yaml", ".yml":
+		parse = parseYAML
+	}
+	tree, err := parse(data)
+	if err != nil {
+		return nil, nil, &Error{Path: name, Problems: []string{err.Error()}}
+	}
+	cfg := Default()
+	var problems []string
+	decode("", tree, reflectValue(cfg), &problems)
+	return cfg, problems, nil
+}
+
+// Validate checks the configuration, including that the token source
+// yields credentials.
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+func (c *Config) problems() []string {
+	var problems []string
+	add := func(key, format string, args ...any) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+	if token, key, secret := c.Auth.credentials(); token == "" && (key == "" || secret == "") {
+		add("auth", "no credentials, set token or the variable %s, or a consumer key and secret", c.Auth.tokenEnv())
+	}
+	if c.Stream.Preset != "" {
+		if _, err := stream.Preset(c.Stream.Preset); err != nil {
+			add("stream.preset", "unknown preset %q, want one of %s", c.Stream.Preset, strings.Join(stream.PresetNames(), ", "))
+		}
+	}
+	lists := []struct {
+		key    string
+		values []string
+	}{
+		{"stream.tweet_fields", c.Stream.TweetFields},
+		{"stream.expansions", c.Stream.Expansions},
+		{"stream.user_fields", c.Stream.UserFields},
+		{"stream.media_fields", c.Stream.MediaFields},
+		{"stream.place_fields", c.Stream.PlaceFields},
+		{"stream.poll_fields", c.Stream.PollFields},
+	}
+	listed := true
+	for _, list := range lists {
+		for _, v := range list.values {
+			if v == "" || strings.ContainsAny(v, ", \t") {
+				add(list.key, "%q is not a field, list fields as separate strings", v)
+				listed = false
+			}
+		}
+	}
+	if params, err := c.Params(); err == nil && listed {
+		// misspelled fields and fields missing their expansion
+		var paramErr *stream.ParameterError
+		if errors.As(params.Validate(), &paramErr) {
+			add("stream."+strings.ReplaceAll(paramErr.Parameter, ".", "_"), "%q: %s", paramErr.Values, paramErr.Message)
+		}
+	}
+	if n := c.Stream.BackfillMinutes; n < 0 || n > 5 {
+		add("stream.backfill_minutes", "%d is out of range 0 to 5", n)
+	}
+	if c.Stream.Buffer < 0 {
+		add("stream.buffer", "must not be negative")
+	}
+	backOffs := []struct {
+		key string
+		b   *BackOff
+	}{
+		{"backoff.network", c.BackOff.Network},
+		{"backoff.http", c.BackOff.HTTP},
+		{"backoff.rate_limit", c.BackOff.RateLimit},
+	}
+	for _, backOff := range backOffs {
+		key, b := backOff.key, backOff.b
+		if b == nil {
+			continue
+		}
+		if b.InitialInterval <= 0 {
+			add(key+".initial_interval", "must be positive")
+		}
+		if b.Multiplier < 1 {
+			add(key+".multiplier", "must be at least 1")
+		}
+		if b.MaxInterval < b.InitialInterval {
+			add(key+".max_interval", "must be at least initial_interval")
+		}
+		if b.MaxElapsedTime < 0 {
+			add(key+".max_elapsed_time", "must not be negative")
+		}
+		if b.Jitter < 0 || b.Jitter > 1 {
+			add(key+".jitter", "%v is out of range 0 to 1", b.Jitter)
+		}
+	}
+	for i, rule := range c.Rules {
+		if strings.TrimSpace(rule.Value) == "" {
+			add(fmt.Sprintf("rules[%d].value", i), "must not be empty")
+		}
+	}
+	for i, s := range c.Sinks {
+		problems = append(problems, s.problems(fmt.Sprintf("sinks[%d]", i))...)
+	}
+	return problems
+}
+
+func (s *Sink) problems(key string) []string {
+	var problems []string
+	require := func(name, value string) {
+		if value == "" {
+			problems = append(problems, fmt.Sprintf("%s.%s: required for %s sinks", key, name, s.Type))
+		}
+	}
+	switch s.Type {
+	case "file":
+		require("dir", s.Dir)
+	case "webhook":
+		require("url", s.URL)
+		if s.SecretEnv != "" && os.Getenv(s.SecretEnv) == "" {
+			problems = append(problems, fmt.Sprintf("%s.secret_env: the variable %s is not set", key, s.SecretEnv))
+		}
+	case "kafka":
+		if len(s.Brokers) == 0 {
+			problems = append(problems, fmt.Sprintf("%s.brokers: required for kafka sinks", key))
+		}
+		require("topic", s.Topic)
+	case "redis":
+		require("addr", s.Addr)
+		if s.Channel == "" && s.Stream == "" {
+			problems = append(problems, fmt.Sprintf("%s: redis sinks require a channel or a stream", key))
+		}
+	case "sql":
+		require("dsn", s.DSN)
+		if s.Dialect != "postgres" && s.Dialect != "sqlite" {
+			problems = append(problems, fmt.Sprintf("%s.dialect: %q is not postgres or sqlite", key, s.Dialect))
+		}
+	case "":
+		problems = append(problems, fmt.Sprintf("%s.type: required, one of %s", key, strings.Join(SinkTypes, ", ")))
+	default:
+		problems = append(problems, fmt.Sprintf("%s.type: unknown sink %q, want one of %s", key, s.Type, strings.Join(SinkTypes, ", ")))
+	}
+	switch s.Format {
+	case "", "json":
+	case "protobuf":
+		if s.Type == "file" || s.Type == "sql" {
+			problems = append(problems, fmt.Sprintf("%s.format: %s sinks only write json", key, s.Type))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("%s.format: unknown format %q, want json or protobuf", key, s.Format))
+	}
+	for _, field := range []struct {
+		name     string
+		negative bool
+	}{
+		{"max_bytes", s.MaxBytes < 0},
+		{"max_age", s.MaxAge < 0},
+		{"batch_size", s.BatchSize < 0},
+		{"flush_interval", s.FlushInterval < 0},
+	} {
+		if field.negative {
+			problems = append(problems, fmt.Sprintf("%s.%s: must not be negative", key, field.name))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/config"
+)
+
+// example is the configuration of the package documentation in each
+// format.
+var example = []struct {
+	name string
+	data string
+}{
+	{"twstream.toml", `
+[auth]
+token_env = "TWITTER_TOKEN"
+
+[stream]
+preset = "standard"
+tweet_fields = ["lang"]
+buffer = 100
+
+[backoff.rate_limit]
+initial_interval = "1m"
+multiplier = 2
+max_interval = "16m"
+
+[[rules]]
+value = "cat has:images"
+tag = "cats"
+
+[[sinks]]
+type = "file"
+dir = "archive"
+max_age = "1h"
+`},
+	{"twstream.yaml", `
+auth:
+  token_env: TWITTER_TOKEN
+stream:
+  preset: standard
+  tweet_fields: [lang]
+  buffer: 100
+backoff:
+  rate_limit:
+    initial_interval: 1m
+    multiplier: 2
+    max_interval: 16m
+rules:
+  - value: "cat has:images"
+    tag: cats
+sinks:
+  - type: file
+    dir: archive
+    max_age: 1h
+`},
+	{"twstream.json", `{
+	"auth": {"token_env": "TWITTER_TOKEN"},
+	"stream": {"preset": "standard", "tweet_fields": ["lang"], "buffer": 100},
+	"backoff": {"rate_limit": {"initial_interval": "1m", "multiplier": 2, "max_interval": "16m"}},
+	"rules": [{"value": "cat has:images", "tag": "cats"}],
+	"sinks": [{"type": "file", "dir": "archive", "max_age": "1h"}]
+}`},
+}
+
+func TestParseFormats(t *testing.T) {
+	var want *config.Config
+	for _, tt := range example {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Parse(tt.name, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Stream.Buffer != 100 || len(cfg.Rules) != 1 || cfg.Rules[0].Tag != "cats" ||
+				len(cfg.Sinks) != 1 || time.Duration(cfg.Sinks[0].MaxAge) != time.Hour {
+				t.Errorf("decoded %+v", cfg)
+			}
+			if want == nil {
+				want = cfg
+			} else if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("decoded\n%+v\nwant the same as %s\n%+v", cfg, example[0].name, want)
+			}
+		})
+	}
+}
+
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want []string
+	}{
+		{"TOML syntax", "c.toml", "[stream\nbuffer = 1", []string{"toml: line 2: expected '.' or ']' to end table name"}},
+		{"TOML multi-line string", "c.toml", "[[rules]]\nvalue = \"\"\"cat\nhas:images\"\"\"", nil},
+		{"TOML date-time", "c.toml", "[stream]\npreset = 2024-05-01T00:00:00Z", []string{"stream.preset: want a string, got the date-time 2024-05-01T00:00:00Z"}},
+		{"TOML hexadecimal", "c.toml", "[stream]\nbuffer = 0x10", nil},
+		{"YAML syntax", "c.yaml", "stream:\n  buffer: [1", []string{"yaml: line"}},
+		{"YAML list document", "c.yml", "- stream", []string{"want a mapping of keys, got an array"}},
+		{"YAML empty document", "c.yaml", "", nil},
+		{"JSON syntax", "c.json", `{"stream": }`, []string{"invalid character"}},
+		{"unknown key", "c.toml", "[stream]\nbufer = 1", []string{"stream.bufer: unknown key"}},
+		{"wrong type", "c.yaml", "stream:\n  buffer: many", []string{`stream.buffer: want an integer, got the string "many"`}},
+		{"bad duration", "c.json", `{"sinks": [{"type": "file", "max_age": "an hour"}]}`, []string{`sinks[0].max_age: "an hour" is not a duration`}},
+		{"several problems", "c.toml", "[stream]\nbufer = 1\nbuffer = true", []string{"stream.bufer: unknown key", "stream.buffer: want an integer, got true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Parse(tt.file, []byte(tt.data))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var cfgErr *config.Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("err = %v, want a *config.Error", err)
+			}
+			if len(cfgErr.Problems) != len(tt.want) {
+				t.Fatalf("problems %q, want %q", cfgErr.Problems, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(cfgErr.Problems[i], want) {
+					t.Errorf("problem %q, want it to contain %q", cfgErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
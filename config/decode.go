@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(Duration(0))
+
+func reflectValue(v any) reflect.Value {
+	return reflect.ValueOf(v).Elem()
+}
+
+// decode stores the decoded value v at key into dst, by the json tags of
+// structs, collecting every unknown key and mismatched type as a problem.
+func decode(key string, v any, dst reflect.Value, problems *[]string) {
+	mismatch := func(want string) {
+		*problems = append(*problems, fmt.Sprintf("%s: want %s, got %s", key, want, describe(v)))
+	}
+	switch {
+	case dst.Type() == durationType:
+		s, ok := v.(string)
+		if !ok {
+			mismatch(`a duration such as "30s"`)
+			return
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not a duration such as \"30s\"", key, s))
+			return
+		}
+		dst.SetInt(int64(d))
+		return
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			mismatch("a string")
+			return
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			mismatch("a boolean")
+			return
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, ok := toInt(v)
+		if !ok {
+			mismatch("an integer")
+			return
+		}
+		dst.SetInt(n)
+	case reflect.Float64:
+		f, ok := toFloat(v)
+		if !ok {
+			mismatch("a number")
+			return
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		values, ok := v.([]any)
+		if !ok {
+			mismatch("an array")
+			return
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(values), len(values))
+		for i, value := range values {
+			decode(fmt.Sprintf("%s[%d]", key, i), value, slice.Index(i), problems)
+		}
+		dst.Set(slice)
+	case reflect.Pointer:
+		dst.Set(reflect.New(dst.Type().Elem()))
+		decode(key, v, dst.Elem(), problems)
+	case reflect.Struct:
+		table, ok := v.(map[string]any)
+		if !ok {
+			mismatch("a table")
+			return
+		}
+		fields := make(map[string]int)
+		for i := 0; i < dst.NumField(); i++ {
+			name, _, _ := strings.Cut(dst.Type().Field(i).Tag.Get("json"), ",")
+			fields[name] = i
+		}
+		names := make([]string, 0, len(table))
+		for name := range table {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := name
+			if key != "" {
+				child = key + "." + name
+			}
+			i, ok := fields[name]
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: unknown key", child))
+				continue
+			}
+			decode(child, table[name], dst.Field(i), problems)
+		}
+	}
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// describe names the type of a decoded value for problems.
+func describe(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case int64, float64, json.Number:
+		return fmt.Sprintf("the number %v", v)
+	case bool:
+		return fmt.Sprintf("%v", v)
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "a table"
+	case time.Time:
+		return "the date-time " + v.Format(time.RFC3339)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("the value %v", v)
+	}
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefixes are the prefixes of the variables of the configuration. Set
+// variables with these prefixes which are not known are reported, to catch
+// typos.
+var envPrefixes = []string{"STREAM_", "SINK_", "BACKOFF_"}
+
+// envVar is an environment variable setting the key of the configuration,
+// or the field of the first sink of the type if sink is set.
+type envVar struct {
+	name  string
+	key   string
+	sink  string
+	parse func(c *Config, value string) error
+}
+
+// envVars are the environment variables of the configuration, see FromEnv.
+var envVars = buildEnvVars()
+
+func buildEnvVars() []envVar {
+	vars := []envVar{
+		{name: "TWITTER_TOKEN", key: "auth.token", parse: func(c *Config, v string) error { c.Auth.Token = v; return nil }},
+		{name: "TWITTER_CONSUMER_KEY", key: "auth.consumer_key", parse: func(c *Config, v string) error { c.Auth.ConsumerKey = v; return nil }},
+		{name: "TWITTER_CONSUMER_SECRET", key: "auth.consumer_secret", parse: func(c *Config, v string) error { c.Auth.ConsumerSecret = v; return nil }},
+		{name: "TWITTER_BASE_URL", key: "stream.base_url", parse: setString(func(c *Config) *string { return &c.Stream.BaseURL })},
+		{name: "STREAM_PRESET", key: "stream.preset", parse: setString(func(c *Config) *string { return &c.Stream.Preset })},
+		{name: "STREAM_TWEET_FIELDS", key: "stream.tweet_fields", parse: setList(func(c *Config) *[]string { return &c.Stream.TweetFields })},
+		{name: "STREAM_EXPANSIONS", key: "stream.expansions", parse: setList(func(c *Config) *[]string { return &c.Stream.Expansions })},
+		{name: "STREAM_USER_FIELDS", key: "stream.user_fields", parse: setList(func(c *Config) *[]string { return &c.Stream.UserFields })},
+		{name: "STREAM_MEDIA_FIELDS", key: "stream.media_fields", parse: setList(func(c *Config) *[]string { return &c.Stream.MediaFields })},
+		{name: "STREAM_PLACE_FIELDS", key: "stream.place_fields", parse: setList(func(c *Config) *[]string { return &c.Stream.PlaceFields })},
+		{name: "STREAM_POLL_FIELDS", key: "stream.poll_fields", parse: setList(func(c *Config) *[]string { return &c.Stream.PollFields })},
+		{name: "STREAM_BACKFILL_MINUTES", key: "stream.backfill_minutes", parse: setInt(func(c *Config) *int { return &c.Stream.BackfillMinutes })},
+		{name: "STREAM_BUFFER", key: "stream.buffer", parse: setInt(func(c *Config) *int { return &c.Stream.Buffer })},
+		{name: "STREAM_ADDR", key: "stream.addr", parse: setString(func(c *Config) *string { return &c.Stream.Addr })},
+	}
+	classes := []struct {
+		name, key string
+		backOff   func(c *Config) **BackOff
+	}{
+		{"NETWORK", "network", func(c *Config) **BackOff { return &c.BackOff.Network }},
+		{"HTTP", "http", func(c *Config) **BackOff { return &c.BackOff.HTTP }},
+		{"RATE_LIMIT", "rate_limit", func(c *Config) **BackOff { return &c.BackOff.RateLimit }},
+	}
+	for _, class := range classes {
+		field := func(c *Config) *BackOff {
+			b := class.backOff(c)
+			if *b == nil {
+				*b = &BackOff{}
+			}
+			return *b
+		}
+		prefix, key := "BACKOFF_"+class.name+"_", "backoff."+class.key+"."
+		vars = append(vars,
+			envVar{name: prefix + "INITIAL_INTERVAL", key: key + "initial_interval", parse: setDuration(func(c *Config) *Duration { return &field(c).InitialInterval })},
+			envVar{name: prefix + "MULTIPLIER", key: key + "multiplier", parse: setFloat(func(c *Config) *float64 { return &field(c).Multiplier })},
+			envVar{name: prefix + "MAX_INTERVAL", key: key + "max_interval", parse: setDuration(func(c *Config) *Duration { return &field(c).MaxInterval })},
+			envVar{name: prefix + "MAX_ELAPSED_TIME", key: key + "max_elapsed_time", parse: setDuration(func(c *Config) *Duration { return &field(c).MaxElapsedTime })},
+			envVar{name: prefix + "JITTER", key: key + "jitter", parse: setFloat(func(c *Config) *float64 { return &field(c).Jitter })},
+		)
+	}
+	sinkVar := func(typ, key string, parse func(s *Sink, v string) error) envVar {
+		return envVar{
+			name: "SINK_" + strings.ToUpper(typ+"_"+key),
+			key:  key,
+			sink: typ,
+			parse: func(c *Config, v string) error {
+				return parse(c.sinkOfType(typ), v)
+			},
+		}
+	}
+	str := func(field func(s *Sink) *string) func(s *Sink, v string) error {
+		return func(s *Sink, v string) error { *field(s) = v; return nil }
+	}
+	dur := func(field func(s *Sink) *Duration) func(s *Sink, v string) error {
+		return func(s *Sink, v string) error { return parseDuration(v, field(s)) }
+	}
+	return append(vars,
+		sinkVar("file", "dir", str(func(s *Sink) *string { return &s.Dir })),
+		sinkVar("file", "prefix", str(func(s *Sink) *string { return &s.Prefix })),
+		sinkVar("file", "max_bytes", func(s *Sink, v string) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("want an integer, got %q", v)
+			}
+			s.MaxBytes = n
+			return nil
+		}),
+		sinkVar("file", "max_age", dur(func(s *Sink) *Duration { return &s.MaxAge })),
+		sinkVar("webhook", "url", str(func(s *Sink) *string { return &s.URL })),
+		envVar{
+			name: "SINK_WEBHOOK_SECRET",
+			key:  "secret_env",
+			sink: "webhook",
+			parse: func(c *Config, v string) error {
+				c.sinkOfType("webhook").SecretEnv = "SINK_WEBHOOK_SECRET"
+				return nil
+			},
+		},
+		sinkVar("webhook", "batch_size", func(s *Sink, v string) error { return parseInt(v, &s.BatchSize) }),
+		sinkVar("webhook", "flush_interval", dur(func(s *Sink) *Duration { return &s.FlushInterval })),
+		sinkVar("webhook", "dead_letter", str(func(s *Sink) *string { return &s.DeadLetter })),
+		sinkVar("webhook", "format", str(func(s *Sink) *string { return &s.Format })),
+		sinkVar("kafka", "brokers", func(s *Sink, v string) error { s.Brokers = splitList(v); return nil }),
+		sinkVar("kafka", "topic", str(func(s *Sink) *string { return &s.Topic })),
+		sinkVar("kafka", "format", str(func(s *Sink) *string { return &s.Format })),
+		sinkVar("redis", "addr", str(func(s *Sink) *string { return &s.Addr })),
+		sinkVar("redis", "channel", str(func(s *Sink) *string { return &s.Channel })),
+		sinkVar("redis", "stream", str(func(s *Sink) *string { return &s.Stream })),
+		sinkVar("redis", "format", str(func(s *Sink) *string { return &s.Format })),
+		sinkVar("sql", "dsn", str(func(s *Sink) *string { return &s.DSN })),
+		sinkVar("sql", "dialect", str(func(s *Sink) *string { return &s.Dialect })),
+	)
+}
+
+func setString(field func(c *Config) *string) func(c *Config, v string) error {
+	return func(c *Config, v string) error { *field(c) = v; return nil }
+}
+
+func setList(field func(c *Config) *[]string) func(c *Config, v string) error {
+	return func(c *Config, v string) error { *field(c) = splitList(v); return nil }
+}
+
+func setInt(field func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error { return parseInt(v, field(c)) }
+}
+
+func setFloat(field func(c *Config) *float64) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("want a number, got %q", v)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func setDuration(field func(c *Config) *Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error { return parseDuration(v, field(c)) }
+}
+
+func parseInt(v string, dst *int) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("want an integer, got %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func parseDuration(v string, dst *Duration) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as \"30s\"", v)
+	}
+	*dst = Duration(d)
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty values.
+func splitList(v string) []string {
+	var values []string
+	for _, value := range strings.Split(v, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// sinkOfType returns the first sink of the type, adding one if there is
+// none.
+func (c *Config) sinkOfType(typ string) *Sink {
+	for i := range c.Sinks {
+		if c.Sinks[i].Type == typ {
+			return &c.Sinks[i]
+		}
+	}
+	c.Sinks = append(c.Sinks, Sink{Type: typ})
+	return &c.Sinks[len(c.Sinks)-1]
+}
+
+// Default returns the default configuration: unbuffered, with the default
+// backoffs, serving on 127.0.0.1:8080, and reading the token from
+// TWITTER_TOKEN, or the consumer key and secret from TWITTER_CONSUMER_KEY
+// and TWITTER_CONSUMER_SECRET.
+func Default() *Config {
+	return &Config{
+		Stream: Stream{Addr: "127.0.0.1:8080"},
+	}
+}
+
+// FromEnv returns the default configuration overridden by the environment
+// variables, validated. The variables are:
+//
+//   - TWITTER_TOKEN, or TWITTER_CONSUMER_KEY and TWITTER_CONSUMER_SECRET
+//   - TWITTER_BASE_URL
+//   - STREAM_PRESET, STREAM_TWEET_FIELDS, STREAM_EXPANSIONS,
+//     STREAM_USER_FIELDS, STREAM_MEDIA_FIELDS, STREAM_PLACE_FIELDS and
+//     STREAM_POLL_FIELDS, as comma separated lists
+//   - STREAM_BACKFILL_MINUTES, STREAM_BUFFER and STREAM_ADDR
+//   - BACKOFF_<CLASS>_INITIAL_INTERVAL, _MULTIPLIER, _MAX_INTERVAL,
+//     _MAX_ELAPSED_TIME and _JITTER, for the classes NETWORK, HTTP and
+//     RATE_LIMIT
+//   - SINK_FILE_DIR, _PREFIX, _MAX_BYTES and _MAX_AGE
+//   - SINK_WEBHOOK_URL, _SECRET, _BATCH_SIZE, _FLUSH_INTERVAL and
+//     _DEAD_LETTER
+//   - SINK_KAFKA_BROKERS and _TOPIC
+//   - SINK_REDIS_ADDR, _CHANNEL and _STREAM
+//   - SINK_SQL_DSN and _DIALECT
+//
+// Setting any variable of a sink adds the sink. The error lists every
+// missing or invalid setting, named by its variable.
+func FromEnv() (*Config, error) {
+	cfg := Default()
+	problems := cfg.applyEnv()
+	names := cfg.envNames(func(string) bool { return true })
+	problems = append(problems, renameProblems(cfg.problems(), names)...)
+	if len(problems) > 0 {
+		return nil, &Error{Path: "environment", Problems: problems}
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the configuration with the set variables, returning
+// the problems of their values and of unknown variables.
+func (c *Config) applyEnv() []string {
+	var problems []string
+	known := make(map[string]bool, len(envVars))
+	for _, v := range envVars {
+		known[v.name] = true
+		value := os.Getenv(v.name)
+		if value == "" {
+			continue
+		}
+		if err := v.parse(c, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", v.name, err))
+		}
+	}
+	var unknown []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		for _, prefix := range envPrefixes {
+			if strings.HasPrefix(name, prefix) && !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown variable", name))
+	}
+	return problems
+}
+
+// envNames returns the names of the variables for which include is true by
+// the key of the configuration they set. Sink variables set the first sink
+// of their type.
+func (c *Config) envNames(include func(name string) bool) map[string]string {
+	names := make(map[string]string)
+	for _, v := range envVars {
+		if !include(v.name) {
+			continue
+		}
+		if v.sink == "" {
+			names[v.key] = v.name
+			continue
+		}
+		for i := range c.Sinks {
+			if c.Sinks[i].Type == v.sink {
+				names[fmt.Sprintf("sinks[%d].%s", i, v.key)] = v.name
+				break
+			}
+		}
+	}
+	return names
+}
+
+// renameProblems names the problems of keys set by variables by the
+// variable.
+func renameProblems(problems []string, names map[string]string) []string {
+	for i, problem := range problems {
+		key, rest, ok := strings.Cut(problem, ": ")
+		if !ok {
+			continue
+		}
+		if name, ok := names[key]; ok {
+			problems[i] = name + ": " + rest
+		}
+	}
+	return problems
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// parseJSON parses a JSON document, keeping numbers as json.Number.
+func parseJSON(data []byte) (map[string]any, error) {
+	var tree map[string]any
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// parseTOML parses a TOML document.
+func parseTOML(data []byte) (map[string]any, error) {
+	var tree map[string]any
+	if _, err := toml.Decode(string(data), &tree); err != nil {
+		return nil, err
+	}
+	return normalize(tree).(map[string]any), nil
+}
+
+// parseYAML parses a YAML document, which must be a mapping.
+func parseYAML(data []byte) (map[string]any, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		// an empty document
+		return map[string]any{}, nil
+	}
+	tree, ok := normalize(doc).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("want a mapping of keys, got %s", describe(normalize(doc)))
+	}
+	return tree, nil
+}
+
+// normalize converts the values decoded by the TOML and YAML libraries to
+// those decode expects: int64 integers, []any arrays and map[string]any
+// tables.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case uint64:
+		return json.Number(fmt.Sprint(v))
+	case []map[string]any:
+		values := make([]any, len(v))
+		for i, table := range v {
+			values[i] = normalize(table)
+		}
+		return values
+	case []any:
+		values := make([]any, len(v))
+		for i, value := range v {
+			values[i] = normalize(value)
+		}
+		return values
+	case map[string]any:
+		table := make(map[string]any, len(v))
+		for key, value := range v {
+			table[key] = normalize(value)
+		}
+		return table
+	case map[any]any:
+		table := make(map[string]any, len(v))
+		for key, value := range v {
+			table[fmt.Sprint(key)] = normalize(value)
+		}
+		return table
+	}
+	return v
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// ErrClientRequired is returned by OpenSink for the kafka, redis and sql
+// sinks, which are built with a client of the application's choice from the
+// settings of the Sink, e.g. with sink.NewKafka.
+var ErrClientRequired = errors.New("config: sink requires a client, build it with the sink package")
+
+func (a Auth) tokenEnv() string {
+	if a.TokenEnv != "" {
+		return a.TokenEnv
+	}
+	return "TWITTER_TOKEN"
+}
+
+// credentials returns the bearer token, or the consumer key and secret, of
+// the token source.
+func (a Auth) credentials() (token, key, secret string) {
+	lookup := func(value, env, fallback string) string {
+		if value != "" {
+			return value
+		}
+		if env == "" {
+			env = fallback
+		}
+		return os.Getenv(env)
+	}
+	token = lookup(a.Token, a.TokenEnv, "TWITTER_TOKEN")
+	key = lookup(a.ConsumerKey, a.ConsumerKeyEnv, "TWITTER_CONSUMER_KEY")
+	secret = lookup(a.ConsumerSecret, a.ConsumerSecretEnv, "TWITTER_CONSUMER_SECRET")
+	return token, key, secret
+}
+
+// Params returns the fields and expansions of the stream: those of the
+// preset, if any, and the listed ones.
+func (c *Config) Params() (*stream.StreamFilterParams, error) {
+	params := &stream.StreamFilterParams{}
+	if c.Stream.Preset != "" {
+		var err error
+		if params, err = stream.Preset(c.Stream.Preset); err != nil {
+			return nil, err
+		}
+	}
+	params.TweetFields = appendNew(params.TweetFields, c.Stream.TweetFields)
+	params.Expansions = appendNew(params.Expansions, c.Stream.Expansions)
+	params.UserFields = appendNew(params.UserFields, c.Stream.UserFields)
+	params.MediaFields = appendNew(params.MediaFields, c.Stream.MediaFields)
+	params.PlaceFields = appendNew(params.PlaceFields, c.Stream.PlaceFields)
+	params.PollFields = appendNew(params.PollFields, c.Stream.PollFields)
+	params.BackfillMinutes = c.Stream.BackfillMinutes
+	return params, nil
+}
+
+// appendNew appends the values which are not in values yet.
+func appendNew(values, more []string) []string {
+	for _, v := range more {
+		found := false
+		for _, existing := range values {
+			found = found || existing == v
+		}
+		if !found {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Options returns the options of the configured base URL, buffer size and
+// backoffs.
+func (c *Config) Options() []stream.Option {
+	var opts []stream.Option
+	if c.Stream.BaseURL != "" {
+		opts = append(opts, stream.WithBaseURL(c.Stream.BaseURL))
+	}
+	if c.Stream.Buffer > 0 {
+		opts = append(opts, stream.WithMessagesBuffer(c.Stream.Buffer))
+	}
+	for class, b := range map[stream.ErrorClass]*BackOff{
+		stream.ClassNetwork:   c.BackOff.Network,
+		stream.ClassHTTP:      c.BackOff.HTTP,
+		stream.ClassRateLimit: c.BackOff.RateLimit,
+	} {
+		if b == nil {
+			continue
+		}
+		cfg := stream.BackOffConfig{
+			InitialInterval: time.Duration(b.InitialInterval),
+			Multiplier:      b.Multiplier,
+			MaxInterval:     time.Duration(b.MaxInterval),
+			MaxElapsedTime:  time.Duration(b.MaxElapsedTime),
+			Jitter:          b.Jitter,
+		}
+		opts = append(opts, stream.WithBackOff(class, func() backoff.BackOff { return cfg.New() }))
+	}
+	return opts
+}
+
+// NewService returns a StreamService authenticated by the token source and
+// configured by Options, followed by opts.
+func (c *Config) NewService(ctx context.Context, client *http.Client, opts ...stream.Option) (*stream.StreamService, error) {
+	opts = append(c.Options(), opts...)
+	token, key, secret := c.Auth.credentials()
+	if token != "" {
+		return stream.NewStreamService(client, token, opts...), nil
+	}
+	return stream.NewAppStreamService(ctx, client, key, secret, opts...)
+}
+
+// SyncRules adds the configured rules which the filtered stream does not
+// have yet, with the same value and tag. Other rules are kept. It returns
+// the response of the add request, or nil if every rule existed.
+func (c *Config) SyncRules(ctx context.Context, srv *stream.StreamService) (*stream.RulesResponse, error) {
+	existing, err := srv.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[Rule]bool, len(existing))
+	for _, rule := range existing {
+		have[Rule{Value: rule.Value, Tag: rule.Tag}] = true
+	}
+	var missing []*stream.Rule
+	for _, rule := range c.Rules {
+		if !have[rule] {
+			have[rule] = true
+			missing = append(missing, &stream.Rule{Value: rule.Value, Tag: rule.Tag})
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return srv.AddRules(ctx, missing, nil)
+}
+
+// OpenSink returns the file or webhook sink configured by s. Kafka, redis
+// and sql sinks return ErrClientRequired.
+func OpenSink(s Sink) (sink.Sink, error) {
+	switch s.Type {
+	case "file":
+		prefix := s.Prefix
+		if prefix == "" {
+			prefix = "tweets"
+		}
+		files, err := sink.NewFile(s.Dir, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if s.MaxBytes > 0 {
+			files.MaxBytes = s.MaxBytes
+		}
+		if s.MaxAge > 0 {
+			files.MaxAge = time.Duration(s.MaxAge)
+		}
+		return files, nil
+	case "webhook":
+		var secret []byte
+		if s.SecretEnv != "" {
+			secret = []byte(os.Getenv(s.SecretEnv))
+		}
+		webhook := sink.NewWebhook(s.URL, secret)
+		webhook.BatchSize = s.BatchSize
+		webhook.DeadLetter = s.DeadLetter
+		if s.Format == "protobuf" {
+			webhook.UseProtobuf()
+		}
+		return webhook, nil
+	case "kafka", "redis", "sql":
+		return nil, fmt.Errorf("%s: %w", s.Type, ErrClientRequired)
+	default:
+		return nil, fmt.Errorf("config: unknown sink %q", s.Type)
+	}
+}
+
+// Serializer returns the serializer of the Format of s, for the Serialize
+// field of kafka and redis sinks built with their clients.
+func (s Sink) Serializer() sink.Serializer {
+	if s.Format == "protobuf" {
+		return sink.ProtobufSerializer
+	}
+	return sink.JSONSerializer
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// countColumns are the table columns of counts.
+var countColumns = []column{{"start", 20}, {"end", 20}, {"tweets", 0}}
+
+// runCounts implements the counts command: it prints the number of Tweets
+// of the last seven days matching a query, to estimate the volume of a rule
+// before adding it, e.g.
+//
+//	twstream counts --granularity day "cat has:images"
+func runCounts(ctx context.Context, client *http.Client, args []string) error {
+	flags := flag.NewFlagSet("counts", flag.ContinueOnError)
+	granularity := flags.String("granularity", stream.GranularityHour, "granularity of the counts: minute, hour or day")
+	output := flags.String("output", "table", "output format: "+strings.Join(outputFormats, ", "))
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: twstream counts [flags] <query>")
+		return errUsage
+	}
+	out, err := newPrinter(*output, os.Stdout, countColumns...)
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	srv, err := newService(ctx, client)
+	if err != nil {
+		return err
+	}
+	counts, total, err := srv.Counts().Estimate(ctx, &stream.CountsParams{Query: flags.Arg(0), Granularity: *granularity})
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(counts))
+	rows := make([][]string, len(counts))
+	for i, count := range counts {
+		values[i] = count
+		rows[i] = []string{count.Start.Format(time.RFC3339), count.End.Format(time.RFC3339), strconv.Itoa(count.TweetCount)}
+	}
+	if err := out.list(values, rows); err != nil {
+		return err
+	}
+	if *output == "table" {
+		fmt.Printf("total %d\n", total)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package dashboard serves a status page of a stream for operators: the
+// connection state, delivery rate, recent connection events, the active
+// rules and a live tail of the last Tweets.
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+const (
+	// eventsKept and tweetsKept are the number of recent events and
+	// Tweets shown.
+	eventsKept = 20
+	tweetsKept = 10
+	// rulesTTL is how long rules are cached, as the rules endpoint is rate
+	// limited.
+	rulesTTL = 30 * time.Second
+)
+
+// Dashboard serves the status page of a stream on its path, e.g.
+// /dashboard, and the state shown on the page as JSON on the path with
+// /state appended. It follows the Events of the stream, which must not be
+// read by others, and is a sink.Sink receiving the Tweets of the live tail:
+//
+//	d := dashboard.New(s, srv)
+//	go d.Run()
+//	go sink.Pump(s.Subscribe(16, stream.OverflowDropOldest).Messages, d, nil)
+//	http.Handle("/dashboard", d)
+//	http.Handle("/dashboard/state", d)
+type Dashboard struct {
+	stream *stream.Stream
+	srv    *stream.StreamService
+
+	mu       sync.Mutex
+	events   []Event
+	tweets   []Tweet
+	rules    []*stream.Rule
+	rulesErr string
+	rulesAt  time.Time
+}
+
+// Event is a connection event shown on the dashboard.
+type Event struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error,omitempty"`
+	Wait  string    `json:"wait,omitempty"`
+}
+
+// Tweet is a Tweet of the live tail.
+type Tweet struct {
+	ID         string    `json:"id"`
+	Text       string    `json:"text"`
+	AuthorID   string    `json:"author_id,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// State is the state shown on the dashboard.
+type State struct {
+	Time   time.Time      `json:"time"`
+	State  string         `json:"state"`
+	Stats  stream.Stats   `json:"stats"`
+	Events []Event        `json:"events"`
+	Tweets []Tweet        `json:"tweets"`
+	Rules  []*stream.Rule `json:"rules"`
+	// RulesError is why the rules could not be listed.
+	RulesError string `json:"rules_error,omitempty"`
+}
+
+// New returns a Dashboard of s, listing the rules of srv.
+func New(s *stream.Stream, srv *stream.StreamService) *Dashboard {
+	return &Dashboard{stream: s, srv: srv}
+}
+
+// Run follows the events of the stream until it stopped.
+func (d *Dashboard) Run() {
+	for e := range d.stream.Events {
+		if e.Type == stream.EventHeartbeat {
+			continue
+		}
+		event := Event{Type: e.Type.String(), Time: e.Time}
+		if e.Err != nil {
+			event.Error = e.Err.Error()
+		}
+		if e.Type == stream.EventBackoff {
+			event.Wait = e.Wait.String()
+		}
+		d.mu.Lock()
+		d.events = keepLast(append(d.events, event), eventsKept)
+		d.mu.Unlock()
+	}
+}
+
+// Write adds the Tweet of the message to the live tail.
+func (d *Dashboard) Write(msg *stream.StreamData) error {
+	if msg.Tweet == nil {
+		return nil
+	}
+	tweet := Tweet{ID: msg.Tweet.ID, Text: msg.Tweet.Text, AuthorID: msg.Tweet.AuthorID, ReceivedAt: time.Now()}
+	for _, rule := range msg.MatchingRules {
+		tweet.Tags = append(tweet.Tags, rule.Tag)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tweets = keepLast(append(d.tweets, tweet), tweetsKept)
+	return nil
+}
+
+// Close does nothing, the dashboard keeps showing the last Tweets.
+func (d *Dashboard) Close() error {
+	return nil
+}
+
+// State returns the state shown on the dashboard, listing the rules if
+// the cached ones expired.
+func (d *Dashboard) State(ctx context.Context) State {
+	d.refreshRules(ctx)
+	stats := d.stream.Stats()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return State{
+		Time:       time.Now(),
+		State:      stats.State.String(),
+		Stats:      stats,
+		Events:     append([]Event(nil), d.events...),
+		Tweets:     append([]Tweet(nil), d.tweets...),
+		Rules:      d.rules,
+		RulesError: d.rulesErr,
+	}
+}
+
+func (d *Dashboard) refreshRules(ctx context.Context) {
+	d.mu.Lock()
+	fresh := time.Since(d.rulesAt) < rulesTTL
+	d.mu.Unlock()
+	if fresh || d.srv == nil {
+		return
+	}
+	rules, err := d.srv.GetRules(ctx)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rulesAt = time.Now()
+	if err != nil {
+		d.rulesErr = err.Error()
+		return
+	}
+	d.rules, d.rulesErr = rules, ""
+}
+
+// ServeHTTP serves the state as JSON on paths ending in /state, and the
+// page otherwise.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if strings.HasSuffix(r.URL.Path, "/state") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.State(r.Context()))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
+
+// keepLast returns the last n elements of s.
+func keepLast[T any](s []T, n int) []T {
+	if len(s) <= n {
+		return s
+	}
+	return append(s[:0], s[len(s)-n:]...)
+}
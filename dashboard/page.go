@@ -0,0 +1,98 @@
+package dashboard
+
+// page is the status page, polling the state every second. Tweets and rules
+// are rendered as text only.
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Stream dashboard</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.1em; margin-top: 1.5em; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: .6em 1em; min-width: 9em; }
+.card b { display: block; font-size: 1.5em; }
+.connected { color: #1a7f37; }
+.backoff, .disconnected, .stopped, .stall { color: #cf222e; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .25em .6em; border-bottom: 1px solid #eee; vertical-align: top; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>Stream dashboard</h1>
+<div class="cards">
+  <div class="card">State<b id="state">…</b></div>
+  <div class="card">Tweets/sec<b id="rate">…</b></div>
+  <div class="card">Delivered<b id="delivered">…</b></div>
+  <div class="card">Dropped<b id="dropped">…</b></div>
+  <div class="card">Reconnects<b id="reconnects">…</b></div>
+  <div class="card">Last message<b id="last">…</b></div>
+</div>
+<h2>Live tail</h2>
+<table><thead><tr><th>Received</th><th>Tweet</th><th>Tags</th><th>Text</th></tr></thead><tbody id="tweets"></tbody></table>
+<h2>Recent events</h2>
+<table><thead><tr><th>Time</th><th>Event</th><th>Detail</th></tr></thead><tbody id="events"></tbody></table>
+<h2>Active rules</h2>
+<p id="rules-error" class="muted"></p>
+<table><thead><tr><th>ID</th><th>Tag</th><th>Value</th></tr></thead><tbody id="rules"></tbody></table>
+<script>
+const stateURL = location.pathname.replace(/\/$/, "") + "/state";
+let previous;
+
+function text(id, value) {
+  document.getElementById(id).textContent = value;
+}
+
+function rows(id, items, cells) {
+  const body = document.getElementById(id);
+  body.replaceChildren(...items.map(item => {
+    const tr = document.createElement("tr");
+    for (const value of cells(item)) {
+      const td = document.createElement("td");
+      td.textContent = value;
+      tr.appendChild(td);
+    }
+    return tr;
+  }));
+}
+
+function ago(time) {
+  const t = Date.parse(time);
+  if (!t || t < 0) return "never";
+  return Math.max(0, Math.round((Date.now() - t) / 1000)) + "s ago";
+}
+
+async function refresh() {
+  try {
+    const s = await (await fetch(stateURL)).json();
+    const state = document.getElementById("state");
+    state.textContent = s.state;
+    state.className = s.state.split(" ")[0];
+    const now = Date.parse(s.time);
+    if (previous) {
+      const rate = (s.stats.Delivered - previous.delivered) / ((now - previous.time) / 1000);
+      text("rate", rate.toFixed(1));
+    }
+    previous = {time: now, delivered: s.stats.Delivered};
+    text("delivered", s.stats.Delivered);
+    text("dropped", s.stats.Dropped);
+    text("reconnects", Object.values(s.stats.Reconnects || {}).reduce((a, b) => a + b, 0));
+    text("last", ago(s.stats.LastMessageAt));
+    rows("tweets", s.tweets.slice().reverse(), t => [ago(t.received_at), t.id, (t.tags || []).join(", "), t.text]);
+    rows("events", s.events.slice().reverse(), e => [new Date(e.time).toLocaleTimeString(), e.type, e.error || e.wait || ""]);
+    rows("rules", s.rules || [], r => [r.id, r.tag || "", r.value]);
+    text("rules-error", s.rules_error ? "Rules unavailable: " + s.rules_error : "");
+  } catch (e) {
+    text("state", "unreachable");
+  }
+}
+
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,94 @@
+// Package health serves the liveness and readiness of a stream for
+// orchestrators such as Kubernetes probes.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Checker checks the health and readiness of a stream. Mount its handlers
+// for liveness and readiness probes:
+//
+//	c := health.NewChecker(s)
+//	http.HandleFunc("/healthz", c.ServeHealthz)
+//	http.HandleFunc("/readyz", c.ServeReadyz)
+type Checker struct {
+	stream *stream.Stream
+	// MaxSilence is how long a connected stream may receive neither a
+	// message nor a keep-alive and still be ready. Twitter sends a
+	// keep-alive every 20 seconds.
+	MaxSilence time.Duration
+	// MaxFailures is the number of consecutive failed connect attempts
+	// after which the stream is unhealthy.
+	MaxFailures uint64
+}
+
+// NewChecker returns a Checker of s, ready while keep-alives arrive and
+// unhealthy after 5 consecutive failed connect attempts.
+func NewChecker(s *stream.Stream) *Checker {
+	return &Checker{stream: s, MaxSilence: time.Minute, MaxFailures: 5}
+}
+
+// Healthy returns why the stream is unhealthy, or nil: it stopped, or
+// failed to connect MaxFailures times in a row.
+func (c *Checker) Healthy() error {
+	stats := c.stream.Stats()
+	if stats.State == stream.EventStopped {
+		return fmt.Errorf("stream stopped")
+	}
+	if c.MaxFailures > 0 && stats.ConnectFailures >= c.MaxFailures {
+		return fmt.Errorf("%d consecutive failed connect attempts", stats.ConnectFailures)
+	}
+	return nil
+}
+
+// Ready returns why the stream is not ready, or nil: it is not connected,
+// or received nothing for MaxSilence since connecting.
+func (c *Checker) Ready() error {
+	stats := c.stream.Stats()
+	if stats.State != stream.EventConnected {
+		return fmt.Errorf("stream %s", stats.State)
+	}
+	last := stats.ConnectedAt
+	for _, t := range []time.Time{stats.LastKeepAliveAt, stats.LastMessageAt} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	if silence := time.Since(last); c.MaxSilence > 0 && silence > c.MaxSilence {
+		return fmt.Errorf("nothing received for %s", silence.Round(time.Second))
+	}
+	return nil
+}
+
+// ServeHealthz answers 200 while the stream is healthy and 503 otherwise.
+func (c *Checker) ServeHealthz(w http.ResponseWriter, r *http.Request) {
+	serve(w, c.Healthy())
+}
+
+// ServeReadyz answers 200 while the stream is ready and 503 otherwise.
+func (c *Checker) ServeReadyz(w http.ResponseWriter, r *http.Request) {
+	serve(w, c.Ready())
+}
+
+// status is the body of health responses.
+type status struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func serve(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(status{Status: "unavailable", Reason: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(status{Status: "ok"})
+}
@@ -0,0 +1,188 @@
+//go:build integration
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/config"
+	"github.com/kalvin807/twitter-v2-stream/shutdown"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streamtest"
+)
+
+// integrationToken is the bearer token the fake API accepts.
+const integrationToken = "integration-token"
+
+// integrationScript is the sequence of connections the fake stream endpoint
+// serves.
+var integrationScript = []streamtest.Connection{
+	streamtest.Disconnect(integrationTweets("1", "2", "3")...),
+	streamtest.RateLimit(time.Second),
+	streamtest.Status(http.StatusServiceUnavailable),
+	streamtest.Connected(integrationTweets("4", "5")...),
+}
+
+// integrationRules are the rules of the configuration file, which the
+// consumer adds to the fake API before connecting.
+var integrationRules = []config.Rule{
+	{Value: "integration", Tag: "integration"},
+	{Value: "cat has:images", Tag: "cats"},
+}
+
+// integrationTweets returns the events of the Tweets, with a keep-alive
+// after each.
+func integrationTweets(ids ...string) []streamtest.Event {
+	var events []streamtest.Event
+	for _, id := range ids {
+		events = append(events, streamtest.Tweet(id, "tweet "+id, "integration"), streamtest.KeepAlive())
+	}
+	return events
+}
+
+// TestIntegration runs twstream end to end against a scripted fake of the
+// Twitter API, injecting a disconnect and a rate limit, and checks the rules
+// it synced from its configuration file, the delivered Tweets, the NDJSON
+// archive of its file sink and the shutdown report. Run it with
+//
+//	go test -tags integration -run TestIntegration .
+func TestIntegration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	dir := t.TempDir()
+
+	binary := filepath.Join(dir, "twstream")
+	build := exec.CommandContext(ctx, "go", "build", "-o", binary, ".")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		t.Fatalf("build twstream: %v", err)
+	}
+
+	server := streamtest.NewServer(integrationScript...)
+	server.Token = integrationToken
+	defer server.Close()
+
+	archive := filepath.Join(dir, "archive")
+	configPath := filepath.Join(dir, "config.json")
+	data, err := json.Marshal(&config.Config{
+		Rules: integrationRules,
+		Sinks: []config.Sink{{Type: "file", Dir: archive}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reportPath := filepath.Join(dir, "shutdown.json")
+	consumer := exec.CommandContext(ctx, binary, "stream", "--config", configPath, "--addr", "")
+	consumer.Env = append(os.Environ(),
+		"TWITTER_TOKEN="+integrationToken,
+		"TWITTER_BASE_URL="+server.URL,
+		"SHUTDOWN_REPORT="+reportPath,
+	)
+	consumer.Stderr = os.Stderr
+	stdout, err := consumer.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start twstream: %v", err)
+	}
+	defer consumer.Process.Kill()
+
+	want := []string{"1", "2", "3", "4", "5"}
+	var got []string
+	scanner := bufio.NewScanner(stdout)
+	for len(got) < len(want) && scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("delivered Tweets %v, want %v", got, want)
+	}
+	if attempts := server.Connects(); attempts < len(integrationScript) {
+		t.Fatalf("%d connect attempts, want %d", attempts, len(integrationScript))
+	}
+	var synced []config.Rule
+	for _, rule := range server.Rules() {
+		synced = append(synced, config.Rule{Value: rule.Value, Tag: rule.Tag})
+	}
+	if !reflect.DeepEqual(synced, integrationRules) {
+		t.Fatalf("synced rules %v, want %v", synced, integrationRules)
+	}
+
+	// twstream must shut down gracefully and report what it delivered
+	if err := consumer.Process.Signal(syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.Wait(); err != nil {
+		t.Fatalf("twstream exited: %v", err)
+	}
+	if archived := readArchive(t, archive); !reflect.DeepEqual(archived, want) {
+		t.Fatalf("archived Tweets %v, want %v", archived, want)
+	}
+	data, err = os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("read shutdown report: %v", err)
+	}
+	var report shutdown.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode shutdown report: %v", err)
+	}
+	if report.Delivered != uint64(len(want)) || report.Dropped != 0 || report.LastCheckpoint != "5" {
+		t.Fatalf("shutdown report %s, want %d delivered, none dropped, checkpoint 5", data, len(want))
+	}
+	if len(report.Sinks) != 1 || report.Sinks[0].Acked != uint64(len(want)) || report.Sinks[0].Failed != 0 {
+		t.Fatalf("shutdown report %s, want the file sink to have acked %d", data, len(want))
+	}
+}
+
+// readArchive returns the IDs of the Tweets in the NDJSON files of the file
+// sink in dir, checking that each matched the integration rule.
+func readArchive(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no files in %s", dir)
+	}
+	// file names are the prefix and the creation time, so they sort in order
+	sort.Strings(paths)
+	var ids []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var msg stream.StreamData
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			if msg.Tweet == nil || len(msg.MatchingRules) != 1 || msg.MatchingRules[0].Tag != "integration" {
+				t.Fatalf("%s: unexpected record %s", path, scanner.Bytes())
+			}
+			ids = append(ids, msg.Tweet.ID)
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids
+}
@@ -1,39 +1,103 @@
+// Command twstream streams Tweets from the Twitter v2 filtered and sample
+// streams and manages the rules of the filtered stream, e.g.
+//
+//	twstream stream --fields created_at --backfill 2 --output ndjson
+//	twstream rules add --tag cats "cat has:images"
+//	twstream counts --granularity day "cat has:images"
+//
+// It authenticates with TWITTER_TOKEN, or with TWITTER_CONSUMER_KEY and
+// TWITTER_CONSUMER_SECRET.
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
-	"github.com/kalvin807/twitter-v2-stream/internal/stream"
+	"github.com/kalvin807/twitter-v2-stream/stream"
 )
 
-// Use the stream
-func HandleChan(messages <-chan *stream.StreamData) {
-	for message := range messages {
-		fmt.Println(message.Tweet.ID)
+const usage = `Usage: twstream <command> [flags] [args]
+
+Commands:
+  stream     print the Tweets of the filtered stream (default)
+  sample     print the Tweets of the 1% sample stream
+  rules      list, add or delete the rules of the filtered stream
+  counts     count the recent Tweets matching a query
+  backfill   print the Tweets of the rules within a time range
+  replay     replay a recording of the stream
+
+Run twstream <command> -h for the flags of a command.
+`
+
+// newService authenticates with TWITTER_TOKEN, or with the app-only token
+// of TWITTER_CONSUMER_KEY and TWITTER_CONSUMER_SECRET if it is not set.
+// TWITTER_BASE_URL, if set, overrides the API host, e.g. for a fake API.
+func newService(ctx context.Context, client *http.Client, opts ...stream.Option) (*stream.StreamService, error) {
+	if baseURL := os.Getenv("TWITTER_BASE_URL"); baseURL != "" {
+		opts = append(opts, stream.WithBaseURL(baseURL))
 	}
+	if token := os.Getenv("TWITTER_TOKEN"); token != "" {
+		return stream.NewStreamService(client, token, opts...), nil
+	}
+	return stream.NewAppStreamService(ctx, client, os.Getenv("TWITTER_CONSUMER_KEY"), os.Getenv("TWITTER_CONSUMER_SECRET"), opts...)
 }
 
-// Demo
 func main() {
-	token := os.Getenv("TWITTER_TOKEN")
-	client := http.DefaultClient
-	v2Service := stream.NewStreamService(client, token)
-	params := &stream.StreamFilterParams{}
-	v2, err := v2Service.Connect(params)
-	if err != nil {
-		panic(err)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client := stream.NewHTTPClient()
+	args := os.Args[1:]
+	command := "stream"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
-	go HandleChan(v2.Messages)
+	var err error
+	switch command {
+	case "stream", "sample":
+		err = runStream(ctx, client, args, command == "sample")
+	case "rules":
+		err = runRules(ctx, client, args)
+	case "counts":
+		err = runCounts(ctx, client, args)
+	case "backfill":
+		err = runBackfill(ctx, client, args)
+	case "replay":
+		err = runReplay(ctx, client, args)
+	case "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "twstream: unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		log.Fatal(err)
+	}
+}
 
-	http.ListenAndServe("0.0.0.0:8080", nil)
+// errUsage is returned by commands called with invalid arguments, after
+// printing why.
+var errUsage = errors.New("usage")
 
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	log.Println(<-ch)
-	v2.Stop()
+// parseFlags parses the flags of a command, which prints why they are
+// invalid, returning errUsage if they are.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
 }
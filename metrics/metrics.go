@@ -0,0 +1,268 @@
+// Package metrics exposes the counters and gauges of streams as a
+// Prometheus collector.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+// states are the connection states exposed by the connection_state gauge.
+var states = []stream.EventType{
+	stream.EventConnecting,
+	stream.EventConnected,
+	stream.EventDisconnected,
+	stream.EventBackoff,
+	stream.EventStopped,
+}
+
+// statMetric is a metric of each stream read from its Stats.
+type statMetric struct {
+	name  string
+	help  string
+	kind  prometheus.ValueType
+	value func(stream.Stats) float64
+}
+
+func counter(name, help string, value func(stream.Stats) uint64) statMetric {
+	return statMetric{name, help, prometheus.CounterValue, func(s stream.Stats) float64 { return float64(value(s)) }}
+}
+
+func gauge(name, help string, value func(stream.Stats) float64) statMetric {
+	return statMetric{name, help, prometheus.GaugeValue, value}
+}
+
+var statMetrics = []statMetric{
+	counter("tweets_received_total", "Messages read from the connection.", func(s stream.Stats) uint64 { return s.Received }),
+	counter("tweets_delivered_total", "Messages sent on the Messages channel.", func(s stream.Stats) uint64 { return s.Delivered }),
+	counter("tweets_dropped_total", "Received messages which were not delivered.", func(s stream.Stats) uint64 { return s.Dropped }),
+	counter("tweets_overflowed_total", "Messages dropped by the overflow policy.", func(s stream.Stats) uint64 { return s.Overflowed }),
+	counter("tweets_filtered_total", "Messages rejected by client-side filters.", func(s stream.Stats) uint64 { return s.Filtered }),
+	counter("tweets_duplicates_total", "Tweets suppressed as duplicates.", func(s stream.Stats) uint64 { return s.Duplicates }),
+	counter("handled_total", "Messages run through the handler of Handle.", func(s stream.Stats) uint64 { return s.Handled }),
+	counter("handler_errors_total", "Messages for which the handler returned an error.", func(s stream.Stats) uint64 { return s.HandlerErrors }),
+	counter("handler_panics_total", "Messages for which the handler panicked.", func(s stream.Stats) uint64 { return s.HandlerPanics }),
+	counter("handler_timeouts_total", "Runs of the handler which timed out.", func(s stream.Stats) uint64 { return s.HandlerTimeouts }),
+	counter("keep_alives_total", "Keep-alive lines read from the connection.", func(s stream.Stats) uint64 { return s.KeepAlives }),
+	counter("decode_errors_total", "Messages which could not be decoded.", func(s stream.Stats) uint64 { return s.DecodeErrors }),
+	counter("rotations_total", "Connections replaced without a gap by Rotate.", func(s stream.Stats) uint64 { return s.Rotations }),
+	counter("bytes_read_total", "Bytes read from connections.", func(s stream.Stats) uint64 { return s.BytesRead }),
+	gauge("last_message_timestamp_seconds", "Unix time of the last received message.", func(s stream.Stats) float64 { return unixSeconds(s.LastMessageAt) }),
+	gauge("last_keep_alive_timestamp_seconds", "Unix time of the last received keep-alive.", func(s stream.Stats) float64 { return unixSeconds(s.LastKeepAliveAt) }),
+	gauge("backoff_seconds", "Wait before the next connect attempt while backing off.", func(s stream.Stats) float64 { return s.Backoff.Seconds() }),
+	gauge("circuit_open", "1 while the circuit breaker is open.", func(s stream.Stats) float64 {
+		if s.CircuitOpen {
+			return 1
+		}
+		return 0
+	}),
+}
+
+// rateLimitMetric is a metric of each endpoint a service sent requests to.
+type rateLimitMetric struct {
+	name  string
+	help  string
+	value func(stream.RateLimit) float64
+}
+
+var rateLimitMetrics = []rateLimitMetric{
+	{"rate_limit_limit", "Requests allowed per rate limit window of the endpoint.", func(r stream.RateLimit) float64 { return float64(r.Limit) }},
+	{"rate_limit_remaining", "Requests left in the rate limit window of the endpoint.", func(r stream.RateLimit) float64 { return float64(r.Remaining) }},
+	{"rate_limit_reset_timestamp_seconds", "Unix time the rate limit window of the endpoint resets.", func(r stream.RateLimit) float64 { return unixSeconds(r.Reset) }},
+}
+
+// Handler collects the metrics of streams, read on each scrape and labelled
+// with the name each stream was added with, and the rate limits of the
+// endpoints of services. It serves them itself when mounted on /metrics:
+//
+//	h := metrics.NewHandler("twitter")
+//	h.Add("filtered", s)
+//	h.AddService("filtered", srv)
+//	http.Handle("/metrics", h)
+//
+// and is a prometheus.Collector, so an app which serves its own metrics
+// with client_golang registers it instead:
+//
+//	prometheus.MustRegister(h)
+type Handler struct {
+	mu       sync.Mutex
+	streams  map[string]*stream.Stream
+	services map[string]*stream.StreamService
+
+	registry   *prometheus.Registry
+	handler    http.Handler
+	stats      []*prometheus.Desc
+	reconnects *prometheus.Desc
+	state      *prometheus.Desc
+	backlog    *prometheus.Desc
+	rateLimits []*prometheus.Desc
+}
+
+// NewHandler returns a Handler prefixing metric names with namespace.
+func NewHandler(namespace string) *Handler {
+	name := func(name string) string {
+		return prometheus.BuildFQName(namespace, "stream", name)
+	}
+	h := &Handler{
+		streams:    make(map[string]*stream.Stream),
+		services:   make(map[string]*stream.StreamService),
+		registry:   prometheus.NewRegistry(),
+		reconnects: prometheus.NewDesc(name("reconnects_total"), "Reconnects by the status code of the attempt which caused them, 0 for transport errors.", []string{"stream", "status"}, nil),
+		state:      prometheus.NewDesc(name("connection_state"), "1 for the current connection state of the stream.", []string{"stream", "state"}, nil),
+		backlog:    prometheus.NewDesc(name("messages_backlog"), "Messages buffered in the Messages channel.", []string{"stream"}, nil),
+	}
+	for _, m := range statMetrics {
+		h.stats = append(h.stats, prometheus.NewDesc(name(m.name), m.help, []string{"stream"}, nil))
+	}
+	for _, m := range rateLimitMetrics {
+		h.rateLimits = append(h.rateLimits, prometheus.NewDesc(name(m.name), m.help, []string{"stream", "endpoint"}, nil))
+	}
+	h.registry.MustRegister(h)
+	h.handler = promhttp.HandlerFor(h.registry, promhttp.HandlerOpts{})
+	return h
+}
+
+// Add collects the metrics of s, labelled stream=name. A stream added with
+// the name of another replaces it, e.g. after reconnecting.
+func (h *Handler) Add(name string, s *stream.Stream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.streams[name] = s
+}
+
+// AddService collects the rate limits of the endpoints srv sent requests
+// to, labelled stream=name and by endpoint, see StreamService.RateLimits.
+func (h *Handler) AddService(name string, srv *stream.StreamService) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.services[name] = srv
+}
+
+// Remove stops collecting the metrics of the named stream and service.
+func (h *Handler) Remove(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.streams, name)
+	delete(h.services, name)
+}
+
+// sample is a stream's snapshot taken for a scrape.
+type sample struct {
+	name    string
+	stats   stream.Stats
+	backlog int
+}
+
+func (h *Handler) samples() []sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := make([]sample, 0, len(h.streams))
+	for name, s := range h.streams {
+		samples = append(samples, sample{name: name, stats: s.Stats(), backlog: len(s.Messages)})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].name < samples[j].name })
+	return samples
+}
+
+// rateLimitSample is a service's rate limits taken for a scrape.
+type rateLimitSample struct {
+	name   string
+	limits []stream.EndpointRateLimit
+}
+
+func (h *Handler) rateLimitSamples() []rateLimitSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := make([]rateLimitSample, 0, len(h.services))
+	for name, srv := range h.services {
+		samples = append(samples, rateLimitSample{name: name, limits: srv.RateLimits()})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].name < samples[j].name })
+	return samples
+}
+
+// Describe implements prometheus.Collector.
+func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range h.stats {
+		ch <- desc
+	}
+	ch <- h.reconnects
+	ch <- h.state
+	ch <- h.backlog
+	for _, desc := range h.rateLimits {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (h *Handler) Collect(ch chan<- prometheus.Metric) {
+	samples := h.samples()
+	for i, m := range statMetrics {
+		for _, s := range samples {
+			ch <- prometheus.MustNewConstMetric(h.stats[i], m.kind, m.value(s.stats), s.name)
+		}
+	}
+	for _, s := range samples {
+		for code, n := range s.stats.Reconnects {
+			ch <- prometheus.MustNewConstMetric(h.reconnects, prometheus.CounterValue, float64(n), s.name, strconv.Itoa(code))
+		}
+		for _, state := range states {
+			value := 0.0
+			if s.stats.State == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(h.state, prometheus.GaugeValue, value, s.name, state.String())
+		}
+		ch <- prometheus.MustNewConstMetric(h.backlog, prometheus.GaugeValue, float64(s.backlog), s.name)
+	}
+	rateLimits := h.rateLimitSamples()
+	for i, m := range rateLimitMetrics {
+		for _, s := range rateLimits {
+			for _, limit := range s.limits {
+				ch <- prometheus.MustNewConstMetric(h.rateLimits[i], prometheus.GaugeValue, m.value(limit.RateLimit), s.name, limit.Endpoint)
+			}
+		}
+	}
+}
+
+// WriteTo writes the metrics of all streams to w in the text exposition
+// format, e.g. to merge them with the output of another exporter.
+func (h *Handler) WriteTo(w io.Writer) (int64, error) {
+	families, err := h.registry.Gather()
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+	var written int64
+	for _, family := range families {
+		n, err := expfmt.MetricFamilyToText(bw, family)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, bw.Flush()
+}
+
+// ServeHTTP serves the metrics for Prometheus to scrape.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+// unixSeconds returns t in Unix seconds, or zero if t is zero.
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
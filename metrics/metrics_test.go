@@ -0,0 +1,154 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/metrics"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const message = `{"data":{"id":"1","text":"hello"},"matching_rules":[{"id":"10","tag":"greetings"}]}` + "\r\n"
+
+// pipeTransport answers requests with what is written to the pipe.
+type pipeTransport struct {
+	body *io.PipeReader
+}
+
+func (t *pipeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       t.body,
+		Request:    req,
+	}, nil
+}
+
+// receive connects a stream, and receives two messages on a connection
+// which then stays open.
+func receive(t *testing.T) *stream.Stream {
+	pr, pw := io.Pipe()
+	go pw.Write([]byte(strings.Repeat(message, 2)))
+	client := &http.Client{Transport: &pipeTransport{body: pr}}
+	srv := stream.NewStreamService(client, "token",
+		stream.WithBaseURL("http://memory"),
+		stream.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	s, err := srv.Connect(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pw.Close()
+		s.Stop()
+	})
+	for i := 0; i < 2; i++ {
+		<-s.Messages
+	}
+	return s
+}
+
+func TestCollector(t *testing.T) {
+	h := metrics.NewHandler("twitter")
+	h.Add("filtered", receive(t))
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(h); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		got[family.GetName()] = family
+	}
+
+	tests := []struct {
+		name   string
+		kind   dto.MetricType
+		labels map[string]string
+		value  float64
+	}{
+		{"twitter_stream_tweets_received_total", dto.MetricType_COUNTER, map[string]string{"stream": "filtered"}, 2},
+		{"twitter_stream_decode_errors_total", dto.MetricType_COUNTER, map[string]string{"stream": "filtered"}, 0},
+		{"twitter_stream_connection_state", dto.MetricType_GAUGE, map[string]string{"stream": "filtered", "state": stream.EventConnected.String()}, 1},
+		{"twitter_stream_messages_backlog", dto.MetricType_GAUGE, map[string]string{"stream": "filtered"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			family := got[tt.name]
+			if family == nil {
+				t.Fatal("not collected")
+			}
+			if family.GetType() != tt.kind {
+				t.Errorf("type %v, want %v", family.GetType(), tt.kind)
+			}
+			for _, m := range family.GetMetric() {
+				if !hasLabels(m, tt.labels) {
+					continue
+				}
+				value := m.GetCounter().GetValue() + m.GetGauge().GetValue()
+				if value != tt.value {
+					t.Errorf("value %g, want %g", value, tt.value)
+				}
+				return
+			}
+			t.Errorf("no metric labelled %v", tt.labels)
+		})
+	}
+}
+
+func hasLabels(m *dto.Metric, labels map[string]string) bool {
+	n := 0
+	for _, pair := range m.GetLabel() {
+		if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+			n++
+		}
+	}
+	return n == len(labels)
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := metrics.NewHandler("")
+	h.Add("filtered", receive(t))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	var buf bytes.Buffer
+	n, err := h.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo = %d, %v; wrote %d bytes", n, err, buf.Len())
+	}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"ServeHTTP", rec.Body.String()},
+		{"WriteTo", buf.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range []string{
+				"# TYPE stream_tweets_received_total counter\n",
+				`stream_tweets_received_total{stream="filtered"} 2` + "\n",
+				`stream_connection_state{state="connected",stream="filtered"} 1` + "\n",
+			} {
+				if !strings.Contains(tt.body, want) {
+					t.Errorf("body lacks %q:\n%s", want, tt.body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// outputFormats are the values of --output.
+var outputFormats = []string{"id", "ndjson", "json", "table"}
+
+// column is a column of table output. The last column is not padded.
+type column struct {
+	name  string
+	width int
+}
+
+// printer writes records in an output format: their first column, as
+// newline delimited or indented JSON, or as table rows.
+type printer struct {
+	format  string
+	w       io.Writer
+	columns []column
+	header  bool
+}
+
+func newPrinter(format string, w io.Writer, columns ...column) (*printer, error) {
+	for _, f := range outputFormats {
+		if f == format {
+			return &printer{format: format, w: w, columns: columns}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown output format %q, want one of %s", format, strings.Join(outputFormats, ", "))
+}
+
+// record writes a record, v encoded as JSON and row as its columns.
+func (p *printer) record(v any, row ...string) error {
+	switch p.format {
+	case "id":
+		_, err := fmt.Fprintln(p.w, row[0])
+		return err
+	case "ndjson":
+		return json.NewEncoder(p.w).Encode(v)
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	default:
+		if !p.header {
+			p.header = true
+			names := make([]string, len(p.columns))
+			for i, c := range p.columns {
+				names[i] = strings.ToUpper(c.name)
+			}
+			if err := p.row(names); err != nil {
+				return err
+			}
+		}
+		return p.row(row)
+	}
+}
+
+// list writes records, as one JSON array in json format.
+func (p *printer) list(values []any, rows [][]string) error {
+	if p.format == "json" {
+		if values == nil {
+			values = []any{}
+		}
+		return p.record(values)
+	}
+	for i, v := range values {
+		if err := p.record(v, rows[i]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// row writes a table row, padding and truncating cells to the widths of
+// their columns.
+func (p *printer) row(cells []string) error {
+	var b strings.Builder
+	for i, cell := range cells {
+		cell = strings.Join(strings.Fields(cell), " ")
+		if i == len(cells)-1 || i >= len(p.columns) {
+			b.WriteString(cell)
+			break
+		}
+		width := p.columns[i].width
+		if utf8.RuneCountInString(cell) > width {
+			cell = string([]rune(cell)[:width-1]) + "…"
+		}
+		b.WriteString(cell)
+		b.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(cell)+2))
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(p.w, b.String())
+	return err
+}
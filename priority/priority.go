@@ -0,0 +1,178 @@
+// Package priority delivers the messages of high-priority rule tags first
+// during backpressure, queueing messages in lanes drained by weight and
+// shedding the messages of low-priority lanes when they fill up.
+package priority
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// defaultCapacity is the capacity of lanes which do not set one.
+const defaultCapacity = 1024
+
+// Lane is a queue of the messages matching any of its rule tags.
+type Lane struct {
+	Name string
+	Tags []string
+	// Weight is the share of deliveries the lane gets while several lanes
+	// are backlogged. Zero counts as one.
+	Weight int
+	// Capacity is the number of messages the lane queues. Zero uses a
+	// default of 1024.
+	Capacity int
+	// Shed drops the messages arriving while the lane is full. Otherwise
+	// intake pauses until the lane has room, pushing back on the stream.
+	Shed bool
+}
+
+// lane is the state of a Lane.
+type lane struct {
+	Lane
+	queue   []*stream.StreamData
+	current int
+	depth   int64
+	shed    uint64
+}
+
+// Lanes routes messages to lanes by the tags of their matching rules. A
+// message matching the tags of several lanes goes to the first of them, and
+// one matching none goes to the last lane.
+type Lanes struct {
+	lanes  []*lane
+	byTag  map[string]int
+	byName map[string]*lane
+}
+
+// New returns Lanes with the lanes, in order of priority.
+func New(lanes ...Lane) (*Lanes, error) {
+	if len(lanes) == 0 {
+		return nil, fmt.Errorf("priority: no lanes")
+	}
+	l := &Lanes{byTag: make(map[string]int), byName: make(map[string]*lane)}
+	for i, spec := range lanes {
+		if _, ok := l.byName[spec.Name]; ok {
+			return nil, fmt.Errorf("priority: duplicate lane %q", spec.Name)
+		}
+		if spec.Weight <= 0 {
+			spec.Weight = 1
+		}
+		if spec.Capacity <= 0 {
+			spec.Capacity = defaultCapacity
+		}
+		ln := &lane{Lane: spec}
+		l.lanes = append(l.lanes, ln)
+		l.byName[spec.Name] = ln
+		for _, tag := range spec.Tags {
+			if _, ok := l.byTag[tag]; !ok {
+				l.byTag[tag] = i
+			}
+		}
+	}
+	return l, nil
+}
+
+// route returns the lane of msg.
+func (l *Lanes) route(msg *stream.StreamData) *lane {
+	best := len(l.lanes) - 1
+	for _, rule := range msg.MatchingRules {
+		if i, ok := l.byTag[rule.Tag]; ok && i < best {
+			best = i
+		}
+	}
+	return l.lanes[best]
+}
+
+// next returns the backlogged lane to deliver from by smooth weighted round
+// robin, or nil if all lanes are empty.
+func (l *Lanes) next() *lane {
+	var chosen *lane
+	total := 0
+	for _, ln := range l.lanes {
+		if len(ln.queue) == 0 {
+			continue
+		}
+		ln.current += ln.Weight
+		total += ln.Weight
+		if chosen == nil || ln.current > chosen.current {
+			chosen = ln
+		}
+	}
+	if chosen != nil {
+		chosen.current -= total
+	}
+	return chosen
+}
+
+// Drain queues the messages of in in their lanes and forwards them to the
+// returned channel, which is closed once in is closed and the lanes are
+// drained.
+func (l *Lanes) Drain(in <-chan *stream.StreamData) <-chan *stream.StreamData {
+	out := make(chan *stream.StreamData)
+	go func() {
+		defer close(out)
+		var (
+			head   *stream.StreamData
+			from   *lane
+			intake = in
+		)
+		for intake != nil || head != nil {
+			var send chan<- *stream.StreamData
+			if head != nil {
+				send = out
+			}
+			select {
+			case msg, ok := <-intake:
+				if !ok {
+					intake, in = nil, nil
+					break
+				}
+				ln := l.route(msg)
+				if len(ln.queue) >= ln.Capacity {
+					// only Shed lanes can be full here, see below
+					atomic.AddUint64(&ln.shed, 1)
+					break
+				}
+				ln.queue = append(ln.queue, msg)
+				atomic.AddInt64(&ln.depth, 1)
+			case send <- head:
+				from.queue[0] = nil
+				from.queue = from.queue[1:]
+				atomic.AddInt64(&from.depth, -1)
+				head, from = nil, nil
+			}
+			if head == nil {
+				if from = l.next(); from != nil {
+					head = from.queue[0]
+				}
+			}
+			// pause intake while a lane which does not shed is full
+			intake = in
+			for _, ln := range l.lanes {
+				if !ln.Shed && len(ln.queue) >= ln.Capacity {
+					intake = nil
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Depth returns the number of messages queued in the named lane, e.g. for a
+// watchdog.Watchdog to watch.
+func (l *Lanes) Depth(name string) int {
+	if ln, ok := l.byName[name]; ok {
+		return int(atomic.LoadInt64(&ln.depth))
+	}
+	return 0
+}
+
+// Shedded returns the number of messages the named lane dropped.
+func (l *Lanes) Shedded(name string) uint64 {
+	if ln, ok := l.byName[name]; ok {
+		return atomic.LoadUint64(&ln.shed)
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+// Package render converts Tweets into safe HTML or Markdown for display, such
+// as in chat notifications, emails or dashboards. URLs, mentions, hashtags
+// and cashtags are hyperlinked and media are rendered as placeholders.
+package render
+
+import (
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Format is an output format of Render.
+type Format int
+
+const (
+	// FormatHTML renders escaped HTML with anchors for entities.
+	FormatHTML Format = iota
+	// FormatMarkdown renders Markdown with inline links, escaping characters
+	// Markdown, Slack and Discord would interpret.
+	FormatMarkdown
+)
+
+// HTML renders the Tweet of the message as HTML.
+func HTML(msg *stream.StreamData) string {
+	return Render(FormatHTML, msg, msg.Tweet)
+}
+
+// Markdown renders the Tweet of the message as Markdown.
+func Markdown(msg *stream.StreamData) string {
+	return Render(FormatMarkdown, msg, msg.Tweet)
+}
+
+// Render renders a Tweet of the message, such as a quoted Tweet, in the
+// format, looking up media in the includes of the message. The full text of
+// long Tweets is rendered if the note_tweet field was requested.
+func Render(format Format, msg *stream.StreamData, tweet *stream.Tweet) string {
+	if tweet == nil {
+		return ""
+	}
+	w := writer(htmlWriter{})
+	if format == FormatMarkdown {
+		w = markdownWriter{}
+	}
+	text, entities := tweet.Text, tweet.Entities
+	if tweet.NoteTweet != nil {
+		text, entities = tweet.NoteTweet.Text, tweet.NoteTweet.Entities
+	}
+	media := map[string]*stream.Media{}
+	for _, m := range msg.MediaFor(tweet) {
+		media[m.MediaKey] = m
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	pos := 0
+	rendered := map[string]bool{}
+	for _, s := range spans(entities) {
+		if s.start < pos || s.end > len(runes) || s.start >= s.end {
+			// overlapping or out of range entities are rendered as text
+			continue
+		}
+		b.WriteString(w.text(string(runes[pos:s.start])))
+		label := string(runes[s.start:s.end])
+		switch {
+		case s.url != nil && s.url.MediaKey != "":
+			rendered[s.url.MediaKey] = true
+			b.WriteString(w.placeholder(media[s.url.MediaKey], s.url.ExpandedURL))
+		case s.url != nil:
+			href := s.url.ExpandedURL
+			if s.url.UnwoundURL != "" {
+				href = s.url.UnwoundURL
+			}
+			if href == "" {
+				href = s.url.URL
+			}
+			if s.url.DisplayURL != "" {
+				label = s.url.DisplayURL
+			}
+			b.WriteString(w.link(label, href))
+		default:
+			b.WriteString(w.link(label, s.href))
+		}
+		pos = s.end
+	}
+	b.WriteString(w.text(string(runes[pos:])))
+	for _, m := range msg.MediaFor(tweet) {
+		if !rendered[m.MediaKey] {
+			b.WriteString(w.text(" "))
+			b.WriteString(w.placeholder(m, ""))
+		}
+	}
+	return b.String()
+}
+
+// span is an entity of the text, from rune offset start to end.
+type span struct {
+	start, end int
+	href       string
+	url        *stream.URLEntity
+}
+
+// spans returns the entities sorted by offset.
+func spans(entities *stream.Entities) []span {
+	if entities == nil {
+		return nil
+	}
+	var spans []span
+	for _, u := range entities.URLs {
+		spans = append(spans, span{start: u.Start, end: u.End, url: u})
+	}
+	for _, m := range entities.Mentions {
+		spans = append(spans, span{start: m.Start, end: m.End, href: "https://twitter.com/" + url.PathEscape(m.Username)})
+	}
+	for _, h := range entities.Hashtags {
+		spans = append(spans, span{start: h.Start, end: h.End, href: "https://twitter.com/hashtag/" + url.PathEscape(h.Tag)})
+	}
+	for _, c := range entities.Cashtags {
+		spans = append(spans, span{start: c.Start, end: c.End, href: "https://twitter.com/search?q=" + url.QueryEscape("$"+c.Tag)})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// placeholderLabel returns the label of a media placeholder, such as
+// "[photo: a cat]".
+func placeholderLabel(m *stream.Media) string {
+	if m == nil {
+		return "[media]"
+	}
+	kind := m.Type
+	if kind == stream.MediaAnimatedGIF {
+		kind = "GIF"
+	}
+	if m.AltText != "" {
+		return "[" + kind + ": " + m.AltText + "]"
+	}
+	return "[" + kind + "]"
+}
+
+// mediaURL returns the URL to link a media placeholder to.
+func mediaURL(m *stream.Media, fallback string) string {
+	switch {
+	case m != nil && m.URL != "":
+		return m.URL
+	case m != nil && m.PreviewImageURL != "":
+		return m.PreviewImageURL
+	default:
+		return fallback
+	}
+}
+
+// safeURL reports whether href may be linked to.
+func safeURL(href string) bool {
+	u, err := url.Parse(href)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http")
+}
+
+// writer renders the parts of a Tweet. Tweet text is HTML escaped by Twitter,
+// so writers unescape it first.
+type writer interface {
+	text(s string) string
+	link(label, href string) string
+	placeholder(m *stream.Media, fallback string) string
+}
+
+type htmlWriter struct{}
+
+func (htmlWriter) text(s string) string {
+	return strings.ReplaceAll(html.EscapeString(html.UnescapeString(s)), "\n", "<br>")
+}
+
+func (w htmlWriter) link(label, href string) string {
+	if !safeURL(href) {
+		return w.text(label)
+	}
+	return `<a href="` + html.EscapeString(href) + `" rel="noopener noreferrer">` + w.text(label) + `</a>`
+}
+
+func (w htmlWriter) placeholder(m *stream.Media, fallback string) string {
+	label := `<span class="tweet-media">` + html.EscapeString(placeholderLabel(m)) + `</span>`
+	href := mediaURL(m, fallback)
+	if !safeURL(href) {
+		return label
+	}
+	return `<a href="` + html.EscapeString(href) + `" rel="noopener noreferrer">` + label + `</a>`
+}
+
+type markdownWriter struct{}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`,
+	">", `\>`, "<", `\<`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "#", `\#`,
+)
+
+func (markdownWriter) text(s string) string {
+	return markdownEscaper.Replace(html.UnescapeString(s))
+}
+
+func (w markdownWriter) link(label, href string) string {
+	if !safeURL(href) {
+		return w.text(label)
+	}
+	return "[" + w.text(label) + "](" + markdownURL(href) + ")"
+}
+
+func (w markdownWriter) placeholder(m *stream.Media, fallback string) string {
+	label := w.text(placeholderLabel(m))
+	href := mediaURL(m, fallback)
+	if !safeURL(href) {
+		return label
+	}
+	return "[" + label + "](" + markdownURL(href) + ")"
+}
+
+// markdownURL escapes the characters which would end an inline link.
+func markdownURL(href string) string {
+	return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20", "<", "%3C", ">", "%3E").Replace(href)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// runReplay implements the replay command: it replays a recording made with
+// twstream stream --record through the stream pipeline, printing or
+// archiving the messages and logging decode errors, e.g.
+//
+//	twstream replay --speed 10 --output table stream.rec
+func runReplay(ctx context.Context, client *http.Client, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := flags.Float64("speed", 1, "pace of the replay relative to the recording, 0 for as fast as possible")
+	output := flags.String("output", "id", "output format: "+strings.Join(outputFormats, ", "))
+	archive := flags.String("archive", "", "archive messages as NDJSON files in the directory instead of printing them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: twstream replay [flags] <recording>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *speed < 0 {
+		flags.Usage()
+		return errUsage
+	}
+	out, err := newPrinter(*output, os.Stdout, tweetColumns...)
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	recording, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+
+	start := time.Now()
+	v2, err := stream.NewStreamService(client, "").ConnectReplay(ctx, recording, *speed)
+	if err != nil {
+		return err
+	}
+	defer v2.Stop()
+	go func() {
+		for err := range v2.Errors {
+			log.Println(err)
+		}
+	}()
+	if *archive != "" {
+		files, err := sink.NewFile(*archive, "tweets")
+		if err != nil {
+			return err
+		}
+		err = sink.Pump(v2.Messages, files, func(msg *stream.StreamData, err error) {
+			log.Println("archive:", err)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		for msg := range v2.Messages {
+			if err := printTweet(out, msg); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+	<-v2.Done()
+	stats := v2.Stats()
+	log.Printf("replayed %d messages in %v: %d delivered, %d decode errors, %d dropped",
+		stats.Received, time.Since(start).Round(time.Millisecond), stats.Delivered, stats.DecodeErrors, stats.Dropped)
+	return v2.Err()
+}
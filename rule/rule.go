@@ -0,0 +1,323 @@
+// Package rule provides a builder for filtered stream rule values, e.g.
+//
+//	rule.Keyword("golang").Or(rule.Hashtag("go")).Not(rule.IsRetweet()).Lang("en")
+//
+// produces `(golang OR #go) -is:retweet lang:en`. Build validates the result
+// against the operator and length constraints of the filtered stream.
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// MaxLength is the maximum length of a rule value on the standard product
+// track.
+const MaxLength = 512
+
+var (
+	// ErrEmpty is returned for operators constructed without a value.
+	ErrEmpty = errors.New("rule: empty operator value")
+	// ErrNoStandalone is returned when a rule only consists of operators that
+	// must be used in conjunction with a standalone operator, e.g. is:retweet.
+	ErrNoStandalone = errors.New("rule: rule requires at least one standalone operator")
+)
+
+// LengthError is returned when a rule value exceeds MaxLength.
+type LengthError struct {
+	Value  string
+	Length int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("rule: value is %d characters long, exceeds limit of %d", e.Length, MaxLength)
+}
+
+type kind int
+
+const (
+	kindTerm kind = iota
+	kindAnd
+	kindOr
+	kindNot
+)
+
+// Query is an immutable node of a rule value. Combining queries returns a new
+// Query and leaves the operands untouched.
+type Query struct {
+	kind       kind
+	term       string
+	standalone bool
+	children   []*Query
+	err        error
+}
+
+func term(value string, standalone bool) *Query {
+	return &Query{kind: kindTerm, term: value, standalone: standalone}
+}
+
+func operator(prefix, value string, standalone bool) *Query {
+	if strings.TrimSpace(value) == "" {
+		return &Query{kind: kindTerm, err: fmt.Errorf("%w: %s", ErrEmpty, prefix)}
+	}
+	if needsQuotes(value) {
+		value = quote(value)
+	}
+	return term(prefix+value, standalone)
+}
+
+// needsQuotes reports whether value would not read as a single term of the
+// rule syntax unquoted: it contains whitespace, a colon as of operators,
+// parentheses or quotes, or starts with '-' as negations do.
+func needsQuotes(value string) bool {
+	return strings.ContainsAny(value, " \t\n:()\"") || strings.HasPrefix(value, "-")
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Keyword matches a keyword within the body of a Tweet. Keywords containing
+// whitespace, ':', parentheses or quotes, or starting with '-', are quoted,
+// so matched as an exact phrase.
+func Keyword(keyword string) *Query {
+	return operator("", keyword, true)
+}
+
+// Phrase matches the exact phrase within the body of a Tweet.
+func Phrase(phrase string) *Query {
+	if strings.TrimSpace(phrase) == "" {
+		return operator("", phrase, true)
+	}
+	return term(quote(phrase), true)
+}
+
+// Hashtag matches Tweets containing the hashtag, with or without the leading
+// '#'.
+func Hashtag(tag string) *Query {
+	return operator("#", strings.TrimPrefix(tag, "#"), true)
+}
+
+// Cashtag matches Tweets containing the cashtag, with or without the leading
+// '$'.
+func Cashtag(tag string) *Query {
+	return operator("$", strings.TrimPrefix(tag, "$"), true)
+}
+
+// Mention matches Tweets mentioning the username, with or without the
+// leading '@'.
+func Mention(username string) *Query {
+	return operator("@", strings.TrimPrefix(username, "@"), true)
+}
+
+// From matches Tweets sent by the user, given as username or user ID.
+func From(user string) *Query {
+	return operator("from:", strings.TrimPrefix(user, "@"), true)
+}
+
+// To matches Tweets in reply to the user, given as username or user ID.
+func To(user string) *Query {
+	return operator("to:", strings.TrimPrefix(user, "@"), true)
+}
+
+// RetweetsOf matches Retweets of the user, given as username or user ID.
+func RetweetsOf(user string) *Query {
+	return operator("retweets_of:", strings.TrimPrefix(user, "@"), true)
+}
+
+// URL matches Tweets containing a URL matching the value.
+func URL(url string) *Query {
+	if strings.TrimSpace(url) == "" {
+		return operator("url:", url, true)
+	}
+	return term("url:"+quote(url), true)
+}
+
+// ConversationID matches Tweets of the conversation.
+func ConversationID(id string) *Query {
+	return operator("conversation_id:", id, true)
+}
+
+// IsRetweet matches Retweets. It must be combined with a standalone operator.
+func IsRetweet() *Query { return term("is:retweet", false) }
+
+// IsReply matches replies. It must be combined with a standalone operator.
+func IsReply() *Query { return term("is:reply", false) }
+
+// IsQuote matches Quote Tweets. It must be combined with a standalone
+// operator.
+func IsQuote() *Query { return term("is:quote", false) }
+
+// IsVerified matches Tweets of verified accounts. It must be combined with a
+// standalone operator.
+func IsVerified() *Query { return term("is:verified", false) }
+
+// HasLinks matches Tweets containing links. It must be combined with a
+// standalone operator.
+func HasLinks() *Query { return term("has:links", false) }
+
+// HasMedia matches Tweets containing media. It must be combined with a
+// standalone operator.
+func HasMedia() *Query { return term("has:media", false) }
+
+// HasImages matches Tweets containing images. It must be combined with a
+// standalone operator.
+func HasImages() *Query { return term("has:images", false) }
+
+// HasVideos matches Tweets containing videos. It must be combined with a
+// standalone operator.
+func HasVideos() *Query { return term("has:videos", false) }
+
+// HasHashtags matches Tweets containing hashtags. It must be combined with a
+// standalone operator.
+func HasHashtags() *Query { return term("has:hashtags", false) }
+
+// HasMentions matches Tweets containing mentions. It must be combined with a
+// standalone operator.
+func HasMentions() *Query { return term("has:mentions", false) }
+
+// Lang matches Tweets classified in the BCP 47 language. It must be combined
+// with a standalone operator.
+func Lang(code string) *Query {
+	return operator("lang:", code, false)
+}
+
+func combine(k kind, operands ...*Query) *Query {
+	q := &Query{kind: k}
+	for _, operand := range operands {
+		if operand == nil {
+			continue
+		}
+		// flatten nested nodes of the same kind, e.g. (a OR b) OR c
+		if operand.kind == k && k != kindNot {
+			q.children = append(q.children, operand.children...)
+		} else {
+			q.children = append(q.children, operand)
+		}
+	}
+	return q
+}
+
+// And matches Tweets matching the query and all of the operands.
+func (q *Query) And(operands ...*Query) *Query {
+	return combine(kindAnd, append([]*Query{q}, operands...)...)
+}
+
+// Or matches Tweets matching the query or any of the operands.
+func (q *Query) Or(operands ...*Query) *Query {
+	return combine(kindOr, append([]*Query{q}, operands...)...)
+}
+
+// Not matches Tweets matching the query but none of the operands.
+func (q *Query) Not(operands ...*Query) *Query {
+	negated := make([]*Query, 0, len(operands))
+	for _, operand := range operands {
+		if operand == nil {
+			continue
+		}
+		negated = append(negated, &Query{kind: kindNot, children: []*Query{operand}})
+	}
+	return q.And(negated...)
+}
+
+// Lang restricts the query to Tweets classified in the language.
+func (q *Query) Lang(code string) *Query {
+	return q.And(Lang(code))
+}
+
+// String returns the rule value without validating it.
+func (q *Query) String() string {
+	switch q.kind {
+	case kindAnd:
+		parts := make([]string, len(q.children))
+		for i, child := range q.children {
+			// AND binds tighter than OR, so OR groups need parentheses
+			if child.kind == kindOr {
+				parts[i] = "(" + child.String() + ")"
+			} else {
+				parts[i] = child.String()
+			}
+		}
+		return strings.Join(parts, " ")
+	case kindOr:
+		parts := make([]string, len(q.children))
+		for i, child := range q.children {
+			parts[i] = child.String()
+		}
+		return strings.Join(parts, " OR ")
+	case kindNot:
+		child := q.children[0]
+		if child.kind == kindTerm {
+			return "-" + child.String()
+		}
+		return "-(" + child.String() + ")"
+	default:
+		return q.term
+	}
+}
+
+// Build validates the query and returns the rule value.
+func (q *Query) Build() (string, error) {
+	if err := q.validate(); err != nil {
+		return "", err
+	}
+	if !q.hasStandalone() {
+		return "", ErrNoStandalone
+	}
+	value := q.String()
+	if n := utf8.RuneCountInString(value); n > MaxLength {
+		return "", &LengthError{Value: value, Length: n}
+	}
+	return value, nil
+}
+
+// Rule builds the query into a stream rule with the given tag.
+func (q *Query) Rule(tag string) (*stream.Rule, error) {
+	value, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &stream.Rule{Value: value, Tag: tag}, nil
+}
+
+func (q *Query) validate() error {
+	if q.err != nil {
+		return q.err
+	}
+	for _, child := range q.children {
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasStandalone reports whether the query can match on its own: a term must
+// be standalone, an AND needs one standalone operand and an OR needs all of
+// its operands to be standalone. Negations never count as standalone.
+func (q *Query) hasStandalone() bool {
+	switch q.kind {
+	case kindAnd:
+		for _, child := range q.children {
+			if child.hasStandalone() {
+				return true
+			}
+		}
+		return false
+	case kindOr:
+		for _, child := range q.children {
+			if !child.hasStandalone() {
+				return false
+			}
+		}
+		return len(q.children) > 0
+	case kindNot:
+		return false
+	default:
+		return q.standalone
+	}
+}
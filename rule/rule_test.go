@@ -0,0 +1,43 @@
+package rule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/rule"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   *rule.Query
+		want    string
+		wantErr error
+	}{
+		{"package example", rule.Keyword("golang").Or(rule.Hashtag("go")).Not(rule.IsRetweet()).Lang("en"), "(golang OR #go) -is:retweet lang:en", nil},
+		{"nil Not operand", rule.Keyword("golang").Not(nil), "golang", nil},
+		{"nil among Not operands", rule.Keyword("golang").Not(nil, rule.IsReply(), nil), "golang -is:reply", nil},
+		{"negated group", rule.Keyword("golang").Not(rule.Keyword("rust").Or(rule.Keyword("zig"))), "golang -(rust OR zig)", nil},
+		{"keyword with whitespace", rule.Keyword("go lang"), `"go lang"`, nil},
+		{"keyword with colon", rule.Keyword("is:retweet"), `"is:retweet"`, nil},
+		{"keyword with parentheses", rule.Keyword("(go)"), `"(go)"`, nil},
+		{"keyword with quotes", rule.Keyword(`say"go`), `"say\"go"`, nil},
+		{"keyword with leading dash", rule.Keyword("-go"), `"-go"`, nil},
+		{"keyword with inner dash", rule.Keyword("go-lang"), "go-lang", nil},
+		{"mention with colon", rule.Mention("a:b"), `@"a:b"`, nil},
+		{"url", rule.URL("https://go.dev"), `url:"https://go.dev"`, nil},
+		{"empty keyword", rule.Keyword(" "), "", rule.ErrEmpty},
+		{"no standalone operator", rule.IsRetweet().And(rule.Lang("en")), "", rule.ErrNoStandalone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.Build()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
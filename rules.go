@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// ruleColumns are the table columns of rules.
+var ruleColumns = []column{{"id", 19}, {"tag", 16}, {"value", 0}}
+
+// runRules implements the rules command, e.g.
+//
+//	twstream rules list --output table
+//	twstream rules add --tag cats "cat has:images"
+//	twstream rules delete 1788000000000000000
+func runRules(ctx context.Context, client *http.Client, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: twstream rules list|add|delete [flags] [args]")
+		return errUsage
+	}
+	action, args := args[0], args[1:]
+	flags := flag.NewFlagSet("rules "+action, flag.ContinueOnError)
+	output := flags.String("output", "table", "output format: "+strings.Join(outputFormats, ", "))
+	var tag, access *string
+	var dryRun *bool
+	switch action {
+	case "list":
+	case "add":
+		tag = flags.String("tag", "", "tag of the added rules")
+		dryRun = flags.Bool("dry-run", false, "validate the rules without adding them")
+		access = flags.String("access", "", "check the rules against the rule quota of the access level of the app before adding them")
+	case "delete":
+		dryRun = flags.Bool("dry-run", false, "validate the deletion without deleting")
+	default:
+		fmt.Fprintf(os.Stderr, "twstream: unknown rules command %q, want list, add or delete\n", action)
+		return errUsage
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if action != "list" && flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "twstream: rules %s needs at least one rule\n", action)
+		return errUsage
+	}
+	out, err := newPrinter(*output, os.Stdout, ruleColumns...)
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	var opts []stream.Option
+	if access != nil && *access != "" {
+		quota, err := stream.RuleQuotaOf(*access)
+		if err != nil {
+			return fmt.Errorf("--access: %w", err)
+		}
+		opts = append(opts, stream.WithRuleQuota(quota))
+	}
+	srv, err := newService(ctx, client, opts...)
+	if err != nil {
+		return err
+	}
+	var rules []*stream.Rule
+	switch action {
+	case "list":
+		rules, err = srv.GetRules(ctx)
+	case "add":
+		var add []*stream.Rule
+		for _, value := range flags.Args() {
+			add = append(add, &stream.Rule{Value: value, Tag: *tag})
+		}
+		var resp *stream.RulesResponse
+		resp, err = srv.AddRules(ctx, add, &stream.RulesParams{DryRun: *dryRun})
+		if resp != nil {
+			rules = resp.Data
+		}
+	case "delete":
+		var resp *stream.RulesResponse
+		resp, err = srv.DeleteRules(ctx, flags.Args(), &stream.RulesParams{DryRun: *dryRun})
+		if resp != nil && resp.Meta != nil && resp.Meta.Summary != nil {
+			fmt.Fprintf(os.Stderr, "deleted %d, not deleted %d\n", resp.Meta.Summary.Deleted, resp.Meta.Summary.NotDeleted)
+		}
+		return err
+	}
+	var ruleErrs stream.RuleErrors
+	if err != nil && !errors.As(err, &ruleErrs) {
+		return err
+	}
+	values := make([]any, len(rules))
+	rows := make([][]string, len(rules))
+	for i, rule := range rules {
+		values[i] = rule
+		rows[i] = []string{rule.ID, rule.Tag, rule.Value}
+	}
+	if err := out.list(values, rows); err != nil {
+		return err
+	}
+	// the rules which were added are printed before those refused
+	return err
+}
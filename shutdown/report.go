@@ -0,0 +1,78 @@
+// Package shutdown reports what a consumer received, delivered, dropped and
+// left buffered when it exits, so operators can verify whether a restart
+// lost anything.
+package shutdown
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Report is the machine-readable summary of a run.
+type Report struct {
+	Time      time.Time `json:"time"`
+	Received  uint64    `json:"received"`
+	Delivered uint64    `json:"delivered"`
+	Dropped   uint64    `json:"dropped"`
+	Sinks     []*Sink   `json:"sinks,omitempty"`
+	// LastCheckpoint is the ID of the last delivered Tweet.
+	LastCheckpoint string `json:"last_checkpoint,omitempty"`
+}
+
+// Sink is the report of a sink.
+type Sink struct {
+	Name     string `json:"name"`
+	Acked    uint64 `json:"acked"`
+	Failed   uint64 `json:"failed"`
+	Buffered int    `json:"buffered"`
+}
+
+// New returns the report of the stream, which may be nil, and the sinks.
+// Create it after the stream stopped and the sinks were closed, so the
+// counts are final.
+func New(s *stream.Stream, sinks ...*sink.Counting) *Report {
+	r := &Report{Time: time.Now()}
+	if s != nil {
+		stats := s.Stats()
+		r.Received = stats.Received
+		r.Delivered = stats.Delivered
+		r.Dropped = stats.Dropped
+		r.LastCheckpoint = stats.LastTweetID
+	}
+	for _, c := range sinks {
+		r.Sinks = append(r.Sinks, &Sink{
+			Name:     c.Name,
+			Acked:    c.Acked(),
+			Failed:   c.Failed(),
+			Buffered: c.Buffered(),
+		})
+	}
+	return r
+}
+
+// Log writes the report as a single JSON line to the logger, or the
+// standard logger if l is nil.
+func (r *Report) Log(l *log.Logger) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if l == nil {
+		l = log.Default()
+	}
+	l.Printf("shutdown report: %s", data)
+}
+
+// WriteFile writes the report as JSON to the file at path.
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,107 @@
+package sink_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/codegen"
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// samples are envelopes as Twitter sends them, from a bare Tweet to one
+// with every field, includes and rules, and an in-stream error.
+var samples = []string{
+	`{"data":{"id":"1","text":"hello","edit_history_tweet_ids":["1"]},"matching_rules":[{"id":"10","tag":"greetings"}]}`,
+	`{
+		"data": {
+			"id": "2", "text": "@bob look https://t.co/x #go $TWTR", "created_at": "2023-05-01T12:00:00.000Z",
+			"author_id": "100", "conversation_id": "2", "in_reply_to_user_id": "101", "lang": "en",
+			"source": "Twitter Web App", "reply_settings": "everyone", "possibly_sensitive": false,
+			"entities": {
+				"hashtags": [{"start": 28, "end": 31, "tag": "go"}],
+				"cashtags": [{"start": 32, "end": 37, "tag": "TWTR"}],
+				"mentions": [{"start": 0, "end": 4, "username": "bob", "id": "101"}],
+				"urls": [{"start": 10, "end": 27, "url": "https://t.co/x", "expanded_url": "https://example.com", "display_url": "example.com", "status": 200}]
+			},
+			"public_metrics": {"retweet_count": 1, "reply_count": 2, "like_count": 3, "quote_count": 4},
+			"referenced_tweets": [{"type": "replied_to", "id": "1"}],
+			"attachments": {"media_keys": ["3_1"], "poll_ids": ["p1"]},
+			"geo": {"place_id": "pl1", "coordinates": {"type": "Point", "coordinates": [-122.4, 37.8]}},
+			"context_annotations": [{"domain": {"id": "1", "name": "Tech"}, "entity": {"id": "2", "name": "Go"}}],
+			"edit_history_tweet_ids": ["2"],
+			"edit_controls": {"edits_remaining": 5, "is_edit_eligible": true, "editable_until": "2023-05-01T12:30:00.000Z"},
+			"note_tweet": {"text": "a longer text"}
+		},
+		"includes": {
+			"users": [{"id": "100", "name": "Alice", "username": "alice", "created_at": "2010-01-01T00:00:00.000Z", "verified": false, "public_metrics": {"followers_count": 1, "following_count": 2, "tweet_count": 3, "listed_count": 4}}],
+			"media": [{"media_key": "3_1", "type": "photo", "url": "https://pbs.twimg.com/x.jpg", "width": 10, "height": 20}],
+			"places": [{"id": "pl1", "full_name": "San Francisco, CA", "geo": {"type": "Feature", "bbox": [-122.5, 37.7, -122.3, 37.9]}}],
+			"polls": [{"id": "p1", "options": [{"position": 1, "label": "yes", "votes": 3}], "voting_status": "open"}],
+			"tweets": [{"id": "1", "text": "hello", "edit_history_tweet_ids": ["1"]}]
+		},
+		"matching_rules": [{"id": "10", "tag": "greetings"}, {"id": "11"}]
+	}`,
+	`{"errors":[{"title":"operational-disconnect","detail":"This stream has been disconnected for operational reasons.","type":"https://api.twitter.com/2/problems/operational-disconnect"}]}`,
+}
+
+// TestGeneratedContract checks that the committed JSON Schema, TypeScript
+// and Python types of the records sinks write are exactly what schemagen
+// generates from the Go types, i.e. that go generate ./sink was run.
+func TestGeneratedContract(t *testing.T) {
+	schema := sink.EnvelopeSchema()
+	tests := []struct {
+		file     string
+		generate func(io.Writer, *sink.Schema) error
+	}{
+		{"envelope.schema.json", func(w io.Writer, schema *sink.Schema) error {
+			data, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(data, '\n'))
+			return err
+		}},
+		{"envelope.ts", codegen.TypeScript},
+		{"envelope.py", codegen.Python},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			var want bytes.Buffer
+			if err := tt.generate(&want, schema); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want.Bytes()) {
+				t.Errorf("%s differs from the generated file, run go generate ./sink", tt.file)
+			}
+		})
+	}
+}
+
+// TestSampleEnvelopes checks that envelopes as Twitter sends them pass the
+// Validating sink.
+func TestSampleEnvelopes(t *testing.T) {
+	validating := sink.NewValidating(discard{}, 1)
+	for i, sample := range samples {
+		msg := &stream.StreamData{}
+		if err := json.Unmarshal([]byte(sample), msg); err != nil {
+			t.Fatalf("sample %d: %v", i, err)
+		}
+		if err := validating.Write(msg); err != nil {
+			t.Errorf("sample %d: %v", i, err)
+		}
+	}
+}
+
+// discard is a sink dropping the records.
+type discard struct{}
+
+func (discard) Write(*stream.StreamData) error { return nil }
+func (discard) Close() error                   { return nil }
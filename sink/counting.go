@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"sync/atomic"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Buffered is implemented by sinks holding messages which were accepted but
+// not yet delivered, such as Scheduled.
+type Buffered interface {
+	Buffered() int
+}
+
+// Counting counts the messages a sink acknowledged and failed to write.
+type Counting struct {
+	// Name identifies the sink in reports.
+	Name   string
+	sink   Sink
+	acked  uint64
+	failed uint64
+}
+
+// NewCounting returns a Counting sink writing to s.
+func NewCounting(name string, s Sink) *Counting {
+	return &Counting{Name: name, sink: s}
+}
+
+func (c *Counting) Write(msg *stream.StreamData) error {
+	if err := c.sink.Write(msg); err != nil {
+		atomic.AddUint64(&c.failed, 1)
+		return err
+	}
+	atomic.AddUint64(&c.acked, 1)
+	return nil
+}
+
+func (c *Counting) Close() error {
+	return c.sink.Close()
+}
+
+// Acked returns the number of messages the sink wrote successfully.
+func (c *Counting) Acked() uint64 {
+	return atomic.LoadUint64(&c.acked)
+}
+
+// Failed returns the number of messages the sink failed to write.
+func (c *Counting) Failed() uint64 {
+	return atomic.LoadUint64(&c.failed)
+}
+
+// Buffered returns the number of messages buffered by the sink, or zero if
+// it does not buffer.
+func (c *Counting) Buffered() int {
+	if b, ok := c.sink.(Buffered); ok {
+		return b.Buffered()
+	}
+	return 0
+}
@@ -0,0 +1,309 @@
+# Code generated by codegen. DO NOT EDIT.
+from typing import Any, Dict, List, Optional, TypedDict
+
+from typing_extensions import NotRequired
+
+
+Attachments = TypedDict("Attachments", {
+    "media_keys": NotRequired[List[str]],
+    "poll_ids": NotRequired[List[str]],
+})
+
+
+ContextEntity = TypedDict("ContextEntity", {
+    "description": NotRequired[str],
+    "id": str,
+    "name": str,
+})
+
+
+ContextAnnotation = TypedDict("ContextAnnotation", {
+    "domain": Optional["ContextEntity"],
+    "entity": Optional["ContextEntity"],
+})
+
+
+EditControls = TypedDict("EditControls", {
+    "editable_until": str,
+    "edits_remaining": int,
+    "is_edit_eligible": bool,
+})
+
+
+AnnotationEntity = TypedDict("AnnotationEntity", {
+    "end": int,
+    "normalized_text": str,
+    "probability": float,
+    "start": int,
+    "type": str,
+})
+
+
+TagEntity = TypedDict("TagEntity", {
+    "end": int,
+    "start": int,
+    "tag": str,
+})
+
+
+MentionEntity = TypedDict("MentionEntity", {
+    "end": int,
+    "id": NotRequired[str],
+    "start": int,
+    "username": str,
+})
+
+
+URLImage = TypedDict("URLImage", {
+    "height": int,
+    "url": str,
+    "width": int,
+})
+
+
+URLEntity = TypedDict("URLEntity", {
+    "description": NotRequired[str],
+    "display_url": NotRequired[str],
+    "end": int,
+    "expanded_url": NotRequired[str],
+    "images": NotRequired[List["URLImage"]],
+    "media_key": NotRequired[str],
+    "start": int,
+    "status": NotRequired[int],
+    "title": NotRequired[str],
+    "unwound_url": NotRequired[str],
+    "url": str,
+})
+
+
+Entities = TypedDict("Entities", {
+    "annotations": NotRequired[List["AnnotationEntity"]],
+    "cashtags": NotRequired[List["TagEntity"]],
+    "hashtags": NotRequired[List["TagEntity"]],
+    "mentions": NotRequired[List["MentionEntity"]],
+    "urls": NotRequired[List["URLEntity"]],
+})
+
+
+Coordinates = TypedDict("Coordinates", {
+    "coordinates": Optional[List[float]],
+    "type": str,
+})
+
+
+Geo = TypedDict("Geo", {
+    "coordinates": NotRequired["Coordinates"],
+    "place_id": NotRequired[str],
+})
+
+
+NonPublicMetrics = TypedDict("NonPublicMetrics", {
+    "impression_count": int,
+    "url_link_clicks": int,
+    "user_profile_clicks": int,
+})
+
+
+NoteTweet = TypedDict("NoteTweet", {
+    "entities": NotRequired["Entities"],
+    "text": str,
+})
+
+
+EngagementMetrics = TypedDict("EngagementMetrics", {
+    "impression_count": int,
+    "like_count": int,
+    "reply_count": int,
+    "retweet_count": int,
+    "url_link_clicks": int,
+    "user_profile_clicks": int,
+})
+
+
+PublicMetrics = TypedDict("PublicMetrics", {
+    "bookmark_count": int,
+    "impression_count": int,
+    "like_count": int,
+    "quote_count": int,
+    "reply_count": int,
+    "retweet_count": int,
+})
+
+
+ReferencedTweet = TypedDict("ReferencedTweet", {
+    "id": str,
+    "type": str,
+})
+
+
+Withheld = TypedDict("Withheld", {
+    "copyright": bool,
+    "country_codes": Optional[List[str]],
+    "scope": NotRequired[str],
+})
+
+
+Tweet = TypedDict("Tweet", {
+    "attachments": NotRequired["Attachments"],
+    "author_id": NotRequired[str],
+    "context_annotations": NotRequired[List["ContextAnnotation"]],
+    "conversation_id": NotRequired[str],
+    "created_at": NotRequired[str],
+    "edit_controls": NotRequired["EditControls"],
+    "edit_history_tweet_ids": NotRequired[List[str]],
+    "entities": NotRequired["Entities"],
+    "geo": NotRequired["Geo"],
+    "id": str,
+    "in_reply_to_user_id": NotRequired[str],
+    "lang": NotRequired[str],
+    "non_public_metrics": NotRequired["NonPublicMetrics"],
+    "note_tweet": NotRequired["NoteTweet"],
+    "organic_metrics": NotRequired["EngagementMetrics"],
+    "possibly_sensitive": NotRequired[bool],
+    "promoted_metrics": NotRequired["EngagementMetrics"],
+    "public_metrics": NotRequired["PublicMetrics"],
+    "referenced_tweets": NotRequired[List["ReferencedTweet"]],
+    "reply_settings": NotRequired[str],
+    "source": NotRequired[str],
+    "text": str,
+    "withheld": NotRequired["Withheld"],
+})
+
+
+APIErrorDetail = TypedDict("APIErrorDetail", {
+    "detail": NotRequired[str],
+    "disconnect_type": NotRequired[str],
+    "message": NotRequired[str],
+    "parameter": NotRequired[str],
+    "parameters": NotRequired[Dict[str, List[str]]],
+    "resource_id": NotRequired[str],
+    "resource_type": NotRequired[str],
+    "title": NotRequired[str],
+    "type": NotRequired[str],
+    "value": NotRequired[str],
+})
+
+
+MediaPublicMetrics = TypedDict("MediaPublicMetrics", {
+    "view_count": int,
+})
+
+
+MediaVariant = TypedDict("MediaVariant", {
+    "bit_rate": NotRequired[int],
+    "content_type": str,
+    "url": str,
+})
+
+
+Media = TypedDict("Media", {
+    "alt_text": NotRequired[str],
+    "duration_ms": NotRequired[int],
+    "height": NotRequired[int],
+    "media_key": str,
+    "non_public_metrics": NotRequired[Dict[str, int]],
+    "organic_metrics": NotRequired[Dict[str, int]],
+    "preview_image_url": NotRequired[str],
+    "promoted_metrics": NotRequired[Dict[str, int]],
+    "public_metrics": NotRequired["MediaPublicMetrics"],
+    "type": str,
+    "url": NotRequired[str],
+    "variants": NotRequired[List["MediaVariant"]],
+    "width": NotRequired[int],
+})
+
+
+PlaceGeo = TypedDict("PlaceGeo", {
+    "bbox": Optional[List[float]],
+    "properties": NotRequired[Dict[str, Any]],
+    "type": str,
+})
+
+
+Place = TypedDict("Place", {
+    "contained_within": NotRequired[List[str]],
+    "country": NotRequired[str],
+    "country_code": NotRequired[str],
+    "full_name": str,
+    "geo": NotRequired["PlaceGeo"],
+    "id": str,
+    "name": NotRequired[str],
+    "place_type": NotRequired[str],
+})
+
+
+PollOption = TypedDict("PollOption", {
+    "label": str,
+    "position": int,
+    "votes": int,
+})
+
+
+Poll = TypedDict("Poll", {
+    "duration_minutes": NotRequired[int],
+    "end_datetime": NotRequired[str],
+    "id": str,
+    "options": Optional[List["PollOption"]],
+    "voting_status": NotRequired[str],
+})
+
+
+UserEntities = TypedDict("UserEntities", {
+    "description": NotRequired["Entities"],
+    "url": NotRequired["Entities"],
+})
+
+
+UserPublicMetrics = TypedDict("UserPublicMetrics", {
+    "followers_count": int,
+    "following_count": int,
+    "like_count": int,
+    "listed_count": int,
+    "tweet_count": int,
+})
+
+
+User = TypedDict("User", {
+    "created_at": NotRequired[str],
+    "description": NotRequired[str],
+    "entities": NotRequired["UserEntities"],
+    "id": str,
+    "location": NotRequired[str],
+    "name": str,
+    "pinned_tweet_id": NotRequired[str],
+    "profile_image_url": NotRequired[str],
+    "protected": NotRequired[bool],
+    "public_metrics": NotRequired["UserPublicMetrics"],
+    "url": NotRequired[str],
+    "username": str,
+    "verified": NotRequired[bool],
+    "verified_type": NotRequired[str],
+    "withheld": NotRequired["Withheld"],
+})
+
+
+Includes = TypedDict("Includes", {
+    "media": NotRequired[List["Media"]],
+    "places": NotRequired[List["Place"]],
+    "polls": NotRequired[List["Poll"]],
+    "tweets": NotRequired[List["Tweet"]],
+    "users": NotRequired[List["User"]],
+})
+
+
+MatchingRule = TypedDict("MatchingRule", {
+    "id": NotRequired[str],
+    "labels": NotRequired[Dict[str, str]],
+    "original_tag": NotRequired[str],
+    "tag": NotRequired[str],
+})
+
+
+StreamData = TypedDict("StreamData", {
+    "annotations": NotRequired[Dict[str, Any]],
+    "data": NotRequired["Tweet"],
+    "errors": NotRequired[List["APIErrorDetail"]],
+    "includes": NotRequired["Includes"],
+    "matching_rules": NotRequired[List["MatchingRule"]],
+    "recovered": NotRequired[bool],
+})
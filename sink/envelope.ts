@@ -0,0 +1,273 @@
+// Code generated by codegen. DO NOT EDIT.
+
+export interface Attachments {
+  "media_keys"?: string[];
+  "poll_ids"?: string[];
+}
+
+export interface ContextEntity {
+  "description"?: string;
+  "id": string;
+  "name": string;
+}
+
+export interface ContextAnnotation {
+  "domain": ContextEntity | null;
+  "entity": ContextEntity | null;
+}
+
+export interface EditControls {
+  "editable_until": string;
+  "edits_remaining": number;
+  "is_edit_eligible": boolean;
+}
+
+export interface AnnotationEntity {
+  "end": number;
+  "normalized_text": string;
+  "probability": number;
+  "start": number;
+  "type": string;
+}
+
+export interface TagEntity {
+  "end": number;
+  "start": number;
+  "tag": string;
+}
+
+export interface MentionEntity {
+  "end": number;
+  "id"?: string;
+  "start": number;
+  "username": string;
+}
+
+export interface URLImage {
+  "height": number;
+  "url": string;
+  "width": number;
+}
+
+export interface URLEntity {
+  "description"?: string;
+  "display_url"?: string;
+  "end": number;
+  "expanded_url"?: string;
+  "images"?: URLImage[];
+  "media_key"?: string;
+  "start": number;
+  "status"?: number;
+  "title"?: string;
+  "unwound_url"?: string;
+  "url": string;
+}
+
+export interface Entities {
+  "annotations"?: AnnotationEntity[];
+  "cashtags"?: TagEntity[];
+  "hashtags"?: TagEntity[];
+  "mentions"?: MentionEntity[];
+  "urls"?: URLEntity[];
+}
+
+export interface Coordinates {
+  "coordinates": number[] | null;
+  "type": string;
+}
+
+export interface Geo {
+  "coordinates"?: Coordinates;
+  "place_id"?: string;
+}
+
+export interface NonPublicMetrics {
+  "impression_count": number;
+  "url_link_clicks": number;
+  "user_profile_clicks": number;
+}
+
+export interface NoteTweet {
+  "entities"?: Entities;
+  "text": string;
+}
+
+export interface EngagementMetrics {
+  "impression_count": number;
+  "like_count": number;
+  "reply_count": number;
+  "retweet_count": number;
+  "url_link_clicks": number;
+  "user_profile_clicks": number;
+}
+
+export interface PublicMetrics {
+  "bookmark_count": number;
+  "impression_count": number;
+  "like_count": number;
+  "quote_count": number;
+  "reply_count": number;
+  "retweet_count": number;
+}
+
+export interface ReferencedTweet {
+  "id": string;
+  "type": string;
+}
+
+export interface Withheld {
+  "copyright": boolean;
+  "country_codes": string[] | null;
+  "scope"?: string;
+}
+
+export interface Tweet {
+  "attachments"?: Attachments;
+  "author_id"?: string;
+  "context_annotations"?: ContextAnnotation[];
+  "conversation_id"?: string;
+  "created_at"?: string;
+  "edit_controls"?: EditControls;
+  "edit_history_tweet_ids"?: string[];
+  "entities"?: Entities;
+  "geo"?: Geo;
+  "id": string;
+  "in_reply_to_user_id"?: string;
+  "lang"?: string;
+  "non_public_metrics"?: NonPublicMetrics;
+  "note_tweet"?: NoteTweet;
+  "organic_metrics"?: EngagementMetrics;
+  "possibly_sensitive"?: boolean;
+  "promoted_metrics"?: EngagementMetrics;
+  "public_metrics"?: PublicMetrics;
+  "referenced_tweets"?: ReferencedTweet[];
+  "reply_settings"?: string;
+  "source"?: string;
+  "text": string;
+  "withheld"?: Withheld;
+}
+
+export interface APIErrorDetail {
+  "detail"?: string;
+  "disconnect_type"?: string;
+  "message"?: string;
+  "parameter"?: string;
+  "parameters"?: Record<string, string[]>;
+  "resource_id"?: string;
+  "resource_type"?: string;
+  "title"?: string;
+  "type"?: string;
+  "value"?: string;
+}
+
+export interface MediaPublicMetrics {
+  "view_count": number;
+}
+
+export interface MediaVariant {
+  "bit_rate"?: number;
+  "content_type": string;
+  "url": string;
+}
+
+export interface Media {
+  "alt_text"?: string;
+  "duration_ms"?: number;
+  "height"?: number;
+  "media_key": string;
+  "non_public_metrics"?: Record<string, number>;
+  "organic_metrics"?: Record<string, number>;
+  "preview_image_url"?: string;
+  "promoted_metrics"?: Record<string, number>;
+  "public_metrics"?: MediaPublicMetrics;
+  "type": string;
+  "url"?: string;
+  "variants"?: MediaVariant[];
+  "width"?: number;
+}
+
+export interface PlaceGeo {
+  "bbox": number[] | null;
+  "properties"?: Record<string, unknown>;
+  "type": string;
+}
+
+export interface Place {
+  "contained_within"?: string[];
+  "country"?: string;
+  "country_code"?: string;
+  "full_name": string;
+  "geo"?: PlaceGeo;
+  "id": string;
+  "name"?: string;
+  "place_type"?: string;
+}
+
+export interface PollOption {
+  "label": string;
+  "position": number;
+  "votes": number;
+}
+
+export interface Poll {
+  "duration_minutes"?: number;
+  "end_datetime"?: string;
+  "id": string;
+  "options": PollOption[] | null;
+  "voting_status"?: string;
+}
+
+export interface UserEntities {
+  "description"?: Entities;
+  "url"?: Entities;
+}
+
+export interface UserPublicMetrics {
+  "followers_count": number;
+  "following_count": number;
+  "like_count": number;
+  "listed_count": number;
+  "tweet_count": number;
+}
+
+export interface User {
+  "created_at"?: string;
+  "description"?: string;
+  "entities"?: UserEntities;
+  "id": string;
+  "location"?: string;
+  "name": string;
+  "pinned_tweet_id"?: string;
+  "profile_image_url"?: string;
+  "protected"?: boolean;
+  "public_metrics"?: UserPublicMetrics;
+  "url"?: string;
+  "username": string;
+  "verified"?: boolean;
+  "verified_type"?: string;
+  "withheld"?: Withheld;
+}
+
+export interface Includes {
+  "media"?: Media[];
+  "places"?: Place[];
+  "polls"?: Poll[];
+  "tweets"?: Tweet[];
+  "users"?: User[];
+}
+
+export interface MatchingRule {
+  "id"?: string;
+  "labels"?: Record<string, string>;
+  "original_tag"?: string;
+  "tag"?: string;
+}
+
+export interface StreamData {
+  "annotations"?: Record<string, unknown>;
+  "data"?: Tweet;
+  "errors"?: APIErrorDetail[];
+  "includes"?: Includes;
+  "matching_rules"?: MatchingRule[];
+  "recovered"?: boolean;
+}
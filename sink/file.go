@@ -0,0 +1,139 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// fileTimeFormat is the layout of the creation time in file names.
+const fileTimeFormat = "20060102T150405.000"
+
+// File appends messages as newline delimited JSON to files in a directory,
+// rotating to a new file once the current one reaches MaxBytes or MaxAge.
+// Files are named after the prefix and their creation time, e.g.
+// tweets-20240501T120000.000.ndjson, with a counter appended to the names
+// of files created within the same millisecond.
+type File struct {
+	dir    string
+	prefix string
+	// MaxBytes rotates files once they reach the size. Zero disables size
+	// based rotation.
+	MaxBytes int64
+	// MaxAge rotates files once they are older than the age, checked on
+	// write. Zero disables time based rotation.
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	size    int64
+	created time.Time
+}
+
+// NewFile returns a File sink writing to files in dir, which is created if
+// it does not exist.
+func NewFile(dir, prefix string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &File{dir: dir, prefix: prefix}, nil
+}
+
+func (f *File) Write(msg *stream.StreamData) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if f.file != nil && f.due(now, len(data)) {
+		if err := f.closeFile(); err != nil {
+			return err
+		}
+	}
+	if f.file == nil {
+		if err := f.open(now); err != nil {
+			return err
+		}
+	}
+	n, err := f.w.Write(data)
+	f.size += int64(n)
+	return err
+}
+
+// due reports whether the current file must be rotated before writing n
+// bytes at now. A file always takes at least one message.
+func (f *File) due(now time.Time, n int) bool {
+	if f.MaxBytes > 0 && f.size > 0 && f.size+int64(n) > f.MaxBytes {
+		return true
+	}
+	return f.MaxAge > 0 && now.Sub(f.created) >= f.MaxAge
+}
+
+func (f *File) open(now time.Time) error {
+	base := fmt.Sprintf("%s-%s", f.prefix, now.UTC().Format(fileTimeFormat))
+	name := base + ".ndjson"
+	for i := 1; ; i++ {
+		file, err := os.OpenFile(filepath.Join(f.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			// rotated within the same millisecond
+			name = fmt.Sprintf("%s-%d.ndjson", base, i)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		f.file, f.w, f.size, f.created = file, bufio.NewWriter(file), 0, now
+		return nil
+	}
+}
+
+// Flush writes buffered messages to the current file.
+func (f *File) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.w == nil {
+		return nil
+	}
+	return f.w.Flush()
+}
+
+// Path returns the path of the current file, or "" if none is open.
+func (f *File) Path() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return ""
+	}
+	return f.file.Name()
+}
+
+func (f *File) closeFile() error {
+	err := f.w.Flush()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file, f.w = nil, nil
+	return err
+}
+
+// Close flushes and closes the current file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.closeFile()
+}
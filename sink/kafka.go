@@ -0,0 +1,74 @@
+package sink
+
+import (
+	"encoding/json"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+)
+
+// KafkaProducer produces records to Kafka, e.g. an adapter to a
+// sarama.SyncProducer or a kafka-go Writer. Produce returns once the record
+// is acknowledged.
+type KafkaProducer interface {
+	Produce(topic string, key, value []byte) error
+	Close() error
+}
+
+// Serializer encodes a message into a record.
+type Serializer func(msg *stream.StreamData) ([]byte, error)
+
+// JSONSerializer encodes messages as in the stream, the encoding of sinks by
+// default.
+func JSONSerializer(msg *stream.StreamData) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+// ProtobufSerializer encodes messages as the StreamData message of
+// streampb.proto, more compact than JSON. Annotations are left out.
+func ProtobufSerializer(msg *stream.StreamData) ([]byte, error) {
+	return streampb.MarshalStreamData(msg), nil
+}
+
+// Kafka produces messages to Kafka topics, keyed by Key so the revisions of
+// a Tweet are in order within their partition, and so compacted topics keep
+// one record of Tweets redelivered after a reconnect or replay.
+type Kafka struct {
+	producer KafkaProducer
+	topic    string
+	// Key returns the message keys, stream.DeliveryKey by default.
+	Key stream.KeyFunc
+	// TagTopic, if set, returns the topic of messages matching a rule
+	// tagged tag, or "" for the default topic. Messages are produced once
+	// to each topic of their tags.
+	TagTopic func(tag string) string
+	// Serialize encodes record values, JSONSerializer by default.
+	Serialize Serializer
+}
+
+// NewKafka returns a Kafka sink producing to topic.
+func NewKafka(producer KafkaProducer, topic string) *Kafka {
+	return &Kafka{producer: producer, topic: topic, Key: stream.DeliveryKey, Serialize: JSONSerializer}
+}
+
+func (k *Kafka) Write(msg *stream.StreamData) error {
+	value, err := k.Serialize(msg)
+	if err != nil {
+		return err
+	}
+	var key []byte
+	if id := deliveryKey(k.Key, msg); id != "" {
+		key = []byte(id)
+	}
+	for _, topic := range tagTargets(msg, k.TagTopic, k.topic) {
+		if err := k.producer.Produce(topic, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the producer.
+func (k *Kafka) Close() error {
+	return k.producer.Close()
+}
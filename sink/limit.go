@@ -0,0 +1,202 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// OversizeAction is what a Limited sink does with a record exceeding its
+// size limit.
+type OversizeAction int
+
+const (
+	// OversizeProject writes the record projected down to a smaller field
+	// set, and dead-letters it if it still exceeds the limit.
+	OversizeProject OversizeAction = iota + 1
+	// OversizeStore puts the full record to the ObjectStore under its
+	// stream.DeliveryKey, so edits of a Tweet are kept apart, and writes a
+	// pointer record instead: the Tweet ID and matching rules, with the
+	// location of the record in the PayloadAnnotation. Records without a
+	// Tweet are dead-lettered.
+	OversizeStore
+	// OversizeDeadLetter writes the record to the dead letter sink.
+	OversizeDeadLetter
+)
+
+const (
+	// PayloadAnnotation is the annotation holding the location of the full
+	// record in pointer records.
+	PayloadAnnotation = "payload_url"
+	// DeadLetterAnnotation is the annotation holding the reason a record
+	// was dead-lettered.
+	DeadLetterAnnotation = "dead_letter_reason"
+)
+
+// ObjectStore stores full records which are too large for a sink, such as
+// an S3 or GCS bucket.
+type ObjectStore interface {
+	// Put stores data under key and returns its location.
+	Put(key string, data []byte) (location string, err error)
+}
+
+// DirStore is an ObjectStore keeping records as files in a directory.
+type DirStore string
+
+func (d DirStore) Put(key string, data []byte) (string, error) {
+	path := filepath.Join(string(d), key)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return "file://" + filepath.ToSlash(path), nil
+}
+
+// OversizeError is the reason a record was not written to a sink.
+type OversizeError struct {
+	TweetID string
+	Size    int
+	Limit   int
+	// Err is set if the record could not be stored.
+	Err error
+}
+
+func (e *OversizeError) Error() string {
+	msg := fmt.Sprintf("sink: record of Tweet %s is %d bytes, limit is %d", e.TweetID, e.Size, e.Limit)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *OversizeError) Unwrap() error {
+	return e.Err
+}
+
+// Limited enforces the payload size cap of a sink, such as the 256 KiB of
+// SQS messages, on the JSON encoding of the records written to it.
+type Limited struct {
+	sink   Sink
+	limit  int
+	action OversizeAction
+	// Project projects an oversized record down to a smaller field set for
+	// OversizeProject. If it is nil, ProjectMinimal is used.
+	Project func(msg *stream.StreamData) *stream.StreamData
+	// Store stores the full records of OversizeStore.
+	Store ObjectStore
+	// DeadLetter receives the records which are dead-lettered, annotated
+	// with the DeadLetterAnnotation. If it is nil, Write returns the
+	// OversizeError instead.
+	DeadLetter Sink
+}
+
+// NewLimited returns a Limited sink writing records up to limit bytes to s,
+// and treating larger records with action.
+func NewLimited(s Sink, limit int, action OversizeAction) *Limited {
+	return &Limited{sink: s, limit: limit, action: action}
+}
+
+func (l *Limited) Write(msg *stream.StreamData) error {
+	record, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(record) <= l.limit {
+		return l.sink.Write(msg)
+	}
+	oversize := &OversizeError{Size: len(record), Limit: l.limit}
+	if msg.Tweet != nil {
+		oversize.TweetID = msg.Tweet.ID
+	}
+	switch l.action {
+	case OversizeProject:
+		project := l.Project
+		if project == nil {
+			project = ProjectMinimal
+		}
+		projected := project(msg)
+		if record, err := json.Marshal(projected); err == nil && len(record) <= l.limit {
+			return l.sink.Write(projected)
+		}
+	case OversizeStore:
+		if l.Store == nil {
+			oversize.Err = fmt.Errorf("no object store")
+			break
+		}
+		key := stream.DeliveryKey(msg)
+		if key == "" {
+			oversize.Err = fmt.Errorf("no Tweet to key the record by")
+			break
+		}
+		// the colon of delivery keys is not allowed in file names everywhere
+		location, err := l.Store.Put(strings.ReplaceAll(key, ":", "_")+".json", record)
+		if err != nil {
+			oversize.Err = err
+			break
+		}
+		return l.sink.Write(pointer(msg, location))
+	}
+	return l.deadLetter(msg, oversize)
+}
+
+// deadLetter writes msg annotated with reason to the dead letter sink.
+func (l *Limited) deadLetter(msg *stream.StreamData, reason *OversizeError) error {
+	if l.DeadLetter == nil {
+		return reason
+	}
+	dead := *msg
+	dead.Annotations = make(map[string]any, len(msg.Annotations)+1)
+	for k, v := range msg.Annotations {
+		dead.Annotations[k] = v
+	}
+	dead.Annotations[DeadLetterAnnotation] = reason.Error()
+	return l.DeadLetter.Write(&dead)
+}
+
+func (l *Limited) Close() error {
+	err := l.sink.Close()
+	if l.DeadLetter != nil {
+		if dlErr := l.DeadLetter.Close(); err == nil {
+			err = dlErr
+		}
+	}
+	return err
+}
+
+// ProjectMinimal projects a record down to the ID, text, author, creation
+// time and language of the Tweet and the matching rules, dropping the
+// expansions.
+func ProjectMinimal(msg *stream.StreamData) *stream.StreamData {
+	projected := &stream.StreamData{
+		MatchingRules: msg.MatchingRules,
+		Annotations:   msg.Annotations,
+		Recovered:     msg.Recovered,
+	}
+	if t := msg.Tweet; t != nil {
+		projected.Tweet = &stream.Tweet{
+			ID:        t.ID,
+			Text:      t.Text,
+			CreatedAt: t.CreatedAt,
+			AuthorID:  t.AuthorID,
+			Lang:      t.Lang,
+		}
+	}
+	return projected
+}
+
+// pointer returns the pointer record of msg, whose full record is stored at
+// location.
+func pointer(msg *stream.StreamData, location string) *stream.StreamData {
+	p := &stream.StreamData{
+		MatchingRules: msg.MatchingRules,
+		Annotations:   map[string]any{PayloadAnnotation: location},
+		Recovered:     msg.Recovered,
+	}
+	if msg.Tweet != nil {
+		p.Tweet = &stream.Tweet{ID: msg.Tweet.ID}
+	}
+	return p
+}
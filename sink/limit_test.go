@@ -0,0 +1,114 @@
+package sink_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// recorder is a Sink keeping the records written to it, failing with err
+// if set.
+type recorder struct {
+	mu      sync.Mutex
+	records []*stream.StreamData
+	err     error
+}
+
+func (r *recorder) Write(msg *stream.StreamData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.records = append(r.records, msg)
+	return nil
+}
+
+func (r *recorder) Close() error { return nil }
+
+func (r *recorder) written() []*stream.StreamData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*stream.StreamData(nil), r.records...)
+}
+
+// objects is an ObjectStore in memory.
+type objects map[string][]byte
+
+func (o objects) Put(key string, data []byte) (string, error) {
+	o[key] = data
+	return "mem://" + key, nil
+}
+
+func TestLimitedStore(t *testing.T) {
+	text := strings.Repeat("x", 200)
+	tests := []struct {
+		name           string
+		msg            *stream.StreamData
+		wantKey        string
+		wantDeadLetter bool
+	}{
+		{"small record", &stream.StreamData{Tweet: &stream.Tweet{ID: "1", Text: "hi"}}, "", false},
+		{"original Tweet", &stream.StreamData{Tweet: &stream.Tweet{ID: "1", Text: text, EditHistoryTweetIDs: []string{"1"}}}, "1_0.json", false},
+		{"edited Tweet", &stream.StreamData{Tweet: &stream.Tweet{ID: "2", Text: text, EditHistoryTweetIDs: []string{"1", "2"}}}, "1_1.json", false},
+		{"no Tweet", &stream.StreamData{Annotations: map[string]any{"note": text}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, dead, store := &recorder{}, &recorder{}, objects{}
+			limited := sink.NewLimited(out, 100, sink.OversizeStore)
+			limited.Store = store
+			limited.DeadLetter = dead
+			if err := limited.Write(tt.msg); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(dead.written()) == 1; got != tt.wantDeadLetter {
+				t.Fatalf("dead-lettered %v, want %v", got, tt.wantDeadLetter)
+			}
+			if tt.wantDeadLetter {
+				if len(store) != 0 || len(out.written()) != 0 {
+					t.Errorf("stored %d objects and wrote %d records of a dead letter", len(store), len(out.written()))
+				}
+				return
+			}
+			written := out.written()
+			if len(written) != 1 {
+				t.Fatalf("wrote %d records, want 1", len(written))
+			}
+			if tt.wantKey == "" {
+				if len(store) != 0 || written[0] != tt.msg {
+					t.Errorf("stored %d objects of a small record", len(store))
+				}
+				return
+			}
+			if _, ok := store[tt.wantKey]; !ok || len(store) != 1 {
+				t.Errorf("stored keys %v, want %s", keys(store), tt.wantKey)
+			}
+			if got := written[0].Annotations[sink.PayloadAnnotation]; got != "mem://"+tt.wantKey {
+				t.Errorf("pointer to %v, want mem://%s", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLimitedStoreNoDeadLetter(t *testing.T) {
+	limited := sink.NewLimited(&recorder{}, 10, sink.OversizeStore)
+	limited.Store = objects{}
+	err := limited.Write(&stream.StreamData{Annotations: map[string]any{"note": "too long for the limit"}})
+	var oversize *sink.OversizeError
+	if !errors.As(err, &oversize) || oversize.Err == nil {
+		t.Errorf("err = %v, want an OversizeError with a cause", err)
+	}
+}
+
+func keys(o objects) []string {
+	var keys []string
+	for k := range o {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,16 @@
+package sink
+
+import "github.com/kalvin807/twitter-v2-stream/stream"
+
+// Pump writes the messages to s until the channel is closed, e.g. the
+// Messages of a Stream once it stopped, then closes s. Failed writes are
+// passed to onError, if set, and do not stop the pump. It returns the error
+// of closing s.
+func Pump(messages <-chan *stream.StreamData, s Sink, onError func(msg *stream.StreamData, err error)) error {
+	for msg := range messages {
+		if err := s.Write(msg); err != nil && onError != nil {
+			onError(msg, err)
+		}
+	}
+	return s.Close()
+}
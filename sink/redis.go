@@ -0,0 +1,69 @@
+package sink
+
+import "github.com/kalvin807/twitter-v2-stream/stream"
+
+// RedisClient publishes to Redis, e.g. an adapter to a go-redis Client.
+type RedisClient interface {
+	// Publish publishes payload to the pub/sub channel.
+	Publish(channel string, payload []byte) error
+	// XAdd appends the fields to the Redis Stream, trimming it to about
+	// maxLen entries (XADD key MAXLEN ~ maxLen * field value ...).
+	XAdd(stream string, maxLen int64, fields map[string]any) error
+	Close() error
+}
+
+// Redis publishes messages to a Redis pub/sub channel, and optionally
+// appends them to a capped Redis Stream for consumers which need to replay
+// messages they missed.
+type Redis struct {
+	client  RedisClient
+	channel string
+	// TagChannel, if set, returns the channel of messages matching a rule
+	// tagged tag, or "" for the default channel. Messages are published
+	// once to each channel of their tags.
+	TagChannel func(tag string) string
+	// Stream, if set, is the Redis Stream messages are appended to, with
+	// the Tweet ID in the "id" field, the Key in the "key" field and the
+	// record in the "data" field.
+	Stream string
+	// Key returns the keys consumers deduplicate entries by,
+	// stream.DeliveryKey by default.
+	Key stream.KeyFunc
+	// StreamMaxLen caps the Redis Stream to about the number of entries.
+	StreamMaxLen int64
+	// Serialize encodes records, JSONSerializer by default.
+	Serialize Serializer
+}
+
+// NewRedis returns a Redis sink publishing to channel.
+func NewRedis(client RedisClient, channel string) *Redis {
+	return &Redis{client: client, channel: channel, StreamMaxLen: 10000, Key: stream.DeliveryKey, Serialize: JSONSerializer}
+}
+
+func (r *Redis) Write(msg *stream.StreamData) error {
+	payload, err := r.Serialize(msg)
+	if err != nil {
+		return err
+	}
+	for _, channel := range tagTargets(msg, r.TagChannel, r.channel) {
+		if err := r.client.Publish(channel, payload); err != nil {
+			return err
+		}
+	}
+	if r.Stream == "" {
+		return nil
+	}
+	fields := map[string]any{"data": payload}
+	if msg.Tweet != nil {
+		fields["id"] = msg.Tweet.ID
+	}
+	if key := deliveryKey(r.Key, msg); key != "" {
+		fields["key"] = key
+	}
+	return r.client.XAdd(r.Stream, r.StreamMaxLen, fields)
+}
+
+// Close closes the client.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
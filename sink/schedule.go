@@ -0,0 +1,148 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Window is a daily time-of-day window, such as the off-peak hours of a
+// metered link. A window whose End is before its Start spans midnight.
+type Window struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// ParseWindow parses a window such as "22:30-05:00" in the location, or
+// local time if loc is nil. The end of the day is 24:00.
+func ParseWindow(s string, loc *time.Location) (Window, error) {
+	var startH, startM, endH, endM int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &startH, &startM, &endH, &endM); err != nil {
+		return Window{}, fmt.Errorf("sink: invalid window %q, want HH:MM-HH:MM", s)
+	}
+	if startH > 23 || endH > 24 || startM > 59 || endM > 59 || endH == 24 && endM != 0 {
+		return Window{}, fmt.Errorf("sink: invalid window %q, want HH:MM-HH:MM", s)
+	}
+	return Window{
+		Start:    time.Duration(startH)*time.Hour + time.Duration(startM)*time.Minute,
+		End:      time.Duration(endH)*time.Hour + time.Duration(endM)*time.Minute,
+		Location: loc,
+	}, nil
+}
+
+// Contains reports whether t is within the window.
+func (w Window) Contains(t time.Time) bool {
+	if w.Location != nil {
+		t = t.In(w.Location)
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// Scheduled restricts a bandwidth-heavy sink, such as bulk uploads, to
+// windows of the day. Messages arriving outside the windows are spilled to
+// disk and written to the sink once a window opens.
+type Scheduled struct {
+	sink    Sink
+	windows []Window
+	spill   *spill
+	// mu keeps direct writes from overtaking spilled messages
+	mu sync.Mutex
+}
+
+// NewScheduled returns a Scheduled sink writing to s during the windows and
+// spilling to the file at spillPath otherwise. Messages spilled by a previous
+// run are kept.
+func NewScheduled(s Sink, spillPath string, windows ...Window) (*Scheduled, error) {
+	spill, err := openSpill(spillPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduled{sink: s, windows: windows, spill: spill}, nil
+}
+
+// Open reports whether a window is open at t.
+func (s *Scheduled) Open(t time.Time) bool {
+	for _, w := range s.windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the message to the sink if a window is open, after the
+// spilled messages, or spills it otherwise.
+func (s *Scheduled) Write(msg *stream.StreamData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Open(time.Now()) {
+		return s.spill.append(msg)
+	}
+	if err := s.spill.drain(s.sink.Write); err != nil {
+		// keep the order: queue behind the messages which failed
+		if spillErr := s.spill.append(msg); spillErr != nil {
+			return spillErr
+		}
+		return err
+	}
+	return s.sink.Write(msg)
+}
+
+// Spilled returns the number of messages waiting for a window.
+func (s *Scheduled) Spilled() int {
+	return s.spill.Len()
+}
+
+// Corrupt returns the number of spilled messages which could not be read
+// back, e.g. after a crash while spilling. They are moved to the spill file
+// with the suffix .corrupt.
+func (s *Scheduled) Corrupt() int {
+	return s.spill.Corrupt()
+}
+
+// Buffered returns the number of spilled messages, see Spilled.
+func (s *Scheduled) Buffered() int {
+	return s.Spilled()
+}
+
+// Run drains spilled messages whenever a window is open, checking every
+// interval, until ctx is done. A failed drain is retried on the next check.
+// Without Run, spilled messages are only drained by the next Write within a
+// window.
+func (s *Scheduled) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if !s.Open(now) {
+				continue
+			}
+			s.mu.Lock()
+			s.spill.drain(s.sink.Write)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close closes the spill file and the sink. Spilled messages stay on disk.
+func (s *Scheduled) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.spill.close(); err != nil {
+		s.sink.Close()
+		return err
+	}
+	return s.sink.Close()
+}
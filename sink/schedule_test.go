@@ -0,0 +1,143 @@
+package sink_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+func TestWindowContains(t *testing.T) {
+	tests := []struct {
+		window string
+		at     string
+		want   bool
+	}{
+		{"09:00-17:00", "09:00", true},
+		{"09:00-17:00", "16:59", true},
+		{"09:00-17:00", "17:00", false},
+		{"09:00-17:00", "08:59", false},
+		{"22:30-05:00", "23:00", true},
+		{"22:30-05:00", "04:59", true},
+		{"22:30-05:00", "05:00", false},
+		{"22:30-05:00", "12:00", false},
+		{"00:00-24:00", "00:00", true},
+		{"00:00-24:00", "23:59", true},
+		{"12:00-12:00", "12:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window+" at "+tt.at, func(t *testing.T) {
+			w, err := sink.ParseWindow(tt.window, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at, err := time.Parse("15:04", tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Contains(at); got != tt.want {
+				t.Errorf("Contains = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWindowInvalid(t *testing.T) {
+	for _, s := range []string{"", "9-17", "24:00-05:00", "22:60-05:00", "22:00-24:30", "22:00-25:00", "night"} {
+		t.Run(s, func(t *testing.T) {
+			if _, err := sink.ParseWindow(s, nil); err == nil {
+				t.Errorf("ParseWindow(%q) succeeded", s)
+			}
+		})
+	}
+}
+
+func TestScheduled(t *testing.T) {
+	allDay, _ := sink.ParseWindow("00:00-24:00", nil)
+	tests := []struct {
+		name string
+		// corrupt is a line appended to the spill file, e.g. by a crash
+		corrupt string
+		// failing makes the sink fail the first write within the window
+		failing       bool
+		wantErr       bool
+		wantDelivered []string
+		wantSpilled   int
+		wantCorrupt   int
+	}{
+		{name: "drained in order", wantDelivered: []string{"1", "2", "3"}},
+		{name: "corrupt line", corrupt: `{"data":{"id":"x"`, wantDelivered: []string{"1", "2", "3"}, wantCorrupt: 1},
+		{name: "sink failing", failing: true, wantErr: true, wantSpilled: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spill.ndjson")
+			out := &recorder{}
+
+			// without windows, the sink is never open and messages spill
+			closed, err := sink.NewScheduled(out, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []string{"1", "2"} {
+				if err := closed.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: id}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if n := closed.Spilled(); n != 2 || len(out.written()) != 0 {
+				t.Fatalf("spilled %d and wrote %d messages outside of the windows", n, len(out.written()))
+			}
+			if err := closed.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.corrupt != "" {
+				f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.WriteString(tt.corrupt + "\n")
+				f.Close()
+			}
+
+			// messages spilled by the previous run are drained within a
+			// window, before the message written
+			if tt.failing {
+				out.err = errors.New("link down")
+			}
+			open, err := sink.NewScheduled(out, path, allDay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer open.Close()
+			err = open.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "3"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var delivered []string
+			for _, msg := range out.written() {
+				delivered = append(delivered, msg.Tweet.ID)
+			}
+			if !reflect.DeepEqual(delivered, tt.wantDelivered) {
+				t.Errorf("delivered %v, want %v", delivered, tt.wantDelivered)
+			}
+			if n := open.Spilled(); n != tt.wantSpilled {
+				t.Errorf("%d spilled, want %d", n, tt.wantSpilled)
+			}
+			if n := open.Corrupt(); n != tt.wantCorrupt {
+				t.Errorf("%d corrupt, want %d", n, tt.wantCorrupt)
+			}
+			if tt.corrupt != "" {
+				data, err := os.ReadFile(path + ".corrupt")
+				if err != nil || !strings.Contains(string(data), tt.corrupt) {
+					t.Errorf("corrupt file %q (%v), want the corrupt line", data, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+//go:generate go run ../cmd/schemagen -o envelope.schema.json -ts envelope.ts -py envelope.py
+
+// Schema is the subset of JSON Schema describing the records sinks write:
+// types, object properties, required properties, array items and formats.
+// The Title of an object schema is the name of the Go type it describes.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Types is the type keyword of a Schema, a single type or a list of types.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// EnvelopeSchema returns the JSON Schema of the records sinks write, derived
+// from stream.StreamData.
+func EnvelopeSchema() *Schema {
+	s := GenerateSchema(reflect.TypeOf(stream.StreamData{}))
+	s.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	return s
+}
+
+// GenerateSchema derives the JSON Schema of the encoding/json encoding of
+// values of type t. Fields without omitempty or omitzero are required;
+// pointers, slices and maps without omitempty may be null.
+func GenerateSchema(t reflect.Type) *Schema {
+	return generateSchema(t, map[reflect.Type]bool{})
+}
+
+func generateSchema(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	}
+	if t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		// custom encodings, e.g. json.RawMessage, may be anything
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: generateSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: generateSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			// recursive types, e.g. Tweet within Includes, are not expanded
+			// twice along the same path
+			return &Schema{Type: Types{"object"}, Title: t.Name()}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := &Schema{Type: Types{"object"}, Title: t.Name(), Properties: map[string]*Schema{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, omitempty, skip := jsonName(field)
+			if skip {
+				continue
+			}
+			prop := generateSchema(field.Type, seen)
+			if !omitempty {
+				s.Required = append(s.Required, name)
+				if nullable(field.Type) && len(prop.Type) > 0 {
+					prop.Type = append(append(Types{}, prop.Type...), "null")
+				}
+			}
+			s.Properties[name] = prop
+		}
+		sort.Strings(s.Required)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func jsonName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// ValidationError lists the violations of a record against a Schema.
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return "sink: record violates schema: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate checks the JSON record against the schema.
+func (s *Schema) Validate(record []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(record))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	var violations []string
+	s.validate("$", v, &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, v interface{}, violations *[]string) {
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		*violations = append(*violations, fmt.Sprintf("%s: want type %s, got %s", path, strings.Join(s.Type, "|"), jsonType(v)))
+		return
+	}
+	switch v := v.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				*violations = append(*violations, fmt.Sprintf("%s: invalid date-time %q", path, v))
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+		for name, value := range v {
+			if prop, ok := s.Properties[name]; ok {
+				prop.validate(path+"."+name, value, violations)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+name, value, violations)
+			}
+		}
+	}
+}
+
+func (t Types) matches(v interface{}) bool {
+	actual := jsonType(v)
+	for _, want := range t {
+		if want == actual || (want == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// Compatible reports the changes from the schema old to s which break
+// consumers relying on old: removed properties, changed types and newly
+// required properties. Use it to check the generated EnvelopeSchema against
+// the committed envelope.schema.json.
+func (s *Schema) Compatible(old *Schema) error {
+	var breaks []string
+	s.compatible("$", old, &breaks)
+	if len(breaks) > 0 {
+		return fmt.Errorf("sink: breaking schema changes: %s", strings.Join(breaks, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) compatible(path string, old *Schema, breaks *[]string) {
+	for _, want := range old.Type {
+		if !containsType(s.Type, want) && len(s.Type) > 0 {
+			*breaks = append(*breaks, fmt.Sprintf("%s: type %s removed", path, want))
+		}
+	}
+	required := map[string]bool{}
+	for _, name := range old.Required {
+		required[name] = true
+	}
+	for _, name := range s.Required {
+		if !required[name] {
+			*breaks = append(*breaks, fmt.Sprintf("%s: property %q became required", path, name))
+		}
+	}
+	for name, oldProp := range old.Properties {
+		prop, ok := s.Properties[name]
+		if !ok {
+			*breaks = append(*breaks, fmt.Sprintf("%s: property %q removed", path, name))
+			continue
+		}
+		prop.compatible(path+"."+name, oldProp, breaks)
+	}
+	if s.Items != nil && old.Items != nil {
+		s.Items.compatible(path+"[]", old.Items, breaks)
+	}
+}
+
+func containsType(types Types, t string) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
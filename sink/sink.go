@@ -0,0 +1,52 @@
+// Package sink delivers stream messages to external systems.
+package sink
+
+import "github.com/kalvin807/twitter-v2-stream/stream"
+
+// Sink is a destination of stream messages. Write must not retain msg after
+// returning. Close flushes buffered messages and releases resources.
+type Sink interface {
+	Write(msg *stream.StreamData) error
+	Close() error
+}
+
+// tagTargets returns the distinct targets, such as topics or channels, of
+// the tags of msg by target, or fallback for tags without a target and for
+// messages without tags.
+func tagTargets(msg *stream.StreamData, target func(tag string) string, fallback string) []string {
+	if target == nil {
+		return []string{fallback}
+	}
+	var targets []string
+	for _, rule := range msg.MatchingRules {
+		t := target(rule.Tag)
+		if t == "" {
+			t = fallback
+		}
+		if !containsString(targets, t) {
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, fallback)
+	}
+	return targets
+}
+
+// deliveryKey returns the key of msg by key, or by stream.DeliveryKey if key
+// is nil.
+func deliveryKey(key stream.KeyFunc, msg *stream.StreamData) string {
+	if key == nil {
+		key = stream.DeliveryKey
+	}
+	return key(msg)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
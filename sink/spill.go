@@ -0,0 +1,151 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// spill is an on-disk queue of messages, stored as newline delimited JSON,
+// absorbing messages while a sink must not be written to. Lines which do
+// not decode, e.g. one left partially written by a crash, are moved to the
+// file with the suffix .corrupt when drained.
+type spill struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	len     int
+	corrupt int
+}
+
+func openSpill(path string) (*spill, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &spill{path: path, file: file}
+	// count messages left over from a previous run
+	lines, err := readLines(file)
+	s.len = len(lines)
+	return s, err
+}
+
+// readLines returns the non-empty lines of r. A last line without a newline
+// is returned too.
+func readLines(r io.Reader) ([][]byte, error) {
+	var lines [][]byte
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSuffix(line, []byte{'\n'}); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+func (s *spill) append(msg *stream.StreamData) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.len++
+	return nil
+}
+
+// Corrupt returns the number of spilled lines moved aside because they did
+// not decode.
+func (s *spill) Corrupt() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.corrupt
+}
+
+// Len returns the number of spilled messages.
+func (s *spill) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.len
+}
+
+// drain writes the spilled messages to fn in order, moving aside those which
+// do not decode. If fn fails, the remaining messages are kept for the next
+// drain and the error is returned.
+func (s *spill) drain(fn func(*stream.StreamData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.len == 0 {
+		return nil
+	}
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return err
+	}
+	lines, err := readLines(s.file)
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		msg := &stream.StreamData{}
+		if err := json.Unmarshal(line, msg); err != nil {
+			if err := s.moveAside(line); err != nil {
+				return s.rewrite(lines[i:], err)
+			}
+			continue
+		}
+		if err := fn(msg); err != nil {
+			return s.rewrite(lines[i:], err)
+		}
+	}
+	return s.rewrite(nil, nil)
+}
+
+// moveAside appends an undecodable line to the .corrupt file and counts it.
+func (s *spill) moveAside(line []byte) error {
+	f, err := os.OpenFile(s.path+".corrupt", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.corrupt++
+	return nil
+}
+
+// rewrite replaces the spilled messages with lines and returns cause, or the
+// error of rewriting.
+func (s *spill) rewrite(lines [][]byte, cause error) error {
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := s.file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	s.len = len(lines)
+	return cause
+}
+
+func (s *spill) close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,358 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect int
+
+const (
+	// DialectPostgres uses $n placeholders.
+	DialectPostgres Dialect = iota + 1
+	// DialectSQLite uses ? placeholders, and needs SQLite 3.24 or later
+	// for upserts.
+	DialectSQLite
+)
+
+// placeholders rewrites the $n placeholders of query, numbered from 1 in
+// order of appearance, for the dialect.
+func (d Dialect) placeholders(query string) string {
+	if d != DialectSQLite {
+		return query
+	}
+	for n := strings.Count(query, "$"); n > 0; n-- {
+		query = strings.ReplaceAll(query, fmt.Sprintf("$%d", n), "?")
+	}
+	return query
+}
+
+// migrations are the schema versions of SQL sinks, applied in order. They
+// only use types and statements common to Postgres and SQLite.
+var migrations = []string{
+	`CREATE TABLE tweets (
+		id TEXT PRIMARY KEY,
+		author_id TEXT,
+		conversation_id TEXT,
+		text TEXT NOT NULL,
+		lang TEXT,
+		created_at TIMESTAMP,
+		data TEXT NOT NULL
+	);
+	CREATE TABLE authors (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL,
+		name TEXT NOT NULL
+	);
+	CREATE TABLE tweet_rules (
+		tweet_id TEXT NOT NULL REFERENCES tweets (id),
+		rule_id TEXT NOT NULL,
+		tag TEXT,
+		PRIMARY KEY (tweet_id, rule_id)
+	);
+	CREATE INDEX tweets_author_id ON tweets (author_id);
+	CREATE INDEX tweet_rules_tag ON tweet_rules (tag);`,
+	// rows written before are keyed as the first revision of their Tweet
+	`ALTER TABLE tweets ADD COLUMN delivery_key TEXT;
+	UPDATE tweets SET delivery_key = id || ':0';
+	CREATE UNIQUE INDEX tweets_delivery_key ON tweets (delivery_key);`,
+}
+
+// Migrate creates or upgrades the schema of SQL sinks in db, recording the
+// applied version in the schema_migrations table.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL)`); err != nil {
+		return err
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		// drivers do not all accept several statements in one Exec
+		for _, stmt := range strings.Split(migrations[version], ";") {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d: %w", version+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, dialect.placeholders(`INSERT INTO schema_migrations (version) VALUES ($1)`), version+1); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	upsertTweet = `INSERT INTO tweets (id, author_id, conversation_id, text, lang, created_at, data, delivery_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (delivery_key) DO UPDATE SET text = excluded.text, lang = excluded.lang, data = excluded.data`
+	upsertAuthor = `INSERT INTO authors (id, username, name) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET username = excluded.username, name = excluded.name`
+	upsertTweetRule = `INSERT INTO tweet_rules (tweet_id, rule_id, tag) VALUES ($1, $2, $3)
+		ON CONFLICT (tweet_id, rule_id) DO UPDATE SET tag = excluded.tag`
+)
+
+// sqlRecord holds the rows of a message, so batches do not retain messages.
+type sqlRecord struct {
+	tweet  []any
+	author []any
+	rules  [][]any
+	// data is the serialized message, also in the data column of tweet
+	data []byte
+}
+
+// SQL upserts Tweets, their authors and the rules they matched into a
+// Postgres or SQLite database, in batches of BatchSize messages per
+// transaction. Tweets are upserted by Key, so redelivered Tweets update
+// their rows. Messages without a Tweet are skipped.
+//
+// If a batch fails, its messages are retried one per transaction, so one
+// message the database rejects does not hold back the others. Messages
+// which fail while others succeed are appended to the DeadLetter file, or
+// dropped. If all fail, e.g. while the database is down, the batch is kept
+// for the next flush, up to MaxPending messages, beyond which the oldest are
+// dead-lettered.
+type SQL struct {
+	db      *sql.DB
+	dialect Dialect
+	// BatchSize is the number of messages written per transaction.
+	BatchSize int
+	// MaxPending bounds the messages kept while the database fails, 10
+	// batches by default.
+	MaxPending int
+	// DeadLetter, if set, is the path of the file messages which cannot be
+	// written are appended to, one serialized message per line.
+	DeadLetter string
+	// Serialize encodes the data column, JSONSerializer by default.
+	Serialize Serializer
+	// Key returns the delivery_key column Tweets are upserted by,
+	// stream.DeliveryKey by default.
+	Key stream.KeyFunc
+
+	mu    sync.Mutex
+	batch []sqlRecord
+}
+
+// NewSQL migrates the schema of db and returns an SQL sink writing to it.
+// The db is opened with the driver of the dialect, e.g. pgx or sqlite.
+func NewSQL(ctx context.Context, db *sql.DB, dialect Dialect) (*SQL, error) {
+	if err := Migrate(ctx, db, dialect); err != nil {
+		return nil, err
+	}
+	return &SQL{db: db, dialect: dialect, BatchSize: 100, Serialize: JSONSerializer, Key: stream.DeliveryKey}, nil
+}
+
+// Write adds the message to the batch and writes the batch once full.
+func (s *SQL) Write(msg *stream.StreamData) error {
+	if msg.Tweet == nil {
+		return nil
+	}
+	data, err := s.Serialize(msg)
+	if err != nil {
+		return err
+	}
+	t := msg.Tweet
+	var createdAt any
+	if !t.CreatedAt.IsZero() {
+		createdAt = t.CreatedAt.UTC()
+	}
+	record := sqlRecord{
+		tweet: []any{t.ID, nullString(t.AuthorID), nullString(t.ConversationID), t.Text, nullString(t.Lang), createdAt, string(data), nullString(deliveryKey(s.Key, msg))},
+		data:  data,
+	}
+	if msg.Includes != nil {
+		for _, u := range msg.Includes.Users {
+			if u.ID == t.AuthorID {
+				record.author = []any{u.ID, u.Username, u.Name}
+				break
+			}
+		}
+	}
+	for _, rule := range msg.MatchingRules {
+		record.rules = append(record.rules, []any{t.ID, rule.Id, nullString(rule.Tag)})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch = append(s.batch, record)
+	if len(s.batch) < s.BatchSize {
+		return nil
+	}
+	return s.flush()
+}
+
+// Flush writes the pending batch.
+func (s *SQL) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// flush writes the batch in a transaction, or if that fails each message in
+// its own. Messages failing while others succeed are dead-lettered; if all
+// fail, the batch is kept to be retried by the next flush, up to MaxPending.
+func (s *SQL) flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	err := s.writeTx(ctx, s.batch)
+	if err == nil {
+		s.batch = s.batch[:0]
+		return nil
+	}
+	var failed []sqlRecord
+	for _, record := range s.batch {
+		if recordErr := s.writeTx(ctx, []sqlRecord{record}); recordErr != nil {
+			failed = append(failed, record)
+			err = recordErr
+		}
+	}
+	if len(failed) == 0 {
+		s.batch = s.batch[:0]
+		return nil
+	}
+	if len(failed) < len(s.batch) {
+		// the database accepts other messages, so these are rejected for
+		// their content and would fail again
+		s.batch = s.batch[:0]
+		return s.reject(failed, err)
+	}
+	maxPending := s.MaxPending
+	if maxPending <= 0 {
+		maxPending = 10 * max(s.BatchSize, 1)
+	}
+	if over := len(s.batch) - maxPending; over > 0 {
+		rejected := append([]sqlRecord(nil), s.batch[:over]...)
+		s.batch = append(s.batch[:0], s.batch[over:]...)
+		return s.reject(rejected, err)
+	}
+	return fmt.Errorf("sql: %d messages pending: %w", len(s.batch), err)
+}
+
+// reject dead-letters records which could not be written because of err.
+func (s *SQL) reject(records []sqlRecord, err error) error {
+	if s.DeadLetter == "" {
+		return fmt.Errorf("sql: %d messages dropped: %w", len(records), err)
+	}
+	if dlErr := s.deadLetter(records); dlErr != nil {
+		return fmt.Errorf("sql: %d messages lost: %w (dead letter: %v)", len(records), err, dlErr)
+	}
+	return fmt.Errorf("sql: %d messages dead-lettered: %w", len(records), err)
+}
+
+func (s *SQL) deadLetter(records []sqlRecord) error {
+	f, err := os.OpenFile(s.DeadLetter, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if _, err := f.Write(append(record.data, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// writeTx writes the records in a transaction.
+func (s *SQL) writeTx(ctx context.Context, records []sqlRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.write(ctx, tx, records); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQL) write(ctx context.Context, tx *sql.Tx, records []sqlRecord) error {
+	tweets, err := tx.PrepareContext(ctx, s.dialect.placeholders(upsertTweet))
+	if err != nil {
+		return err
+	}
+	defer tweets.Close()
+	authors, err := tx.PrepareContext(ctx, s.dialect.placeholders(upsertAuthor))
+	if err != nil {
+		return err
+	}
+	defer authors.Close()
+	rules, err := tx.PrepareContext(ctx, s.dialect.placeholders(upsertTweetRule))
+	if err != nil {
+		return err
+	}
+	defer rules.Close()
+	for _, record := range records {
+		if record.author != nil {
+			if _, err := authors.ExecContext(ctx, record.author...); err != nil {
+				return err
+			}
+		}
+		if _, err := tweets.ExecContext(ctx, record.tweet...); err != nil {
+			return err
+		}
+		for _, rule := range record.rules {
+			if _, err := rules.ExecContext(ctx, rule...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Buffered returns the number of messages waiting for the batch to fill.
+func (s *SQL) Buffered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batch)
+}
+
+// Run writes the pending batch every interval until ctx is done, so batches
+// of quiet streams are not held back.
+func (s *SQL) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.Flush()
+		}
+	}
+}
+
+// Close writes the pending batch and closes the database.
+func (s *SQL) Close() error {
+	if err := s.Flush(); err != nil {
+		s.db.Close()
+		return err
+	}
+	return s.db.Close()
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,46 @@
+package sink
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Validating checks the records written to a sink against the
+// EnvelopeSchema, catching accidental breaking changes to downstream
+// contracts. Validate every record in tests and a sample in production.
+type Validating struct {
+	sink       Sink
+	schema     *Schema
+	sampleRate float64
+	// OnViolation is called with records which violate the schema. If it is
+	// nil, Write returns the violation instead of writing the record.
+	OnViolation func(msg *stream.StreamData, err error)
+}
+
+// NewValidating returns a Validating sink checking the fraction sampleRate,
+// from 0 to 1, of the records written to s.
+func NewValidating(s Sink, sampleRate float64) *Validating {
+	return &Validating{sink: s, schema: EnvelopeSchema(), sampleRate: sampleRate}
+}
+
+func (v *Validating) Write(msg *stream.StreamData) error {
+	if v.sampleRate >= 1 || rand.Float64() < v.sampleRate {
+		record, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if err := v.schema.Validate(record); err != nil {
+			if v.OnViolation == nil {
+				return err
+			}
+			v.OnViolation(msg, err)
+		}
+	}
+	return v.sink.Write(msg)
+}
+
+func (v *Validating) Close() error {
+	return v.sink.Close()
+}
@@ -0,0 +1,273 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/streampb"
+)
+
+// webhookTimeout bounds each request of a Webhook by default.
+const webhookTimeout = 10 * time.Second
+
+// IdempotencyKeyHeader is the header of webhook requests carrying the Key
+// of the message, or for batches the SHA-256 of the keys of its messages,
+// which receivers can deduplicate redelivered requests by.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// SignatureHeader is the header of webhook requests carrying the
+// HMAC-SHA256 of the body with the secret, as "sha256=" and the hex digest.
+const SignatureHeader = "X-Signature-256"
+
+// Webhook POSTs messages as JSON to a URL, one message per request, or
+// batches of messages as JSON arrays. Failed requests are retried with
+// exponential backoff, and batches which still cannot be delivered are
+// appended to the DeadLetter file. A full batch is posted by the Write
+// which filled it, while other Writes start the next batch, so batches may
+// arrive out of order.
+type Webhook struct {
+	url    string
+	secret []byte
+	// Client sends the requests. The default client gives up on requests
+	// after Timeout.
+	Client *http.Client
+	// Timeout bounds each attempt of a request, 10 seconds by default.
+	Timeout time.Duration
+	// BatchSize posts batches of up to the number of messages as arrays.
+	// Up to 1, messages are posted one by one as objects.
+	BatchSize int
+	// NewBackOff returns the backoff between the attempts of a request. By
+	// default requests are retried for up to a minute.
+	NewBackOff func() backoff.BackOff
+	// DeadLetter, if set, is the path of the file undeliverable messages
+	// are appended to as newline delimited JSON, or with UseProtobuf as
+	// StreamDataBatch messages, which together decode as one.
+	DeadLetter string
+	// Serialize encodes messages, JSONSerializer by default. See
+	// UseProtobuf to post protobuf instead.
+	Serialize Serializer
+	// ContentType is the media type of request bodies, application/json by
+	// default.
+	ContentType string
+	// JoinBatch encodes the records of a batch as one body, a JSON array by
+	// default.
+	JoinBatch func(records [][]byte) []byte
+	// Key returns the keys of the IdempotencyKeyHeader, stream.DeliveryKey
+	// by default.
+	Key stream.KeyFunc
+
+	mu    sync.Mutex
+	batch [][]byte
+	keys  []string
+}
+
+// NewWebhook returns a Webhook posting to url, signing requests with the
+// secret unless it is empty.
+func NewWebhook(url string, secret []byte) *Webhook {
+	return &Webhook{
+		url:         url,
+		secret:      secret,
+		Client:      &http.Client{Timeout: webhookTimeout},
+		Timeout:     webhookTimeout,
+		Serialize:   JSONSerializer,
+		ContentType: "application/json",
+		JoinBatch:   jsonArray,
+		Key:         stream.DeliveryKey,
+		NewBackOff: func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.MaxElapsedTime = time.Minute
+			return b
+		},
+	}
+}
+
+// UseProtobuf posts messages as StreamData messages of streampb.proto, and
+// batches as StreamDataBatch messages, with the protobuf content type.
+func (w *Webhook) UseProtobuf() {
+	w.Serialize = ProtobufSerializer
+	w.ContentType = streampb.ContentType
+	w.JoinBatch = streampb.MarshalBatch
+}
+
+// jsonArray joins JSON records as a JSON array.
+func jsonArray(records [][]byte) []byte {
+	body := append([]byte{'['}, bytes.Join(records, []byte{','})...)
+	return append(body, ']')
+}
+
+// Write posts the message, or adds it to the batch and posts the batch once
+// full.
+func (w *Webhook) Write(msg *stream.StreamData) error {
+	record, err := w.Serialize(msg)
+	if err != nil {
+		return err
+	}
+	key := deliveryKey(w.Key, msg)
+	w.mu.Lock()
+	w.batch = append(w.batch, record)
+	w.keys = append(w.keys, key)
+	if len(w.batch) < w.BatchSize {
+		w.mu.Unlock()
+		return nil
+	}
+	batch, keys := w.batch, w.keys
+	w.batch, w.keys = nil, nil
+	w.mu.Unlock()
+	return w.post(context.Background(), batch, keys)
+}
+
+// Flush posts the pending batch.
+func (w *Webhook) Flush() error {
+	return w.flush(context.Background())
+}
+
+// flush posts the pending batch, giving up retrying once ctx is done.
+func (w *Webhook) flush(ctx context.Context) error {
+	w.mu.Lock()
+	batch, keys := w.batch, w.keys
+	w.batch, w.keys = nil, nil
+	w.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	return w.post(ctx, batch, keys)
+}
+
+// post posts the batch of messages with the keys, retrying with backoff, and
+// dead-letters it if it cannot be delivered. The lock is not held, so
+// Writes are not blocked meanwhile.
+func (w *Webhook) post(ctx context.Context, batch [][]byte, keys []string) error {
+	var body []byte
+	var key string
+	if w.BatchSize > 1 {
+		body = w.JoinBatch(batch)
+		key = batchKey(keys)
+	} else {
+		body, key = batch[0], keys[0]
+	}
+	err := backoff.Retry(func() error { return w.attempt(ctx, body, key) }, backoff.WithContext(w.NewBackOff(), ctx))
+	if err == nil {
+		return nil
+	}
+	if w.DeadLetter == "" {
+		return fmt.Errorf("webhook: %d messages undeliverable: %w", len(batch), err)
+	}
+	if dlErr := w.deadLetter(batch); dlErr != nil {
+		return fmt.Errorf("webhook: %d messages lost: %w (dead letter: %v)", len(batch), err, dlErr)
+	}
+	return fmt.Errorf("webhook: %d messages dead-lettered: %w", len(batch), err)
+}
+
+// batchKey returns the idempotency key of a batch of messages with the keys,
+// or "" if none of them has one.
+func batchKey(keys []string) string {
+	h := sha256.New()
+	keyed := false
+	for _, key := range keys {
+		keyed = keyed || key != ""
+		io.WriteString(h, key)
+		h.Write([]byte{'\n'})
+	}
+	if !keyed {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// attempt makes one attempt to post body with the idempotency key, within
+// Timeout. Client errors other than timeouts and rate limits are not
+// retried.
+func (w *Webhook) attempt(ctx context.Context, body []byte, key string) error {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", w.ContentType)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("unexpected status %s", resp.Status)
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return backoff.Permanent(err)
+	}
+	return err
+}
+
+func (w *Webhook) deadLetter(batch [][]byte) error {
+	f, err := os.OpenFile(w.DeadLetter, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if w.ContentType == streampb.ContentType {
+		// binary records cannot be delimited by newlines
+		if _, err := f.Write(streampb.MarshalBatch(batch)); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	for _, record := range batch {
+		if _, err := f.Write(append(record, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Buffered returns the number of messages waiting for the batch to fill.
+func (w *Webhook) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.batch)
+}
+
+// Run posts the pending batch every interval until ctx is done, so batches
+// of quiet streams are not held back. A failed post is dead-lettered.
+func (w *Webhook) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			w.flush(ctx)
+		}
+	}
+}
+
+// Close posts the pending batch.
+func (w *Webhook) Close() error {
+	return w.Flush()
+}
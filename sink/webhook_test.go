@@ -0,0 +1,136 @@
+package sink_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+func TestWebhook(t *testing.T) {
+	const retries = 3
+	tests := []struct {
+		name string
+		// statuses are the answers to the attempts, the last one repeated
+		statuses       []int
+		deadLetter     bool
+		wantAttempts   int
+		wantErr        string
+		wantDeadLetter bool
+	}{
+		{name: "delivered", statuses: []int{200}, wantAttempts: 1},
+		{name: "unavailable", statuses: []int{503, 502, 200}, wantAttempts: 3},
+		{name: "rate limited", statuses: []int{429, 204}, wantAttempts: 2},
+		{name: "timed out", statuses: []int{408, 200}, wantAttempts: 2},
+		{name: "rejected", statuses: []int{400}, deadLetter: true, wantAttempts: 1, wantErr: "1 messages dead-lettered", wantDeadLetter: true},
+		{name: "retries exhausted", statuses: []int{503}, deadLetter: true, wantAttempts: 1 + retries, wantErr: "1 messages dead-lettered", wantDeadLetter: true},
+		{name: "no dead letter", statuses: []int{503}, wantAttempts: 1 + retries, wantErr: "1 messages undeliverable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := []byte("secret")
+			var mu sync.Mutex
+			var keys []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mac := hmac.New(sha256.New, secret)
+				mac.Write(body)
+				if got, want := r.Header.Get(sink.SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+					t.Errorf("signature %s, want %s", got, want)
+				}
+				mu.Lock()
+				keys = append(keys, r.Header.Get(sink.IdempotencyKeyHeader))
+				status := tt.statuses[min(len(keys), len(tt.statuses))-1]
+				mu.Unlock()
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			webhook := sink.NewWebhook(srv.URL, secret)
+			webhook.NewBackOff = func() backoff.BackOff {
+				return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, retries)
+			}
+			if tt.deadLetter {
+				webhook.DeadLetter = filepath.Join(t.TempDir(), "dead.ndjson")
+			}
+			err := webhook.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: "1", Text: "hello"}})
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if len(keys) != tt.wantAttempts {
+				t.Errorf("%d attempts, want %d", len(keys), tt.wantAttempts)
+			}
+			for _, key := range keys {
+				if key != "1:0" {
+					t.Errorf("idempotency key %q, want the delivery key 1:0 on every attempt", key)
+				}
+			}
+			if !tt.deadLetter {
+				return
+			}
+			data, err := os.ReadFile(webhook.DeadLetter)
+			if tt.wantDeadLetter != (err == nil) {
+				t.Fatalf("dead letter file: %v, want %v", err, tt.wantDeadLetter)
+			}
+			var msg stream.StreamData
+			if err := json.Unmarshal(data, &msg); err != nil || msg.Tweet.ID != "1" {
+				t.Errorf("dead-lettered %q (%v), want Tweet 1", data, err)
+			}
+		})
+	}
+}
+
+func TestWebhookBatch(t *testing.T) {
+	bodies := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- r.Header.Get(sink.IdempotencyKeyHeader) + " " + string(body)
+	}))
+	defer srv.Close()
+
+	webhook := sink.NewWebhook(srv.URL, nil)
+	webhook.BatchSize = 2
+	tests := []struct {
+		id       string
+		wantPost bool
+	}{
+		{"1", false},
+		{"2", true},
+		{"3", false},
+	}
+	for _, tt := range tests {
+		if err := webhook.Write(&stream.StreamData{Tweet: &stream.Tweet{ID: tt.id}}); err != nil {
+			t.Fatal(err)
+		}
+		if posted := len(bodies) > 0; posted != tt.wantPost {
+			t.Errorf("after Tweet %s posted %v, want %v", tt.id, posted, tt.wantPost)
+		}
+		if tt.wantPost {
+			key, body, _ := strings.Cut(<-bodies, " ")
+			if len(key) != 64 || !strings.HasPrefix(body, "[") || !strings.Contains(body, `"id":"2"`) {
+				t.Errorf("posted key %q and body %s, want a hashed key and an array", key, body)
+			}
+		}
+	}
+	if n := webhook.Buffered(); n != 1 {
+		t.Errorf("%d buffered, want 1", n)
+	}
+	if err := webhook.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if body := <-bodies; !strings.Contains(body, `[{"data":{"id":"3"`) {
+		t.Errorf("Close posted %s, want the batch of Tweet 3", body)
+	}
+}
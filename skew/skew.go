@@ -0,0 +1,118 @@
+// Package skew estimates the offset of the local clock from true time, so
+// latencies computed from Tweet timestamps are not distorted by clock drift
+// on hosts without reliable time synchronization.
+package skew
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds from the NTP epoch, 1900, to the
+// Unix epoch.
+const ntpEpochOffset = 2208988800
+
+// Fixed returns an offset function reporting a configured offset, e.g.
+// measured by the operator.
+func Fixed(offset time.Duration) func() time.Duration {
+	return func() time.Duration { return offset }
+}
+
+// SNTP estimates the clock offset by querying an NTP server with the simple
+// network time protocol. The offset is added to the local time to get the
+// server time.
+type SNTP struct {
+	server string
+
+	mu     sync.Mutex
+	offset time.Duration
+	synced time.Time
+}
+
+// NewSNTP returns an SNTP querying server, such as "pool.ntp.org:123".
+func NewSNTP(server string) *SNTP {
+	return &SNTP{server: server}
+}
+
+// Offset returns the last estimated offset, zero before the first sync.
+func (s *SNTP) Offset() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offset
+}
+
+// Synced returns the time of the last successful sync.
+func (s *SNTP) Synced() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+// Run syncs every interval until ctx is done. Failed syncs keep the previous
+// offset.
+func (s *SNTP) Run(ctx context.Context, interval time.Duration) {
+	s.Sync(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sync(ctx)
+		}
+	}
+}
+
+// Sync queries the server once and updates the offset.
+func (s *SNTP) Sync(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", s.server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	req := make([]byte, 48)
+	// leap indicator 0, version 4, client mode
+	req[0] = 0x23
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return err
+	}
+	t4 := time.Now()
+	if n < 48 || resp[0]&0x07 != 4 {
+		return errors.New("skew: invalid NTP response")
+	}
+	t2 := ntpTime(resp[32:40])
+	t3 := ntpTime(resp[40:48])
+	offset := (t2.Sub(t1) + t3.Sub(t4)) / 2
+
+	s.mu.Lock()
+	s.offset = offset
+	s.synced = t4
+	s.mu.Unlock()
+	return nil
+}
+
+// ntpTime decodes an NTP timestamp: seconds since 1900 and a binary
+// fraction.
+func ntpTime(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[:4])) - ntpEpochOffset
+	fraction := int64(binary.BigEndian.Uint32(b[4:]))
+	return time.Unix(seconds, fraction*1e9>>32)
+}
@@ -0,0 +1,176 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// BoltDB is the subset of a bbolt database the Bolt store needs:
+// transactions on the bucket of the store. Adapt go.etcd.io/bbolt to it,
+// whose *bbolt.Bucket is a BoltBucket; this package does not depend on it:
+//
+//	type boltDB struct{ db *bbolt.DB }
+//
+//	func (b boltDB) View(fn func(state.BoltBucket) error) error {
+//		return b.db.View(func(tx *bbolt.Tx) error {
+//			return fn(tx.Bucket([]byte("state")))
+//		})
+//	}
+//
+//	func (b boltDB) Update(fn func(state.BoltBucket) error) error {
+//		return b.db.Update(func(tx *bbolt.Tx) error {
+//			return fn(tx.Bucket([]byte("state")))
+//		})
+//	}
+//
+// with the bucket created when opening the database.
+type BoltDB interface {
+	// View calls fn with the bucket in a read-only transaction.
+	View(fn func(BoltBucket) error) error
+	// Update calls fn with the bucket in a read-write transaction, which is
+	// committed if fn returns nil and rolled back otherwise.
+	Update(fn func(BoltBucket) error) error
+}
+
+// BoltBucket is the subset of the methods of *bbolt.Bucket the Bolt store
+// needs.
+type BoltBucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	ForEach(fn func(k, v []byte) error) error
+}
+
+// Bolt is a Store persisting its entries to a bbolt database. Unlike File,
+// writes only touch their own entry, so it also suits the write rates of
+// dedupe state on a single instance.
+type Bolt struct {
+	db BoltDB
+}
+
+// NewBolt returns a Bolt store of the bucket of db.
+func NewBolt(db BoltDB) *Bolt {
+	return &Bolt{db: db}
+}
+
+func (b *Bolt) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	var ok bool
+	err := b.db.View(func(bucket BoltBucket) error {
+		e, found, err := getEntry(bucket, key)
+		if err != nil || !found || e.expired(time.Now()) {
+			return err
+		}
+		value, ok = e.Value, true
+		return nil
+	})
+	return value, ok, err
+}
+
+func (b *Bolt) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	data, err := json.Marshal(newEntry(value, ttl))
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(bucket BoltBucket) error {
+		return bucket.Put([]byte(key), data)
+	})
+}
+
+func (b *Bolt) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(newEntry(value, ttl))
+	if err != nil {
+		return false, err
+	}
+	var set bool
+	err = b.db.Update(func(bucket BoltBucket) error {
+		e, found, err := getEntry(bucket, key)
+		if err != nil || found && !e.expired(time.Now()) {
+			return err
+		}
+		set = true
+		return bucket.Put([]byte(key), data)
+	})
+	return set && err == nil, err
+}
+
+func (b *Bolt) Delete(ctx context.Context, key string) error {
+	return b.db.Update(func(bucket BoltBucket) error {
+		return bucket.Delete([]byte(key))
+	})
+}
+
+func (b *Bolt) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	now := time.Now()
+	matches := make(map[string][]byte)
+	err := b.db.View(func(bucket BoltBucket) error {
+		return bucket.ForEach(func(k, v []byte) error {
+			if !bytes.HasPrefix(k, []byte(prefix)) {
+				return nil
+			}
+			var e entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if !e.expired(now) {
+				matches[string(k)] = e.Value
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// fn is called outside of the transaction, so it may use the store
+	for key, value := range matches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune deletes the expired entries, which are otherwise kept in the
+// database until they are overwritten or deleted.
+func (b *Bolt) Prune(ctx context.Context) error {
+	return b.db.Update(func(bucket BoltBucket) error {
+		now := time.Now()
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var e entry
+			if err := json.Unmarshal(v, &e); err == nil && e.expired(now) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// the bucket must not be modified while iterating it
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getEntry returns the entry of key in bucket. Decoding copies the value,
+// which bbolt only keeps valid within the transaction.
+func getEntry(bucket BoltBucket, key string) (entry, bool, error) {
+	data := bucket.Get([]byte(key))
+	if data == nil {
+		return entry{}, false, nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return entry{}, false, err
+	}
+	return e, true, nil
+}
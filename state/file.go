@@ -0,0 +1,92 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File is a Store persisting its entries to a JSON file. Every change
+// rewrites the file atomically, so it suits the low write rates of
+// checkpoints and rule history rather than high-volume dedupe state.
+type File struct {
+	*Memory
+	path string
+	// mu serializes writes of the file
+	mu sync.Mutex
+}
+
+// OpenFile returns a File store persisting to path, loading the entries
+// stored there if the file exists.
+func OpenFile(path string) (*File, error) {
+	f := &File{Memory: NewMemory(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &f.Memory.entries); err != nil {
+		return nil, err
+	}
+	f.Memory.prune()
+	return f, nil
+}
+
+func (f *File) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Memory.Set(ctx, key, value, ttl)
+	return f.save()
+}
+
+func (f *File) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if ok, _ := f.Memory.SetNX(ctx, key, value, ttl); !ok {
+		return false, nil
+	}
+	return true, f.save()
+}
+
+func (f *File) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Memory.Delete(ctx, key)
+	return f.save()
+}
+
+// save writes all unexpired entries to a temporary file and renames it over
+// the store file, so a crash never leaves a partially written store behind.
+func (f *File) save() error {
+	f.Memory.prune()
+	f.Memory.mu.RLock()
+	data, err := json.Marshal(f.Memory.entries)
+	f.Memory.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
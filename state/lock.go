@@ -0,0 +1,149 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLockAborted is returned by Lock.Acquire when it is given up before
+	// the lock was acquired.
+	ErrLockAborted = errors.New("state: lock acquisition aborted")
+	// ErrLockHeld is returned by Lock.Acquire when the lock is held already,
+	// until it is released.
+	ErrLockHeld = errors.New("state: lock already held")
+)
+
+// Lock is a lease on a key of a Store, held by one instance at a time. It
+// implements stream.ConnectionLock: the lease expires after ttl unless the
+// holder renews it, which it does every third of ttl, so the lock of an
+// instance which crashed is released after ttl.
+//
+// Renewing and releasing check the holder and then write, which is not
+// atomic; a lease expiring in between may be taken over and then renewed or
+// deleted by the old holder. Choose a ttl well above the latency of the
+// store.
+type Lock struct {
+	store Store
+	key   string
+	ttl   time.Duration
+	owner []byte
+
+	mu sync.Mutex
+	// stop ends the renewal of a held lock, which closes renewed once it
+	// returned
+	stop    chan struct{}
+	renewed chan struct{}
+}
+
+// NewLock returns a Lock of key in store whose lease lasts ttl, which must
+// be positive.
+func NewLock(store Store, key string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("state: lock ttl %v is not positive", ttl)
+	}
+	owner := make([]byte, 16)
+	rand.Read(owner)
+	return &Lock{store: store, key: key, ttl: ttl, owner: []byte(hex.EncodeToString(owner))}, nil
+}
+
+// Acquire blocks until the lock is held, retrying every third of the ttl,
+// also when the store fails, or until done is closed, returning
+// ErrLockAborted then. It returns ErrLockHeld if the lock is held already,
+// e.g. by another stream of the same service.
+func (l *Lock) Acquire(done <-chan struct{}) error {
+	if l.held() {
+		return ErrLockHeld
+	}
+	interval := l.ttl / 3
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		ok, err := l.store.SetNX(ctx, l.key, l.owner, l.ttl)
+		if err == nil && !ok {
+			// a lease of this lock which was not released, e.g. after a
+			// failed delete, is held already
+			var value []byte
+			value, ok, err = l.store.Get(ctx, l.key)
+			ok = err == nil && ok && bytes.Equal(value, l.owner)
+		}
+		cancel()
+		if ok {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.stop != nil {
+				// a concurrent Acquire took the lease first
+				return ErrLockHeld
+			}
+			l.stop = make(chan struct{})
+			l.renewed = make(chan struct{})
+			go l.renew(l.stop, l.renewed)
+			return nil
+		}
+		select {
+		case <-done:
+			return ErrLockAborted
+		case <-time.After(interval):
+		}
+	}
+}
+
+// held reports whether the lock is held, i.e. renewed.
+func (l *Lock) held() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stop != nil
+}
+
+// renew extends the lease every third of the ttl until stop is closed.
+func (l *Lock) renew(stop, renewed chan struct{}) {
+	defer close(renewed)
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		value, ok, err := l.store.Get(ctx, l.key)
+		switch {
+		case err != nil:
+			// retried with the next tick, before the lease expires
+		case !ok:
+			// the lease expired, e.g. while the store was unavailable
+			l.store.SetNX(ctx, l.key, l.owner, l.ttl)
+		case bytes.Equal(value, l.owner):
+			l.store.Set(ctx, l.key, l.owner, l.ttl)
+		}
+		cancel()
+	}
+}
+
+// Release stops renewing the lease and deletes it if it is still held.
+// Releasing a lock which is not held does nothing.
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	stop, renewed := l.stop, l.renewed
+	l.stop, l.renewed = nil, nil
+	l.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	<-renewed
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+	value, ok, err := l.store.Get(ctx, l.key)
+	if err != nil || !ok || !bytes.Equal(value, l.owner) {
+		return err
+	}
+	return l.store.Delete(ctx, l.key)
+}
@@ -0,0 +1,78 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+)
+
+func TestNewLockTTL(t *testing.T) {
+	tests := []struct {
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{-time.Second, true},
+		{0, true},
+		{time.Nanosecond, false},
+		{30 * time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ttl.String(), func(t *testing.T) {
+			lock, err := state.NewLock(state.NewMemory(), "connection", tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if (lock == nil) != tt.wantErr {
+				t.Errorf("lock = %v", lock)
+			}
+		})
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemory()
+	ttl := 300 * time.Millisecond
+	first, _ := state.NewLock(store, "connection", ttl)
+	second, _ := state.NewLock(store, "connection", ttl)
+
+	if err := first.Acquire(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Acquire(nil); !errors.Is(err, state.ErrLockHeld) {
+		t.Fatalf("second Acquire = %v, want ErrLockHeld", err)
+	}
+
+	// the lease is renewed past its ttl while held
+	done := make(chan struct{})
+	time.AfterFunc(2*ttl, func() { close(done) })
+	if err := second.Acquire(done); !errors.Is(err, state.ErrLockAborted) {
+		t.Fatalf("Acquire of a held lock = %v, want ErrLockAborted", err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Get(ctx, "connection"); ok {
+		t.Fatal("lease kept after Release")
+	}
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release of a released lock = %v", err)
+	}
+	if err := second.Acquire(nil); err != nil {
+		t.Fatal(err)
+	}
+	defer second.Release()
+	if err := first.Acquire(closed()); !errors.Is(err, state.ErrLockAborted) {
+		t.Fatalf("Acquire of a lock held by another = %v, want ErrLockAborted", err)
+	}
+}
+
+func closed() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is a Store keeping its entries in memory. State does not survive a
+// restart, which makes it suitable for single-instance deployments and
+// tests.
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]entry)}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	e, ok := m.entries[key]
+	m.mu.RUnlock()
+	if !ok || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return append([]byte(nil), e.Value...), true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	m.entries[key] = newEntry(value, ttl)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && !e.expired(time.Now()) {
+		return false, nil
+	}
+	m.entries[key] = newEntry(value, ttl)
+	return true, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	now := time.Now()
+	m.mu.RLock()
+	matches := make(map[string][]byte)
+	for key, e := range m.entries {
+		if strings.HasPrefix(key, prefix) && !e.expired(now) {
+			matches[key] = append([]byte(nil), e.Value...)
+		}
+	}
+	m.mu.RUnlock()
+	// fn is called without holding the lock, so it may use the store
+	for key, value := range matches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune removes expired entries.
+func (m *Memory) prune() {
+	now := time.Now()
+	m.mu.Lock()
+	for key, e := range m.entries {
+		if e.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+	m.mu.Unlock()
+}
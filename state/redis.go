@@ -0,0 +1,93 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// RedisClient is the subset of Redis commands the Redis store needs. Adapt
+// the client library of your choice, such as go-redis, to it; this package
+// does not depend on one.
+type RedisClient interface {
+	// Get returns the value of key, with ok false if the key does not exist.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set sets key with the expiry ttl, or without expiry if ttl is zero.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX sets key like Set if it does not exist, i.e. SET with NX, and
+	// reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Del deletes key.
+	Del(ctx context.Context, key string) error
+	// Scan runs a SCAN iteration step with the MATCH pattern.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) (keys []string, next uint64, err error)
+}
+
+// Redis is a Store backed by Redis, sharing state between instances. All
+// keys are stored under a namespace prefix.
+type Redis struct {
+	client    RedisClient
+	namespace string
+}
+
+// NewRedis returns a Redis store storing keys as namespace + key.
+func NewRedis(client RedisClient, namespace string) *Redis {
+	return &Redis{client: client, namespace: namespace}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return r.client.Get(ctx, r.namespace+key)
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.namespace+key, value, ttl)
+}
+
+func (r *Redis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.namespace+key, value, ttl)
+}
+
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.namespace+key)
+}
+
+func (r *Redis) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	match := escapeGlob(r.namespace+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, match, 100)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			// keys may expire between SCAN and GET
+			value, ok, err := r.client.Get(ctx, key)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := fn(strings.TrimPrefix(key, r.namespace), value); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapes the characters of a SCAN MATCH pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+// Package state provides the storage for coordination state: the dedupe
+// window of stream.WithDedupStore, the resume point of stream.WithCheckpoint,
+// the rule history of admin.Rules and the connection Lock, so operators
+// choose one backend for all of it: Memory, File, Bolt or Redis.
+package state
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a key-value store with expiring entries. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Get returns the value of key. ok is false if the key is not set or has
+	// expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set sets the value of key. A ttl of zero keeps the entry until it is
+	// deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX sets the value of key like Set if the key is not set or has
+	// expired, atomically, and reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes key. Deleting a key which is not set is not an error.
+	Delete(ctx context.Context, key string) error
+	// Scan calls fn for each entry whose key starts with prefix, in no
+	// particular order, until fn returns an error.
+	Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error
+}
+
+// entry is a value with its expiry. A zero Expires never expires.
+type entry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+func newEntry(value []byte, ttl time.Duration) entry {
+	e := entry{Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl)
+	}
+	return e
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
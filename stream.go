@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/admin"
+	"github.com/kalvin807/twitter-v2-stream/broadcast"
+	"github.com/kalvin807/twitter-v2-stream/config"
+	"github.com/kalvin807/twitter-v2-stream/dashboard"
+	"github.com/kalvin807/twitter-v2-stream/health"
+	"github.com/kalvin807/twitter-v2-stream/metrics"
+	"github.com/kalvin807/twitter-v2-stream/shutdown"
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// stopTimeout bounds the wait for the stream to stop on shutdown.
+const stopTimeout = 10 * time.Second
+
+// rotateTimeout bounds the connect attempt of a rotation after the rules
+// changed.
+const rotateTimeout = 30 * time.Second
+
+// tweetColumns are the table columns of Tweets.
+var tweetColumns = []column{{"id", 19}, {"author", 19}, {"tags", 16}, {"text", 0}}
+
+// printTweet prints the Tweet of msg, if any.
+func printTweet(p *printer, msg *stream.StreamData) error {
+	if msg.Tweet == nil {
+		return nil
+	}
+	var tags []string
+	for _, rule := range msg.MatchingRules {
+		tags = append(tags, rule.Tag)
+	}
+	return p.record(msg, msg.Tweet.ID, msg.Tweet.AuthorID, strings.Join(tags, ","), msg.Tweet.Text)
+}
+
+// fieldFlags are the flags selecting the fields and expansions of Tweets.
+type fieldFlags struct {
+	preset, fields, expansions, userFields, mediaFields, placeFields, pollFields *string
+}
+
+func addFieldFlags(flags *flag.FlagSet) fieldFlags {
+	return fieldFlags{
+		preset:      flags.String("preset", "", "fields and expansions preset: "+strings.Join(stream.PresetNames(), ", ")),
+		fields:      flags.String("fields", "", "comma separated Tweet fields, added to the preset"),
+		expansions:  flags.String("expansions", "", "comma separated expansions, added to the preset"),
+		userFields:  flags.String("user-fields", "", "comma separated user fields, added to the preset"),
+		mediaFields: flags.String("media-fields", "", "comma separated media fields, added to the preset"),
+		placeFields: flags.String("place-fields", "", "comma separated place fields, added to the preset"),
+		pollFields:  flags.String("poll-fields", "", "comma separated poll fields, added to the preset"),
+	}
+}
+
+// params returns the parameters selected by the flags.
+func (f fieldFlags) params() (*stream.StreamFilterParams, error) {
+	params := &stream.StreamFilterParams{}
+	if *f.preset != "" {
+		var err error
+		if params, err = stream.Preset(*f.preset); err != nil {
+			return nil, fmt.Errorf("--preset: %w", err)
+		}
+	}
+	params.TweetFields = appendList(params.TweetFields, *f.fields)
+	params.Expansions = appendList(params.Expansions, *f.expansions)
+	params.UserFields = appendList(params.UserFields, *f.userFields)
+	params.MediaFields = appendList(params.MediaFields, *f.mediaFields)
+	params.PlaceFields = appendList(params.PlaceFields, *f.placeFields)
+	params.PollFields = appendList(params.PollFields, *f.pollFields)
+	return params, nil
+}
+
+// appendList appends the values of a comma separated list which are not in
+// values yet.
+func appendList(values []string, list string) []string {
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		found := false
+		for _, existing := range values {
+			found = found || existing == v
+		}
+		if !found {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// runStream implements the stream and sample commands: it prints the
+// Tweets of the filtered stream, or of the 1% sample stream, until
+// interrupted, serving the stream on the admin address, e.g.
+//
+//	twstream stream --fields created_at,lang --backfill 2 --output table
+//
+// The token source, fields, sinks, buffer size and backoffs are read from
+// the environment, see config.FromEnv, or with --config from a
+// configuration file overridden by the environment, which also declares
+// rules. Flags which are set take precedence, and field flags add to the
+// configured fields.
+func runStream(ctx context.Context, client *http.Client, args []string, sample bool) error {
+	name := "stream"
+	if sample {
+		name = "sample"
+	}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	fields := addFieldFlags(flags)
+	backfill := flags.Int("backfill", 0, "minutes of Tweets missed before connecting to recover, up to 5 (Academic Research access)")
+	output := flags.String("output", "id", "output format: "+strings.Join(outputFormats, ", "))
+	buffer := flags.Int("buffer", 0, "size of the Messages channel buffer")
+	addr := flags.String("addr", "127.0.0.1:8080", "address serving metrics, probes, the dashboard and rebroadcasts, empty to disable")
+	archive := flags.String("archive", "", "archive messages as NDJSON files in the directory instead of printing them")
+	archiveSize := flags.Int64("archive-size", 100<<20, "rotate archive files at the size in bytes")
+	archiveAge := flags.Duration("archive-age", time.Hour, "rotate archive files at the age")
+	configPath := flags.String("config", "", "configuration file, TOML or JSON")
+	record := flags.String("record", "", "record the raw stream to the file, appending, for twstream replay")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	params, err := fields.params()
+	if err != nil {
+		return err
+	}
+	params.BackfillMinutes = *backfill
+	out, err := newPrinter(*output, os.Stdout, tweetColumns...)
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	// settings are checked before connecting, so a missing or invalid one
+	// is reported along with all others
+	var cfg *config.Config
+	if *configPath != "" {
+		cfg, err = config.Load(*configPath)
+	} else {
+		cfg, err = config.FromEnv()
+	}
+	if err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	flagParams := params
+	if params, err = cfg.Params(); err != nil {
+		return err
+	}
+	params.TweetFields = appendList(params.TweetFields, strings.Join(flagParams.TweetFields, ","))
+	params.Expansions = appendList(params.Expansions, strings.Join(flagParams.Expansions, ","))
+	params.UserFields = appendList(params.UserFields, strings.Join(flagParams.UserFields, ","))
+	params.MediaFields = appendList(params.MediaFields, strings.Join(flagParams.MediaFields, ","))
+	params.PlaceFields = appendList(params.PlaceFields, strings.Join(flagParams.PlaceFields, ","))
+	params.PollFields = appendList(params.PollFields, strings.Join(flagParams.PollFields, ","))
+	if set["backfill"] {
+		params.BackfillMinutes = *backfill
+	}
+	if !set["buffer"] {
+		*buffer = cfg.Stream.Buffer
+	}
+	if !set["addr"] {
+		*addr = cfg.Stream.Addr
+	}
+	opts := []stream.Option{stream.WithMessagesBuffer(*buffer)}
+	if *record != "" {
+		recording, err := os.OpenFile(*record, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer recording.Close()
+		opts = append(opts, stream.WithRecorder(recording))
+	}
+	v2Service, err := cfg.NewService(ctx, client, opts...)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Rules) > 0 && !sample {
+		resp, err := cfg.SyncRules(ctx, v2Service)
+		if err != nil {
+			return fmt.Errorf("sync rules: %w", err)
+		}
+		if resp != nil && resp.Meta != nil && resp.Meta.Summary != nil {
+			log.Printf("added %d rules", resp.Meta.Summary.Created)
+		}
+	}
+	var v2 *stream.Stream
+	if sample {
+		v2, err = v2Service.ConnectSample(ctx, params)
+	} else {
+		v2, err = v2Service.Connect(ctx, params)
+	}
+	if err != nil {
+		return err
+	}
+	messages := v2.Subscribe(*buffer, stream.OverflowBlock).Messages
+	var sinks sync.WaitGroup
+	counted, err := openSinks(ctx, v2, cfg.Sinks, *buffer, &sinks)
+	if err != nil {
+		v2.Stop()
+		return err
+	}
+	hub := broadcast.NewHub(64)
+	http.HandleFunc("/sse", hub.ServeSSE)
+	http.HandleFunc("/ws", hub.ServeWebSocket)
+	go sink.Pump(v2.Subscribe(16, stream.OverflowDropOldest).Messages, hub, nil)
+
+	consumed := make(chan struct{})
+	if *archive != "" {
+		files, err := sink.NewFile(*archive, "tweets")
+		if err != nil {
+			v2.Stop()
+			return err
+		}
+		files.MaxBytes, files.MaxAge = *archiveSize, *archiveAge
+		archived := sink.NewCounting("archive", files)
+		counted = append(counted, archived)
+		go func() {
+			defer close(consumed)
+			err := sink.Pump(messages, archived, func(msg *stream.StreamData, err error) {
+				log.Println("archive:", err)
+			})
+			if err != nil {
+				log.Println("archive:", err)
+			}
+		}()
+	} else {
+		go func() {
+			defer close(consumed)
+			for msg := range messages {
+				if err := printTweet(out, msg); err != nil {
+					log.Println(err)
+				}
+			}
+		}()
+	}
+	go func() {
+		for err := range v2.Errors {
+			log.Println(err)
+		}
+	}()
+
+	exporter := metrics.NewHandler("twitter")
+	exporter.Add(name, v2)
+	exporter.AddService(name, v2Service)
+	http.Handle("/metrics", exporter)
+	if token := os.Getenv("RULES_ADMIN_TOKEN"); token != "" && !sample {
+		rules := admin.NewRules(v2Service, token)
+		rules.OnChange = func(context.Context) { go rotate(v2) }
+		http.Handle("/rules", rules)
+	}
+	rulesService := v2Service
+	if sample {
+		// the sample stream has no rules
+		rulesService = nil
+	}
+	board := dashboard.New(v2, rulesService)
+	go board.Run()
+	go sink.Pump(v2.Subscribe(16, stream.OverflowDropOldest).Messages, board, nil)
+	http.Handle("/dashboard", board)
+	http.Handle("/dashboard/state", board)
+	checker := health.NewChecker(v2)
+	http.HandleFunc("/healthz", checker.ServeHealthz)
+	http.HandleFunc("/readyz", checker.ServeReadyz)
+	http.HandleFunc(broadcast.GRPCSubscribePath, hub.ServeGRPC)
+	if *addr != "" {
+		server := &http.Server{Addr: *addr, Protocols: new(http.Protocols)}
+		server.Protocols.SetHTTP1(true)
+		// gRPC clients connect with HTTP/2 without TLS
+		server.Protocols.SetUnencryptedHTTP2(true)
+		go server.ListenAndServe()
+	}
+
+	select {
+	case <-ctx.Done():
+		log.Println("shutting down")
+	case <-v2.Done():
+		log.Println("stream terminated:", v2.Err())
+	}
+	stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := v2.Shutdown(stopCtx); err != nil {
+		return fmt.Errorf("stop stream: %w", err)
+	}
+	// the archive is flushed and closed, and the output written, once the
+	// subscription is
+	<-consumed
+	sinks.Wait()
+	report := shutdown.New(v2, counted...)
+	report.Log(nil)
+	if path := os.Getenv("SHUTDOWN_REPORT"); path != "" {
+		if err := report.WriteFile(path); err != nil {
+			log.Println(err)
+		}
+	}
+	return v2.Err()
+}
+
+// openSinks pumps the messages of the stream into the configured sinks,
+// each with its own subscription, until the stream stopped. It returns the
+// sinks counting their messages for the shutdown report.
+func openSinks(ctx context.Context, v2 *stream.Stream, configured []config.Sink, buffer int, wg *sync.WaitGroup) ([]*sink.Counting, error) {
+	opened := make([]sink.Sink, len(configured))
+	for i, c := range configured {
+		s, err := config.OpenSink(c)
+		if err != nil {
+			for _, s := range opened[:i] {
+				s.Close()
+			}
+			return nil, fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+		opened[i] = s
+	}
+	counted := make([]*sink.Counting, len(opened))
+	for i, s := range opened {
+		name := fmt.Sprintf("%s sink", configured[i].Type)
+		if webhook, ok := s.(*sink.Webhook); ok && configured[i].FlushInterval > 0 {
+			go webhook.Run(ctx, time.Duration(configured[i].FlushInterval))
+		}
+		counted[i] = sink.NewCounting(fmt.Sprintf("sinks[%d] %s", i, configured[i].Type), s)
+		messages := v2.Subscribe(buffer, stream.OverflowBlock).Messages
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sink.Pump(messages, counted[i], func(msg *stream.StreamData, err error) {
+				log.Printf("%s: %v", name, err)
+			})
+			if err != nil {
+				log.Printf("%s: %v", name, err)
+			}
+		}()
+	}
+	return counted, nil
+}
+
+// rotate replaces the connection of the stream without a gap, or reconnects
+// it if the app is not allowed a second connection.
+func rotate(s *stream.Stream) {
+	ctx, cancel := context.WithTimeout(context.Background(), rotateTimeout)
+	defer cancel()
+	if err := s.Rotate(ctx); err != nil {
+		log.Println("rotate:", err)
+		s.Reconnect()
+	}
+}
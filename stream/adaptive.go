@@ -0,0 +1,79 @@
+package stream
+
+import "time"
+
+// BackOffPolicy selects how a stream paces reconnects after a disconnect or
+// a 503 Service Unavailable.
+type BackOffPolicy int
+
+const (
+	// BackOffExponential is the exponential backoff Twitter documents,
+	// starting at 5 seconds. Disconnects are retried immediately.
+	BackOffExponential BackOffPolicy = iota
+	// BackOffAdaptive adapts the initial backoff to the recent stability of
+	// the connection: it halves after a long-lived connection and doubles
+	// after one which dropped quickly, converging on the pace the API
+	// currently tolerates. Connections closed by Reconnect or Rotate are
+	// reconnected right away and leave it as is.
+	BackOffAdaptive
+)
+
+// WithBackOffPolicy selects the BackOffPolicy of streams.
+func WithBackOffPolicy(policy BackOffPolicy) Option {
+	return func(c *config) {
+		c.backOffPolicy = policy
+	}
+}
+
+// adaptiveBackOff is an exponential backoff whose initial interval is
+// adjusted by observing how long connections last.
+type adaptiveBackOff struct {
+	min         time.Duration
+	max         time.Duration
+	stableAfter time.Duration
+	initial     time.Duration
+	current     time.Duration
+}
+
+func newAdaptiveBackOff() *adaptiveBackOff {
+	return &adaptiveBackOff{
+		min:         250 * time.Millisecond,
+		max:         320 * time.Second,
+		stableAfter: 10 * time.Minute,
+		initial:     5 * time.Second,
+	}
+}
+
+func (b *adaptiveBackOff) NextBackOff() time.Duration {
+	if b.current == 0 {
+		b.current = b.initial
+	} else {
+		b.current *= 2
+	}
+	if b.current > b.max {
+		b.current = b.max
+	}
+	return b.current
+}
+
+func (b *adaptiveBackOff) Reset() {
+	b.current = 0
+}
+
+// observe adjusts the initial interval to a connection which lasted d: a
+// stable connection halves it, a flapping one doubles it. Only connections
+// lost to the server are observed; those closed by Reconnect or Rotate say
+// nothing about its stability.
+func (b *adaptiveBackOff) observe(d time.Duration) {
+	if d >= b.stableAfter {
+		b.initial /= 2
+	} else {
+		b.initial *= 2
+	}
+	if b.initial < b.min {
+		b.initial = b.min
+	}
+	if b.initial > b.max {
+		b.initial = b.max
+	}
+}
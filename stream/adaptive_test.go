@@ -0,0 +1,74 @@
+package stream_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+func TestAdaptiveBackOff(t *testing.T) {
+	tests := []struct {
+		name string
+		// serverCloses makes the server drop each connection after a
+		// Tweet, instead of the client calling Reconnect
+		serverCloses bool
+		wantWait     time.Duration
+	}{
+		{name: "reconnect requested", wantWait: 0},
+		// a connection dropped right away doubles the initial 5s
+		{name: "server disconnect", serverCloses: true, wantWait: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connects atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				connects.Add(1)
+				w.Write([]byte(`{"data":{"id":"1","text":"hello"}}` + "\r\n"))
+				w.(http.Flusher).Flush()
+				if !tt.serverCloses {
+					<-r.Context().Done()
+				}
+			}))
+			defer srv.Close()
+
+			service := stream.NewStreamService(srv.Client(), "token",
+				stream.WithBaseURL(srv.URL),
+				stream.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+				stream.WithBackOffPolicy(stream.BackOffAdaptive),
+			)
+			s, err := service.Connect(context.Background(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Stop()
+
+			var wait time.Duration
+			for reconnects := 0; reconnects < 3; {
+				select {
+				case <-s.Messages:
+					if !tt.serverCloses {
+						s.Reconnect()
+						reconnects++
+					}
+				case event := <-s.Events:
+					if event.Type == stream.EventBackoff {
+						wait = event.Wait
+						reconnects = 3
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("stuck after %d connections", connects.Load())
+				}
+			}
+			if wait != tt.wantWait {
+				t.Errorf("backoff %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package stream
+
+// Well-known annotation keys, so transforms and the stages consuming their
+// results agree on names.
+const (
+	AnnotationLanguage  = "language"
+	AnnotationSentiment = "sentiment"
+	AnnotationSpamScore = "spam_score"
+	AnnotationGeo       = "geo"
+)
+
+// SetAnnotation attaches the result of a transform to the message under key,
+// replacing any previous value. Annotations are not safe for concurrent use;
+// transforms of the same message are expected to run one after another.
+func (d *StreamData) SetAnnotation(key string, value any) {
+	if d.Annotations == nil {
+		d.Annotations = make(map[string]any)
+	}
+	d.Annotations[key] = value
+}
+
+// Annotation returns the value annotated under key and whether it is set.
+func (d *StreamData) Annotation(key string) (any, bool) {
+	value, ok := d.Annotations[key]
+	return value, ok
+}
+
+// AnnotationString returns the string annotated under key. ok is false if the
+// key is not set or does not hold a string.
+func (d *StreamData) AnnotationString(key string) (value string, ok bool) {
+	value, ok = d.Annotations[key].(string)
+	return value, ok
+}
+
+// AnnotationBool returns the bool annotated under key. ok is false if the key
+// is not set or does not hold a bool.
+func (d *StreamData) AnnotationBool(key string) (value bool, ok bool) {
+	value, ok = d.Annotations[key].(bool)
+	return value, ok
+}
+
+// AnnotationFloat returns the number annotated under key as float64. Any
+// integer or float type is accepted, as are the float64 values of annotations
+// decoded from JSON.
+func (d *StreamData) AnnotationFloat(key string) (float64, bool) {
+	switch v := d.Annotations[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// AnnotationInt returns the number annotated under key as int64. Floats are
+// only accepted if they hold an integral value.
+func (d *StreamData) AnnotationInt(key string) (int64, bool) {
+	switch v := d.Annotations[key].(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// AnnotationAs returns the value annotated under key if it holds a T, e.g.
+// AnnotationAs[*GeoResult](msg, AnnotationGeo) for a transform specific type.
+func AnnotationAs[T any](d *StreamData, key string) (T, bool) {
+	value, ok := d.Annotations[key].(T)
+	return value, ok
+}
@@ -0,0 +1,52 @@
+package stream
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxBackfillMinutes is the longest downtime backfill_minutes can recover.
+const maxBackfillMinutes = 5
+
+// WithAutoBackfill makes streams request backfill_minutes on reconnect,
+// covering the measured downtime up to five minutes, so Tweets missed while
+// disconnected are delivered after reconnecting. Only academic and
+// enterprise access support backfill_minutes; redelivered Tweets may
+// duplicate ones received before the disconnect, unless suppressed
+// WithDedup.
+func WithAutoBackfill() Option {
+	return func(c *config) {
+		c.autoBackfill = true
+	}
+}
+
+// backfillMinutes returns the backfill_minutes covering a downtime since
+// disconnectedAt.
+func backfillMinutes(disconnectedAt time.Time) int {
+	minutes := int(math.Ceil(time.Since(disconnectedAt).Minutes()))
+	if minutes > maxBackfillMinutes {
+		return maxBackfillMinutes
+	}
+	return minutes
+}
+
+// setBackfill sets the backfill_minutes of the stream request for a
+// reconnect after disconnectedAt, or restores the requested parameters if
+// disconnectedAt is zero.
+func (s *Stream) setBackfill(query url.Values, disconnectedAt time.Time) {
+	if !s.config.autoBackfill {
+		return
+	}
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	if !disconnectedAt.IsZero() {
+		q.Set("backfill_minutes", strconv.Itoa(backfillMinutes(disconnectedAt)))
+	}
+	s.reqMu.Lock()
+	s.req.URL.RawQuery = q.Encode()
+	s.reqMu.Unlock()
+}
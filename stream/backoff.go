@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// ErrRetriesExhausted is sent on the Errors channel when a backoff gave up
+// retrying, e.g. after its MaxElapsedTime. The stream stops.
+var ErrRetriesExhausted = errors.New("stream: retries exhausted")
+
+// ErrorClass is a class of failed connect attempts, each paced by its own
+// backoff.
+type ErrorClass int
+
+const (
+	// ClassNetwork are transport errors, such as DNS or TLS failures.
+	ClassNetwork ErrorClass = iota + 1
+	// ClassHTTP are retried HTTP statuses other than rate limits, such as
+	// 503 Service Unavailable.
+	ClassHTTP
+	// ClassRateLimit are 420 Enhance Your Calm and 429 Too Many Requests.
+	ClassRateLimit
+)
+
+// BackOffConfig are the parameters of an exponential backoff. Jitter, from
+// 0 to 1, randomizes each interval by up to that fraction, so reconnecting
+// clients do not retry in lockstep. A zero MaxElapsedTime retries forever.
+type BackOffConfig struct {
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+	Jitter          float64
+}
+
+// New returns a backoff with the parameters.
+func (c BackOffConfig) New() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = c.InitialInterval
+	b.Multiplier = c.Multiplier
+	if b.Multiplier < 1 {
+		b.Multiplier = 1
+	}
+	b.MaxInterval = c.MaxInterval
+	b.MaxElapsedTime = c.MaxElapsedTime
+	b.RandomizationFactor = c.Jitter
+	b.Reset()
+	return b
+}
+
+// DefaultBackOff returns the backoff Twitter recommends for the error class:
+// for network errors 250ms more per attempt up to 16 seconds, for HTTP
+// errors exponentially from 5 seconds up to 320 seconds, and for rate limits
+// exponentially from a minute up to 16 minutes.
+func DefaultBackOff(class ErrorClass) backoff.BackOff {
+	switch class {
+	case ClassNetwork:
+		return newLinearBackOff()
+	case ClassRateLimit:
+		return newAggressiveExponentialBackOff()
+	default:
+		return newExponentialBackOff()
+	}
+}
+
+// WithBackOff paces retries of the error class with backoffs returned by
+// newBackOff, which is called once per stream. For ClassHTTP it takes
+// precedence over WithBackOffPolicy.
+func WithBackOff(class ErrorClass, newBackOff func() backoff.BackOff) Option {
+	return func(c *config) {
+		backOffs := make(map[ErrorClass]func() backoff.BackOff, len(c.backOffs)+1)
+		for k, v := range c.backOffs {
+			backOffs[k] = v
+		}
+		backOffs[class] = newBackOff
+		c.backOffs = backOffs
+	}
+}
+
+// newBackOff returns the backoff of the error class for a new stream.
+func (c *config) newBackOff(class ErrorClass) backoff.BackOff {
+	if newBackOff, ok := c.backOffs[class]; ok {
+		return newBackOff()
+	}
+	if class == ClassHTTP && c.backOffPolicy == BackOffAdaptive {
+		return newAdaptiveBackOff()
+	}
+	return DefaultBackOff(class)
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+)
+
+// checkpointInterval is the least interval between writes of the
+// checkpoint of WithCheckpoint while Tweets are delivered.
+const checkpointInterval = 5 * time.Second
+
+// WithCheckpoint keeps the resume point of streams in store under key: the
+// ID of the last delivered Tweet and when it was delivered, written at most
+// every five seconds and when the stream stops. A stream started with a
+// checkpoint in the store, e.g. by the instance replacing a stopped one,
+// resumes from it: WithAutoBackfill covers the downtime since the
+// checkpoint, and WithGapRecovery searches for the Tweets since its Tweet,
+// as after a reconnect. Combine it with WithDedupStore to suppress the
+// Tweets delivered after the last checkpoint was written. If the store
+// fails, the stream connects without resuming and the error is logged.
+func WithCheckpoint(store state.Store, key string) Option {
+	return func(c *config) {
+		c.checkpointStore = store
+		c.checkpointKey = "checkpoint/" + key
+	}
+}
+
+// checkpoint is the resume point stored by WithCheckpoint.
+type checkpoint struct {
+	TweetID string    `json:"tweet_id"`
+	At      time.Time `json:"at"`
+}
+
+// resume loads the checkpoint of WithCheckpoint, as the last Tweet and
+// disconnect time of the stream.
+func (s *Stream) resume() {
+	store := s.config.checkpointStore
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.req.Context(), dedupStoreTimeout)
+	defer cancel()
+	value, ok, err := store.Get(ctx, s.config.checkpointKey)
+	if err != nil || !ok {
+		if err != nil {
+			s.log(slog.LevelWarn, "stream checkpoint load failed", "error", err)
+		}
+		return
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(value, &cp); err != nil {
+		s.log(slog.LevelWarn, "stream checkpoint is invalid", "error", err)
+		return
+	}
+	s.lastTweetID = cp.TweetID
+	s.checkpointed = cp.TweetID
+	s.resumedAt = cp.At
+}
+
+// checkpoint stores the last delivered Tweet as the checkpoint of
+// WithCheckpoint, unless it is stored already, or one was stored within
+// checkpointInterval and final is false.
+func (s *Stream) checkpoint(final bool) {
+	store := s.config.checkpointStore
+	if store == nil {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(s.checkpointedAt) < checkpointInterval {
+		return
+	}
+	s.statsMu.Lock()
+	id := s.stats.LastTweetID
+	s.statsMu.Unlock()
+	if id == "" || id == s.checkpointed {
+		return
+	}
+	value, _ := json.Marshal(checkpoint{TweetID: id, At: now})
+	// the final checkpoint is written once the stream was stopped
+	ctx, cancel := context.WithTimeout(context.Background(), dedupStoreTimeout)
+	defer cancel()
+	if err := store.Set(ctx, s.config.checkpointKey, value, 0); err != nil {
+		s.log(slog.LevelWarn, "stream checkpoint failed", "error", err, "tweet_id", id)
+		return
+	}
+	s.checkpointed = id
+	s.checkpointedAt = now
+}
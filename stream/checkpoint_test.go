@@ -0,0 +1,86 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+func TestCheckpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		checkpoint   *time.Duration
+		wantBackfill string
+	}{
+		{name: "no checkpoint", wantBackfill: ""},
+		{name: "resumed after 90s", checkpoint: durationPtr(90 * time.Second), wantBackfill: "2"},
+		{name: "resumed after an hour", checkpoint: durationPtr(time.Hour), wantBackfill: "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := state.NewMemory()
+			ctx := context.Background()
+			if tt.checkpoint != nil {
+				value := fmt.Sprintf(`{"tweet_id":"1","at":%q}`, time.Now().Add(-*tt.checkpoint).Format(time.RFC3339Nano))
+				store.Set(ctx, "checkpoint/filtered", []byte(value), 0)
+			}
+			queries := make(chan string, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case queries <- r.URL.Query().Get("backfill_minutes"):
+				default:
+				}
+				w.Write([]byte(`{"data":{"id":"2","text":"resumed"}}` + "\r\n"))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+
+			service := stream.NewStreamService(srv.Client(), "token",
+				stream.WithBaseURL(srv.URL),
+				stream.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+				stream.WithAutoBackfill(),
+				stream.WithCheckpoint(store, "filtered"),
+			)
+			s, err := service.Connect(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := <-queries; got != tt.wantBackfill {
+				t.Errorf("backfill_minutes %q, want %q", got, tt.wantBackfill)
+			}
+			if msg := <-s.Messages; msg.Tweet.ID != "2" {
+				t.Fatalf("delivered Tweet %s, want 2", msg.Tweet.ID)
+			}
+			s.Stop()
+
+			value, ok, err := store.Get(ctx, "checkpoint/filtered")
+			if err != nil || !ok {
+				t.Fatalf("checkpoint not stored: %v", err)
+			}
+			var cp struct {
+				TweetID string    `json:"tweet_id"`
+				At      time.Time `json:"at"`
+			}
+			if err := json.Unmarshal(value, &cp); err != nil {
+				t.Fatal(err)
+			}
+			if cp.TweetID != "2" || time.Since(cp.At) > time.Minute {
+				t.Errorf("checkpoint %+v, want Tweet 2 just now", cp)
+			}
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
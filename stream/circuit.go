@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Defaults of CircuitBreaker.
+const (
+	defaultCircuitFailures      = 5
+	defaultCircuitProbeInterval = 5 * time.Minute
+)
+
+// CircuitBreaker opens the circuit of a stream after repeated failed connect
+// attempts, see WithCircuitBreaker.
+type CircuitBreaker struct {
+	// Failures is the number of consecutive failed connect attempts which
+	// open the circuit, 5 if zero.
+	Failures int
+	// Window, if set, only counts the failed attempts within the window, so
+	// failures spread over a long time do not open the circuit.
+	Window time.Duration
+	// ProbeInterval is the wait between connect attempts while the circuit
+	// is open, 5 minutes if zero.
+	ProbeInterval time.Duration
+	// OnOpen, if set, is called with the error of the last attempt when the
+	// circuit opens, e.g. to page someone.
+	OnOpen func(err error)
+	// OnClose, if set, is called when a probe connects and the circuit
+	// closes.
+	OnClose func()
+}
+
+// WithCircuitBreaker stops a stream from hammering the API once connect
+// attempts keep failing: after Failures consecutive failed attempts, or once
+// the backoff of the failures gives up, the circuit opens, sending an
+// EventCircuitOpen and calling OnOpen. While it is open, the stream makes a
+// single probe attempt every ProbeInterval instead of backing off, and it
+// never gives up with ErrRetriesExhausted. The first probe to connect closes
+// the circuit, sending an EventCircuitClosed and calling OnClose. Statuses
+// which are not retried, such as 401 Unauthorized, still stop the stream.
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	if cb.Failures <= 0 {
+		cb.Failures = defaultCircuitFailures
+	}
+	if cb.ProbeInterval <= 0 {
+		cb.ProbeInterval = defaultCircuitProbeInterval
+	}
+	return func(c *config) {
+		c.circuitBreaker = &cb
+	}
+}
+
+// circuit is the state of the circuit breaker of a Stream, only used by its
+// retry goroutine.
+type circuit struct {
+	*CircuitBreaker
+	failures []time.Time
+	open     bool
+}
+
+// tripCircuit records a failed connect attempt with err and returns the wait
+// before the next attempt: wait, the backoff of the failure, while the
+// circuit is closed, and the probe interval once it is open.
+func (s *Stream) tripCircuit(err error, wait time.Duration) time.Duration {
+	c := s.circuit
+	if c == nil {
+		return wait
+	}
+	if c.open {
+		return c.ProbeInterval
+	}
+	now := time.Now()
+	c.failures = append(c.failures, now)
+	if c.Window > 0 {
+		for len(c.failures) > 0 && now.Sub(c.failures[0]) > c.Window {
+			c.failures = c.failures[1:]
+		}
+	}
+	if len(c.failures) < c.Failures && wait != backoff.Stop {
+		return wait
+	}
+	c.open = true
+	c.failures = nil
+	s.emit(Event{Type: EventCircuitOpen, Err: err, Wait: c.ProbeInterval})
+	if c.OnOpen != nil {
+		c.OnOpen(err)
+	}
+	return c.ProbeInterval
+}
+
+// closeCircuit resets the circuit once the stream connected, closing it if
+// it is open.
+func (s *Stream) closeCircuit() {
+	c := s.circuit
+	if c == nil {
+		return
+	}
+	c.failures = nil
+	if !c.open {
+		return
+	}
+	c.open = false
+	s.emit(Event{Type: EventCircuitClosed})
+	if c.OnClose != nil {
+		c.OnClose()
+	}
+}
@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Defaults of NewHTTPClient.
+const (
+	defaultDialTimeout           = 30 * time.Second
+	defaultTCPKeepAlive          = 30 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+)
+
+// clientConfig holds the settings applied by ClientOptions.
+type clientConfig struct {
+	responseHeaderTimeout time.Duration
+	readTimeout           time.Duration
+}
+
+// ClientOption configures NewHTTPClient.
+type ClientOption func(*clientConfig)
+
+// WithResponseHeaderTimeout sets how long to wait for the status and headers
+// of a response once the request is sent, 30 seconds by default. Zero waits
+// indefinitely.
+func WithResponseHeaderTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.responseHeaderTimeout = timeout
+	}
+}
+
+// WithReadTimeout sets a deadline on every read from a connection, so a
+// connection which receives nothing within timeout fails with a timeout
+// error, even within a TLS record or below a stuck proxy. Twitter sends a
+// keep-alive every 20 seconds, so the timeout should be longer. There is
+// no read deadline by default; streams detect silent connections with
+// WithStallTimeout.
+func WithReadTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.readTimeout = timeout
+	}
+}
+
+// NewHTTPClient returns an http.Client suited to long-lived streams, which
+// NewStreamService uses when passed a nil client. Unlike
+// http.DefaultClient, it has no overall Timeout, which would cut off every
+// stream after the timeout, and its own transport, so tuning
+// http.DefaultTransport elsewhere does not affect it. Connections time out
+// connecting, in the TLS handshake and waiting for response headers, send
+// TCP keep-alives every 30 seconds, and use the proxy of the HTTP_PROXY and
+// HTTPS_PROXY environment variables.
+func NewHTTPClient(opts ...ClientOption) *http.Client {
+	c := clientConfig{responseHeaderTimeout: defaultResponseHeaderTimeout}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	dialer := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: defaultTCPKeepAlive}
+	dial := dialer.DialContext
+	if c.readTimeout > 0 {
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &deadlineConn{Conn: conn, timeout: c.readTimeout}, nil
+		}
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dial,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+			ResponseHeaderTimeout: c.responseHeaderTimeout,
+			IdleConnTimeout:       defaultIdleConnTimeout,
+			MaxIdleConns:          10,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// deadlineConn sets the read deadline of a connection before every read.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	tweetComplianceV2Path = "/2/tweets/compliance/stream"
+	userComplianceV2Path  = "/2/users/compliance/stream"
+)
+
+// CompliancePartitions is the number of partitions of the compliance
+// streams.
+const CompliancePartitions = 4
+
+// Types of ComplianceEvent. Tweet compliance streams send the Tweet types,
+// user compliance streams the user types.
+const (
+	ComplianceDelete            = "delete"
+	ComplianceWithheld          = "withheld"
+	ComplianceDrop              = "drop"
+	ComplianceUndrop            = "undrop"
+	ComplianceScrubGeo          = "scrub_geo"
+	ComplianceUserDelete        = "user_delete"
+	ComplianceUserUndelete      = "user_undelete"
+	ComplianceUserWithheld      = "user_withheld"
+	ComplianceUserProtect       = "user_protect"
+	ComplianceUserUnprotect     = "user_unprotect"
+	ComplianceUserSuspend       = "user_suspend"
+	ComplianceUserUnsuspend     = "user_unsuspend"
+	ComplianceUserProfileChange = "user_profile_modification"
+)
+
+// ComplianceParams are the query parameters of a compliance stream
+// connection. Partition, from 1 to CompliancePartitions, is required.
+type ComplianceParams struct {
+	Partition       int        `url:"partition"`
+	BackfillMinutes int        `url:"backfill_minutes,omitempty"`
+	StartTime       *time.Time `url:"start_time,omitempty"`
+	EndTime         *time.Time `url:"end_time,omitempty"`
+}
+
+// ComplianceEvent is an event of a compliance stream which stored Tweets or
+// user data must be updated for, e.g. by deleting a deleted Tweet. Tweet is
+// set for Tweet events, User for user events.
+type ComplianceEvent struct {
+	Type                string           `json:"-"`
+	EventAt             time.Time        `json:"event_at"`
+	Tweet               *ComplianceTweet `json:"tweet,omitempty"`
+	User                *ComplianceUser  `json:"user,omitempty"`
+	WithheldInCountries []string         `json:"withheld_in_countries,omitempty"`
+}
+
+// ComplianceTweet identifies the Tweet of a ComplianceEvent.
+type ComplianceTweet struct {
+	ID     string          `json:"id"`
+	Author *ComplianceUser `json:"author,omitempty"`
+}
+
+// ComplianceUser identifies the user of a ComplianceEvent.
+type ComplianceUser struct {
+	ID string `json:"id"`
+}
+
+// ComplianceStream receives the events of a compliance stream on Events.
+// It reconnects like a Stream; transport failures, undecodable events and
+// terminal statuses are reported on Errors. Both channels are closed once
+// the stream stopped.
+type ComplianceStream struct {
+	Events <-chan *ComplianceEvent
+	Errors <-chan error
+	stream *Stream
+}
+
+// ConnectTweetCompliance starts a ComplianceStream receiving the Tweet
+// compliance events of a partition.
+func (srv *StreamService) ConnectTweetCompliance(ctx context.Context, params *ComplianceParams) (*ComplianceStream, error) {
+	return srv.connectCompliance(ctx, tweetComplianceV2Path, params)
+}
+
+// ConnectUserCompliance starts a ComplianceStream receiving the user
+// compliance events of a partition.
+func (srv *StreamService) ConnectUserCompliance(ctx context.Context, params *ComplianceParams) (*ComplianceStream, error) {
+	return srv.connectCompliance(ctx, userComplianceV2Path, params)
+}
+
+func (srv *StreamService) connectCompliance(ctx context.Context, path string, params *ComplianceParams) (*ComplianceStream, error) {
+	if params == nil || params.Partition < 1 || params.Partition > CompliancePartitions {
+		partition := 0
+		if params != nil {
+			partition = params.Partition
+		}
+		return nil, &ParameterError{
+			Parameter: "partition",
+			Values:    []string{fmt.Sprint(partition)},
+			Message:   fmt.Sprintf("must be between 1 and %d", CompliancePartitions),
+		}
+	}
+	token, err := srv.currentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(ctx, http.MethodGet, srv.endpoint(path), token, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	s := newStream(ctx, srv.client, req, srv.config)
+	events := make(chan *ComplianceEvent)
+	s.handle = func(data []byte) bool {
+		event, apiErr, err := getComplianceEvent(data)
+		if err != nil {
+			s.decodeFailed(data, err)
+			return true
+		}
+		if apiErr != nil {
+			s.sendError(apiErr)
+		}
+		if event == nil {
+			return true
+		}
+		select {
+		case <-s.done:
+			s.drop("stopped", "event", event.Type)
+			return false
+		case events <- event:
+			s.count(func(stats *Stats) { stats.Delivered++ })
+			return true
+		}
+	}
+	go func() {
+		// Messages is unused, events are closed with it
+		for range s.Messages {
+		}
+		close(events)
+	}()
+	if err := s.start(); err != nil {
+		close(s.Messages)
+		return nil, err
+	}
+	return &ComplianceStream{Events: events, Errors: s.Errors, stream: s}, nil
+}
+
+// Stop stops the stream and blocks until it is done.
+func (c *ComplianceStream) Stop() {
+	c.stream.Stop()
+}
+
+// Shutdown stops the stream, giving up waiting once ctx is done, see
+// Stream.Shutdown.
+func (c *ComplianceStream) Shutdown(ctx context.Context) error {
+	return c.stream.Shutdown(ctx)
+}
+
+// Done returns a channel which is closed once the stream stopped.
+func (c *ComplianceStream) Done() <-chan struct{} {
+	return c.stream.Done()
+}
+
+// Err returns the error which terminated the stream, see Stream.Err.
+func (c *ComplianceStream) Err() error {
+	return c.stream.Err()
+}
+
+// getComplianceEvent decodes a compliance stream message, such as
+//
+//	{"data":{"delete":{"tweet":{"id":"1","author":{"id":"2"}},
+//	 "event_at":"2021-07-06T18:40:40.000Z"}}}
+//
+// The event is nil for in-stream error objects, which are returned as an
+// APIError.
+func getComplianceEvent(data []byte) (*ComplianceEvent, *APIError, error) {
+	var msg struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []*APIErrorDetail          `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, nil, err
+	}
+	var apiErr *APIError
+	if len(msg.Errors) > 0 {
+		apiErr = &APIError{Errors: msg.Errors}
+	}
+	for typ, payload := range msg.Data {
+		event := &ComplianceEvent{Type: typ}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, nil, err
+		}
+		return event, apiErr, nil
+	}
+	return nil, apiErr, nil
+}
@@ -0,0 +1,100 @@
+package stream
+
+import (
+	"context"
+	"time"
+)
+
+const countsV2Path = "/2/tweets/counts"
+
+// Granularities of Tweet counts.
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+)
+
+// CountsService counts the Tweets matching a query over the last seven days,
+// to estimate the volume of a rule before adding it to the filtered stream.
+// It shares the client and authentication of its StreamService.
+type CountsService struct {
+	srv *StreamService
+}
+
+// Counts returns the CountsService of the StreamService.
+func (srv *StreamService) Counts() *CountsService {
+	return &CountsService{srv: srv}
+}
+
+// CountsParams are the query parameters of a counts request. Query uses the
+// rule syntax of the filtered stream. Granularity defaults to hour.
+type CountsParams struct {
+	Query       string     `url:"query"`
+	StartTime   *time.Time `url:"start_time,omitempty"`
+	EndTime     *time.Time `url:"end_time,omitempty"`
+	SinceID     string     `url:"since_id,omitempty"`
+	UntilID     string     `url:"until_id,omitempty"`
+	Granularity string     `url:"granularity,omitempty"`
+	NextToken   string     `url:"next_token,omitempty"`
+}
+
+// Count is the number of matching Tweets within [Start, End).
+type Count struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	TweetCount int       `json:"tweet_count"`
+}
+
+// CountsMeta is the meta object of a counts response.
+type CountsMeta struct {
+	TotalTweetCount int    `json:"total_tweet_count"`
+	NextToken       string `json:"next_token"`
+}
+
+// CountsResponse is a page of counts.
+type CountsResponse struct {
+	Data []*Count   `json:"data"`
+	Meta CountsMeta `json:"meta"`
+	// RateLimit is the rate limit of the counts endpoint as of the
+	// response, nil if it had no rate limit headers.
+	RateLimit *RateLimit `json:"-"`
+}
+
+func (r *CountsResponse) setRateLimit(rateLimit *RateLimit) {
+	r.RateLimit = rateLimit
+}
+
+// Recent returns a page of counts of the Tweets of the last seven days
+// matching params.Query. Pass Meta.NextToken as params.NextToken to request
+// the next page.
+func (c *CountsService) Recent(ctx context.Context, params *CountsParams) (*CountsResponse, error) {
+	countsResp := &CountsResponse{}
+	if err := c.srv.getJSON(ctx, c.srv.endpoint(countsV2Path)+"/"+SearchRecent, params, countsResp); err != nil {
+		return nil, err
+	}
+	return countsResp, nil
+}
+
+// Estimate returns the counts of all pages of params and the total number of
+// matching Tweets, e.g. to check the volume of a rule against the monthly
+// Tweet cap before adding it. params must have a Query.
+func (c *CountsService) Estimate(ctx context.Context, params *CountsParams) ([]*Count, int, error) {
+	if params == nil || params.Query == "" {
+		return nil, 0, &ParameterError{Parameter: "query", Message: "is required"}
+	}
+	page := *params
+	var counts []*Count
+	total := 0
+	for {
+		resp, err := c.Recent(ctx, &page)
+		if err != nil {
+			return nil, 0, err
+		}
+		counts = append(counts, resp.Data...)
+		total += resp.Meta.TotalTweetCount
+		if resp.Meta.NextToken == "" {
+			return counts, total, nil
+		}
+		page.NextToken = resp.Meta.NextToken
+	}
+}
@@ -0,0 +1,39 @@
+package stream
+
+import "encoding/json"
+
+// Decoder unmarshals stream messages, e.g. a faster drop-in replacement for
+// encoding/json such as jsoniter.ConfigFastest or sonic.ConfigDefault.
+type Decoder interface {
+	Unmarshal(data []byte, v any) error
+}
+
+// DecoderFunc adapts an unmarshal function, such as json.Unmarshal or one
+// calling easyjson generated code, to a Decoder.
+type DecoderFunc func(data []byte, v any) error
+
+func (f DecoderFunc) Unmarshal(data []byte, v any) error {
+	return f(data, v)
+}
+
+// WithDecoder decodes the messages of streams with the Decoder instead of
+// encoding/json. The Decoder must honor the encoding/json struct tags of
+// StreamData.
+func WithDecoder(d Decoder) Option {
+	return func(c *config) {
+		c.decoder = d
+	}
+}
+
+// decode decodes a message with the Decoder of the stream, into a pooled
+// message if the stream is configured WithMessagePool.
+func (s *Stream) decode(data []byte) (*StreamData, error) {
+	decoder := s.config.decoder
+	if decoder == nil {
+		decoder = DecoderFunc(json.Unmarshal)
+	}
+	if s.config.messagePool {
+		return getPooledMessage(decoder, data)
+	}
+	return getMessage(decoder, data)
+}
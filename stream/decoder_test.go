@@ -0,0 +1,67 @@
+package stream_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// benchMessage is a filtered stream message with common fields and
+// expansions.
+const benchMessage = `{"data":{"id":"1788000000000000000","text":"Shipping a new release of the stream client today, with pooling and benchmarks #golang https://t.co/abcdef","created_at":"2024-05-08T12:00:00.000Z","author_id":"2244994945","conversation_id":"1788000000000000000","lang":"en","possibly_sensitive":false,"reply_settings":"everyone","edit_history_tweet_ids":["1788000000000000000"],"entities":{"hashtags":[{"start":85,"end":92,"tag":"golang"}],"urls":[{"start":93,"end":116,"url":"https://t.co/abcdef","expanded_url":"https://example.com/release","display_url":"example.com/release"}]},"public_metrics":{"retweet_count":12,"reply_count":3,"like_count":48,"quote_count":1}},"includes":{"users":[{"id":"2244994945","name":"Developers","username":"TwitterDev"}]},"matching_rules":[{"id":"1","tag":"golang"},{"id":"2","tag":"releases"}]}` + "\r\n"
+
+// memoryTransport answers every request with body.
+type memoryTransport struct {
+	body []byte
+}
+
+func (t *memoryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+// BenchmarkDecode measures receiving Tweets from a stream reading an
+// in-memory response, so only reading, decoding and delivering messages.
+func BenchmarkDecode(b *testing.B) {
+	benchmarkDecode(b)
+}
+
+// BenchmarkDecodePooled is BenchmarkDecode WithMessagePool.
+func BenchmarkDecodePooled(b *testing.B) {
+	benchmarkDecode(b, stream.WithMessagePool())
+}
+
+func benchmarkDecode(b *testing.B, opts ...stream.Option) {
+	client := &http.Client{Transport: &memoryTransport{body: bytes.Repeat([]byte(benchMessage), b.N)}}
+	opts = append(opts,
+		stream.WithBaseURL("http://memory"),
+		stream.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	srv := stream.NewStreamService(client, "token", opts...)
+	b.SetBytes(int64(len(benchMessage)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	s, err := srv.Connect(context.Background(), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer s.Stop()
+	for i := 0; i < b.N; i++ {
+		msg := <-s.Messages
+		if msg == nil || msg.Tweet == nil {
+			b.Fatal("message without Tweet")
+		}
+		msg.Release()
+	}
+	b.StopTimer()
+}
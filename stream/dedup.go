@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+)
+
+// dedupStoreTimeout bounds each request to the store of WithDedupStore.
+const dedupStoreTimeout = 2 * time.Second
+
+// WithDedup suppresses Tweets already delivered by the stream, e.g. ones
+// redelivered by backfill or gap recovery after a reconnect. The IDs of the
+// last size Tweets are remembered, each for up to ttl, or until evicted if
+// ttl is zero. Suppressed Tweets are counted in Stats.Duplicates.
+func WithDedup(size int, ttl time.Duration) Option {
+	return func(c *config) {
+		c.dedupSize = size
+		c.dedupTTL = ttl
+	}
+}
+
+// WithDedupStore also suppresses Tweets delivered by any stream sharing the
+// store, e.g. by the instance a deploy replaced, or by this one before a
+// restart. Delivered Tweet IDs are kept in the store for ttl, which should
+// cover the backfill and gap recovery windows. Each Tweet costs a lookup and
+// a write, so the store should be fast, like state.Redis or state.Bolt. If
+// the store fails, Tweets are delivered and the error is logged. Combine it
+// with WithDedup to save the lookups for Tweets this stream delivered.
+func WithDedupStore(store state.Store, ttl time.Duration) Option {
+	return func(c *config) {
+		c.dedupStore = store
+		c.dedupStoreTTL = ttl
+	}
+}
+
+// dedup is an LRU set of recently seen Tweet IDs. It is only used by the
+// stream goroutine.
+type dedup struct {
+	size  int
+	ttl   time.Duration
+	order *list.List
+	seen  map[string]*list.Element
+}
+
+// seenID is an element of the LRU order of a dedup.
+type seenID struct {
+	id     string
+	seenAt time.Time
+}
+
+func newDedup(size int, ttl time.Duration) *dedup {
+	return &dedup{size: size, ttl: ttl, order: list.New(), seen: make(map[string]*list.Element, size)}
+}
+
+// duplicate reports whether id was seen within the TTL, and remembers it.
+func (d *dedup) duplicate(id string, now time.Time) bool {
+	if e, ok := d.seen[id]; ok {
+		entry := e.Value.(*seenID)
+		fresh := d.ttl <= 0 || now.Sub(entry.seenAt) < d.ttl
+		entry.seenAt = now
+		d.order.MoveToFront(e)
+		return fresh
+	}
+	d.seen[id] = d.order.PushFront(&seenID{id: id, seenAt: now})
+	for d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.seen, oldest.Value.(*seenID).id)
+	}
+	return false
+}
+
+// duplicate reports whether msg duplicates a Tweet delivered before, and
+// counts it. Without WithDedup, only Tweets delivered around a Rotate are
+// remembered.
+func (s *Stream) duplicate(msg *StreamData) bool {
+	if msg.Tweet == nil {
+		return false
+	}
+	now := time.Now()
+	d := s.dedup
+	if d == nil {
+		d = s.bridgeDedup(now)
+	}
+	if (d == nil || !d.duplicate(msg.Tweet.ID, now)) && !s.storedDuplicate(msg.Tweet.ID) {
+		return false
+	}
+	s.count(func(stats *Stats) { stats.Duplicates++ })
+	return true
+}
+
+// storedDuplicate reports whether the store of WithDedupStore holds id, and
+// stores it.
+func (s *Stream) storedDuplicate(id string) bool {
+	store := s.config.dedupStore
+	if store == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(s.req.Context(), dedupStoreTimeout)
+	defer cancel()
+	key := "dedup/" + id
+	_, ok, err := store.Get(ctx, key)
+	if err == nil && ok {
+		return true
+	}
+	if err == nil {
+		err = store.Set(ctx, key, nil, s.config.dedupStoreTTL)
+	}
+	if err != nil {
+		s.log(slog.LevelWarn, "stream dedup store failed", "error", err, "tweet_id", id)
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// brokenStore is a state.Store whose reads and writes fail.
+type brokenStore struct {
+	state.Store
+}
+
+func (brokenStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("store unavailable")
+}
+
+func (brokenStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("store unavailable")
+}
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name string
+		sent []string
+		// stored are the Tweets delivered before by another stream
+		stored         []string
+		store          state.Store
+		inMemory       bool
+		wantDelivered  []string
+		wantDuplicates uint64
+	}{
+		{
+			name:          "no dedup",
+			sent:          []string{"1", "1", "2"},
+			wantDelivered: []string{"1", "1", "2"},
+		},
+		{
+			name:           "in memory",
+			sent:           []string{"1", "2", "1"},
+			inMemory:       true,
+			wantDelivered:  []string{"1", "2"},
+			wantDuplicates: 1,
+		},
+		{
+			name:           "delivered by another stream",
+			sent:           []string{"1", "2", "3"},
+			stored:         []string{"2"},
+			store:          state.NewMemory(),
+			wantDelivered:  []string{"1", "3"},
+			wantDuplicates: 1,
+		},
+		{
+			name:           "repeated on the connection",
+			sent:           []string{"1", "2", "1"},
+			store:          state.NewMemory(),
+			wantDelivered:  []string{"1", "2"},
+			wantDuplicates: 1,
+		},
+		{
+			name:          "store failing",
+			sent:          []string{"1", "1"},
+			store:         brokenStore{},
+			wantDelivered: []string{"1", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, id := range tt.stored {
+				tt.store.Set(ctx, "dedup/"+id, nil, time.Hour)
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body strings.Builder
+				for _, id := range append(tt.sent, "end") {
+					fmt.Fprintf(&body, `{"data":{"id":%q,"text":"hello"}}`+"\r\n", id)
+				}
+				w.Write([]byte(body.String()))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+
+			opts := []stream.Option{stream.WithBaseURL(srv.URL), stream.WithLogger(quiet())}
+			if tt.inMemory {
+				opts = append(opts, stream.WithDedup(100, time.Hour))
+			}
+			if tt.store != nil {
+				opts = append(opts, stream.WithDedupStore(tt.store, time.Hour))
+			}
+			s, err := stream.NewStreamService(srv.Client(), "token", opts...).Connect(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Stop()
+			var delivered []string
+			for id := nextTweet(t, s); id != "end"; id = nextTweet(t, s) {
+				delivered = append(delivered, id)
+			}
+			if !reflect.DeepEqual(delivered, tt.wantDelivered) {
+				t.Errorf("delivered %v, want %v", delivered, tt.wantDelivered)
+			}
+			if got := s.Stats().Duplicates; got != tt.wantDuplicates {
+				t.Errorf("%d duplicates, want %d", got, tt.wantDuplicates)
+			}
+			if _, ok := tt.store.(*state.Memory); ok {
+				for _, id := range tt.wantDelivered {
+					if _, ok, _ := tt.store.Get(ctx, "dedup/"+id); !ok {
+						t.Errorf("Tweet %s delivered but not stored", id)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+/*
+Package stream is a client for the Twitter API v2 filtered stream.
+
+A StreamService connects to the stream and manages its rules:
+
+	srv := stream.NewStreamService(nil, token)
+	s, err := srv.Connect(ctx, &stream.StreamFilterParams{
+		TweetFields: []string{stream.TweetFieldCreatedAt, stream.TweetFieldLang},
+	})
+	if err != nil {
+		// handle error
+	}
+	defer s.Stop()
+	for msg := range s.Messages {
+		fmt.Println(msg.Tweet.ID, msg.Tweet.Text)
+	}
+
+The Stream reconnects with the backoff policies Twitter recommends until it
+is stopped or Twitter answers with a status which should not be retried, at
+which point the Messages channel is closed.
+
+# Stability
+
+The exported identifiers of this package follow semantic versioning: they
+are not removed or changed incompatibly within a major version. New fields,
+methods and Options may be added in minor versions.
+*/
+package stream
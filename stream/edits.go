@@ -0,0 +1,100 @@
+package stream
+
+import (
+	"context"
+	"time"
+)
+
+// EditControls tells whether and until when a Tweet can be edited. Request
+// the edit_controls field to receive them.
+type EditControls struct {
+	EditsRemaining int       `json:"edits_remaining"`
+	IsEditEligible bool      `json:"is_edit_eligible"`
+	EditableUntil  time.Time `json:"editable_until"`
+}
+
+// IsEdit reports whether the Tweet is an edit of an earlier Tweet, as told
+// by its edit history. Requires the edit_history_tweet_ids field, which
+// Twitter includes by default.
+func (t *Tweet) IsEdit() bool {
+	return len(t.PriorVersionIDs()) > 0
+}
+
+// PriorVersionIDs returns the IDs of the versions of the Tweet before it,
+// from the original Tweet on, or nil if it is not an edit.
+func (t *Tweet) PriorVersionIDs() []string {
+	for i, id := range t.EditHistoryTweetIDs {
+		if id == t.ID {
+			return t.EditHistoryTweetIDs[:i]
+		}
+	}
+	return nil
+}
+
+// TweetsResponse is the response of a lookup of multiple Tweets.
+type TweetsResponse struct {
+	Data     []*Tweet          `json:"data,omitempty"`
+	Includes *Includes         `json:"includes,omitempty"`
+	Errors   []*APIErrorDetail `json:"errors,omitempty"`
+}
+
+// tweetsParams are the query parameters of a lookup of multiple Tweets.
+type tweetsParams struct {
+	IDs []string `url:"ids,comma"`
+	StreamFilterParams
+}
+
+// LookupTweets returns the Tweets with the IDs, up to 100, with the fields
+// and expansions requested by params. Partition and BackfillMinutes of
+// params are ignored. Tweets which are deleted or not visible to the app are
+// left out of Data and reported in Errors.
+func (srv *StreamService) LookupTweets(ctx context.Context, ids []string, params *StreamFilterParams) (*TweetsResponse, error) {
+	p := tweetsParams{IDs: ids}
+	if params != nil {
+		p.StreamFilterParams = *params
+	}
+	p.Partition = 0
+	p.BackfillMinutes = 0
+	tweetsResp := &TweetsResponse{}
+	if err := srv.getJSON(ctx, srv.endpoint(tweetsV2Path), &p, tweetsResp); err != nil {
+		return nil, err
+	}
+	return tweetsResp, nil
+}
+
+// EditHistory looks up the versions of an edited Tweet before it, e.g. to
+// reconcile an edit arriving on the stream with the versions stored
+// before, with the fields and expansions requested by params. Versions are
+// returned from the original Tweet on; versions which can no longer be
+// looked up are left out. It returns nil for Tweets which are not edits.
+func (srv *StreamService) EditHistory(ctx context.Context, tweet *Tweet, params *StreamFilterParams) (*TweetsResponse, error) {
+	ids := tweet.PriorVersionIDs()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	resp, err := srv.LookupTweets(ctx, ids, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 && len(resp.Errors) > 0 {
+		return nil, &APIError{Errors: resp.Errors}
+	}
+	// order the versions as in the edit history
+	order := make(map[string]int, len(ids))
+	for i, id := range ids {
+		order[id] = i
+	}
+	versions := make([]*Tweet, len(ids))
+	for _, version := range resp.Data {
+		if i, ok := order[version.ID]; ok {
+			versions[i] = version
+		}
+	}
+	resp.Data = resp.Data[:0]
+	for _, version := range versions {
+		if version != nil {
+			resp.Data = append(resp.Data, version)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,107 @@
+package stream
+
+import "strings"
+
+// entities returns the entities of the full text of the Tweet: those of the
+// note Tweet if it is one, as the entities of Text only cover its truncated
+// version.
+func (t *Tweet) entities() *Entities {
+	if t.NoteTweet != nil && t.NoteTweet.Entities != nil {
+		return t.NoteTweet.Entities
+	}
+	if t.Entities == nil {
+		return &Entities{}
+	}
+	return t.Entities
+}
+
+// Hashtags returns the hashtags of the Tweet without the leading '#', in
+// the order of the text. Requires the entities field.
+func (t *Tweet) Hashtags() []string {
+	return tags(t.entities().Hashtags)
+}
+
+// Cashtags returns the cashtags of the Tweet without the leading '$', in
+// the order of the text. Requires the entities field.
+func (t *Tweet) Cashtags() []string {
+	return tags(t.entities().Cashtags)
+}
+
+func tags(entities []*TagEntity) []string {
+	var tags []string
+	for _, entity := range entities {
+		tags = append(tags, entity.Tag)
+	}
+	return tags
+}
+
+// Mentions returns the users mentioned by the Tweet, in the order of the
+// text, resolved to the users of includes. Users which were not included
+// have just their ID and Username. Requires the entities field, and the
+// entities.mentions.username expansion to resolve users.
+func (t *Tweet) Mentions(includes *Includes) []*User {
+	var users []*User
+	for _, mention := range t.entities().Mentions {
+		user := includes.mentioned(mention)
+		if user == nil {
+			user = &User{ID: mention.ID, Username: mention.Username}
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
+// mentioned returns the included user of a mention, or nil.
+func (i *Includes) mentioned(mention *MentionEntity) *User {
+	if i == nil {
+		return nil
+	}
+	for _, user := range i.Users {
+		if (mention.ID != "" && user.ID == mention.ID) || strings.EqualFold(user.Username, mention.Username) {
+			return user
+		}
+	}
+	return nil
+}
+
+// Resolved returns the destination of the URL: UnwoundURL if known, else
+// ExpandedURL, else the shortened URL.
+func (u *URLEntity) Resolved() string {
+	switch {
+	case u.UnwoundURL != "":
+		return u.UnwoundURL
+	case u.ExpandedURL != "":
+		return u.ExpandedURL
+	default:
+		return u.URL
+	}
+}
+
+// URLs returns the links of the Tweet, in the order of the text, with their
+// shortened, expanded and unwound forms; see URLEntity.Resolved. Links to
+// attached media are left out, see MediaKeys. Requires the entities field.
+func (t *Tweet) URLs() []*URLEntity {
+	var urls []*URLEntity
+	for _, url := range t.entities().URLs {
+		if url.MediaKey == "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// MediaKeys returns the keys of the media attached to the Tweet, from its
+// attachments or else the links to media in its entities. See
+// StreamData.MediaFor for the Media objects.
+func (t *Tweet) MediaKeys() []string {
+	if t.Attachments != nil && len(t.Attachments.MediaKeys) > 0 {
+		return t.Attachments.MediaKeys
+	}
+	var keys []string
+	for _, url := range t.entities().URLs {
+		if url.MediaKey != "" {
+			keys = append(keys, url.MediaKey)
+		}
+	}
+	return keys
+}
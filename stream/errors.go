@@ -0,0 +1,265 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Errors returned by Connect when Twitter rejects the first connect attempt.
+// They are wrapped in a StatusError, test for them with errors.Is.
+var (
+	// ErrUnauthorized means the bearer token is invalid.
+	ErrUnauthorized = errors.New("stream: unauthorized")
+	// ErrForbidden means the app is not allowed to use the endpoint, e.g.
+	// because it is not attached to a project.
+	ErrForbidden = errors.New("stream: forbidden")
+	// ErrTooManyConnections means another connection to the stream is open
+	// already. See WithConnectGrace to wait for it to close instead.
+	ErrTooManyConnections = errors.New("stream: too many connections")
+	// ErrNotFound means the endpoint does not exist, usually because of a
+	// wrong base URL or an access level without the endpoint.
+	ErrNotFound = errors.New("stream: endpoint not found, check the base URL and the access level of the app")
+)
+
+// errorsBufferSize is the capacity of the Errors channel of a Stream.
+const errorsBufferSize = 16
+
+// ConnectionError is sent on the Errors channel when a connect attempt failed
+// at the transport level, e.g. on a DNS lookup or TLS handshake failure. The
+// stream retries the connection.
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("stream: connect: %v", e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is sent on the Errors channel when a stream message could not
+// be decoded. The message is skipped.
+type DecodeError struct {
+	Data []byte
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("stream: decode message: %v", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// MessageTooLargeError is sent on the Errors channel when a stream message
+// exceeds the maximum message size. The message is skipped.
+type MessageTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("stream: message of %d bytes exceeds the limit of %d", e.Size, e.Limit)
+}
+
+// APIError is an error payload of the Twitter API, sent as the body of a
+// non-200 response or as an error object within the stream, e.g.
+//
+//	{"title":"ConnectionException","detail":"This stream is currently at the
+//	 maximum allowed connection limit.","connection_issue":"TooManyConnections",
+//	 "type":"https://api.twitter.com/2/problems/streaming-connection"}
+//
+// In-stream error objects only consist of Errors.
+type APIError struct {
+	Title           string            `json:"title,omitempty"`
+	Detail          string            `json:"detail,omitempty"`
+	Type            string            `json:"type,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ConnectionIssue string            `json:"connection_issue,omitempty"`
+	Errors          []*APIErrorDetail `json:"errors,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return "stream: api error: " + e.message()
+}
+
+func (e *APIError) message() string {
+	msg := e.Title
+	if e.Detail != "" {
+		if msg != "" {
+			msg += ": "
+		}
+		msg += e.Detail
+	}
+	for _, detail := range e.Errors {
+		if msg != "" {
+			msg += "; "
+		}
+		msg += detail.String()
+	}
+	return msg
+}
+
+// APIErrorDetail is an entry of the errors array of an APIError. Depending
+// on Type it describes a rejected parameter, a resource which could not be
+// found or a disconnect of the stream.
+type APIErrorDetail struct {
+	Title          string              `json:"title,omitempty"`
+	Detail         string              `json:"detail,omitempty"`
+	Type           string              `json:"type,omitempty"`
+	Message        string              `json:"message,omitempty"`
+	Parameters     map[string][]string `json:"parameters,omitempty"`
+	Parameter      string              `json:"parameter,omitempty"`
+	Value          string              `json:"value,omitempty"`
+	ResourceType   string              `json:"resource_type,omitempty"`
+	ResourceID     string              `json:"resource_id,omitempty"`
+	DisconnectType string              `json:"disconnect_type,omitempty"`
+}
+
+func (d *APIErrorDetail) String() string {
+	switch {
+	case d.Detail != "" && d.Title != "":
+		return d.Title + ": " + d.Detail
+	case d.Detail != "":
+		return d.Detail
+	case d.Message != "":
+		return d.Message
+	default:
+		return d.Title
+	}
+}
+
+// parseAPIError decodes an error payload, returning nil if the body is not
+// one.
+func parseAPIError(body io.Reader) *APIError {
+	apiErr := &APIError{}
+	if err := json.NewDecoder(io.LimitReader(body, 1<<16)).Decode(apiErr); err != nil {
+		return nil
+	}
+	if apiErr.Title == "" && apiErr.Detail == "" && len(apiErr.Errors) == 0 {
+		return nil
+	}
+	return apiErr
+}
+
+// StatusError is sent on the Errors channel, or returned by Connect, when
+// Twitter answered a connect attempt with a status code which is not
+// retried. The stream stops. Err is one of the errors above or an
+// InvalidRequestError, if the status is known. APIError is the decoded body
+// of the response, if Twitter sent one.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Err        error
+	APIError   *APIError
+	// RateLimit is the rate limit of the endpoint as of the response, nil
+	// if it had no rate limit headers.
+	RateLimit *RateLimit
+}
+
+func (e *StatusError) Error() string {
+	var msg string
+	if e.Err != nil {
+		msg = fmt.Sprintf("%v: %s", e.Err, e.Status)
+	} else {
+		msg = fmt.Sprintf("stream: unexpected status %s", e.Status)
+	}
+	if e.APIError != nil && e.Err == nil {
+		msg += ": " + e.APIError.message()
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() []error {
+	var errs []error
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	if e.APIError != nil {
+		errs = append(errs, e.APIError)
+	}
+	return errs
+}
+
+// newStatusError returns the StatusError of a non-200 response, decoding its
+// body.
+func newStatusError(resp *http.Response) *StatusError {
+	err := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		APIError:   parseAPIError(resp.Body),
+		RateLimit:  parseRateLimit(resp),
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		err.Err = ErrUnauthorized
+	case http.StatusForbidden:
+		err.Err = ErrForbidden
+	case http.StatusNotFound:
+		err.Err = ErrNotFound
+	case http.StatusBadRequest:
+		err.Err = newInvalidRequestError(err.APIError)
+	case 420, http.StatusTooManyRequests:
+		if err.APIError != nil && err.APIError.ConnectionIssue == "TooManyConnections" {
+			err.Err = ErrTooManyConnections
+		}
+	}
+	return err
+}
+
+// newInvalidRequestError returns the InvalidRequestError of a 400 Bad Request
+// response, whose error payload lists the rejected parameters, e.g.
+//
+//	{"errors":[{"parameters":{"tweet.fields":["foo"]},"message":"..."}],
+//	 "title":"Invalid Request","detail":"..."}
+func newInvalidRequestError(apiErr *APIError) *InvalidRequestError {
+	if apiErr == nil {
+		return &InvalidRequestError{Detail: "undecodable error response"}
+	}
+	invalid := &InvalidRequestError{Detail: apiErr.Detail}
+	for _, detail := range apiErr.Errors {
+		for name, values := range detail.Parameters {
+			invalid.Errors = append(invalid.Errors, &ParameterError{
+				Parameter: name,
+				Values:    values,
+				Message:   detail.Message,
+			})
+		}
+	}
+	return invalid
+}
+
+// handshakeError returns the StatusError of the response of the first
+// connect attempt and whether Connect should report it, rather than leaving
+// the status to be retried by the goroutine.
+func (s *Stream) handshakeError(resp *http.Response) (*StatusError, bool) {
+	if resp.StatusCode == http.StatusOK {
+		return nil, false
+	}
+	err := newStatusError(resp)
+	policy := s.config.statusPolicy(resp.StatusCode)
+	if policy.Alert != nil {
+		policy.Alert(err)
+	}
+	if policy.Action != StatusRetry {
+		return err, true
+	}
+	// another open connection is only waited for within the grace window
+	return err, errors.Is(err, ErrTooManyConnections) && s.config.connectGrace == 0
+}
+
+// sendError sends err on the Errors channel without blocking. Errors are
+// dropped when the buffer is full, so a consumer which ignores the Errors
+// channel never stalls the stream.
+func (s *Stream) sendError(err error) {
+	select {
+	case s.errs <- err:
+	default:
+	}
+}
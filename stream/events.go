@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventsBufferSize is the capacity of the Events channel of a Stream.
+const eventsBufferSize = 32
+
+// EventType is a transition of the connection of a Stream.
+type EventType int
+
+const (
+	// EventConnecting is a connect attempt.
+	EventConnecting EventType = iota + 1
+	// EventConnected is a connect attempt answered with 200 OK.
+	EventConnected
+	// EventDisconnected is the loss of the connection, or a failed connect
+	// attempt, with the reason in Err.
+	EventDisconnected
+	// EventBackoff is a wait of Wait before the next connect attempt.
+	EventBackoff
+	// EventStallDetected is a connection which received nothing within the
+	// stall timeout and is torn down, see WithStallTimeout.
+	EventStallDetected
+	// EventStopped is the end of the stream, with the error which stopped
+	// it, if any, in Err.
+	EventStopped
+	// EventHeartbeat is a keep-alive received on the connection, only sent
+	// WithHeartbeats.
+	EventHeartbeat
+	// EventCircuitOpen is the opening of the circuit after repeated failed
+	// connect attempts, with the error of the last one in Err and the
+	// interval of the probe attempts in Wait, see WithCircuitBreaker.
+	EventCircuitOpen
+	// EventCircuitClosed is a probe attempt which connected while the
+	// circuit was open.
+	EventCircuitClosed
+	// EventNotice is a Notice received instead of a Tweet, in Err, such as
+	// an operational disconnect notice.
+	EventNotice
+	// EventRotated is the switch to the connection opened by Rotate, which
+	// is followed by its EventConnected.
+	EventRotated
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventConnecting:
+		return "connecting"
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	case EventBackoff:
+		return "backoff"
+	case EventStallDetected:
+		return "stall detected"
+	case EventStopped:
+		return "stopped"
+	case EventHeartbeat:
+		return "heartbeat"
+	case EventCircuitOpen:
+		return "circuit open"
+	case EventCircuitClosed:
+		return "circuit closed"
+	case EventNotice:
+		return "notice"
+	case EventRotated:
+		return "rotated"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a transition of the connection of a Stream, sent on its Events
+// channel.
+type Event struct {
+	Type EventType
+	Time time.Time
+	Err  error
+	Wait time.Duration
+}
+
+func (e Event) String() string {
+	switch {
+	case e.Type == EventBackoff:
+		return fmt.Sprintf("%v %v", e.Type, e.Wait)
+	case e.Type == EventCircuitOpen:
+		return fmt.Sprintf("%v, probing every %v: %v", e.Type, e.Wait, e.Err)
+	case e.Err != nil:
+		return fmt.Sprintf("%v: %v", e.Type, e.Err)
+	default:
+		return e.Type.String()
+	}
+}
+
+// emit sends an event on the Events channel without blocking. Events are
+// dropped when the buffer is full, like errors.
+func (s *Stream) emit(event Event) {
+	event.Time = time.Now()
+	if event.Type == EventHeartbeat {
+		// too frequent to trace, and not a change of the connection state
+		s.logEvent(event)
+		s.send(event)
+		return
+	}
+	switch event.Type {
+	case EventStallDetected, EventNotice, EventRotated:
+		// not a change of the connection state
+	case EventCircuitOpen, EventCircuitClosed:
+		// not a connection state; the attempts go on
+		s.count(func(stats *Stats) { stats.CircuitOpen = event.Type == EventCircuitOpen })
+	default:
+		s.count(func(stats *Stats) {
+			stats.State = event.Type
+			if event.Type == EventConnected {
+				stats.ConnectFailures = 0
+				stats.ConnectedAt = event.Time
+			}
+			if event.Type == EventBackoff {
+				stats.Backoff = event.Wait
+			} else {
+				stats.Backoff = 0
+			}
+		})
+	}
+	attrs := map[string]any{}
+	if event.Err != nil {
+		attrs["error"] = event.Err.Error()
+	}
+	if event.Type == EventBackoff || event.Type == EventCircuitOpen {
+		attrs["wait"] = event.Wait.String()
+	}
+	s.span.AddEvent(event.Type.String(), attrs)
+	s.logEvent(event)
+	s.send(event)
+}
+
+// send sends an event on the Events channel without blocking.
+func (s *Stream) send(event Event) {
+	select {
+	case s.events <- event:
+	default:
+	}
+}
+
+// WithHeartbeats sends an EventHeartbeat on Events for each keep-alive the
+// stream receives, which Twitter sends every 20 seconds, for consumers to
+// check the liveness of the connection.
+func WithHeartbeats() Option {
+	return func(c *config) {
+		c.heartbeats = true
+	}
+}
@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Subscription is a subscriber of the messages of a Stream, with its own
+// buffer and overflow policy. Messages is closed by Unsubscribe, or once the
+// stream stopped.
+type Subscription struct {
+	Messages <-chan *StreamData
+	messages chan *StreamData
+	overflow Overflow
+	dropped  uint64
+	// done is closed by Unsubscribe, unblocking a pending send, before mu
+	// is acquired to close messages
+	done     chan struct{}
+	doneOnce sync.Once
+	mu       sync.Mutex
+	closed   bool
+}
+
+// Dropped returns the number of messages the subscription dropped by its
+// overflow policy.
+func (sub *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+// Subscribe returns a Subscription receiving every message of the stream,
+// buffering up to buffer messages and treating overflows by the policy, so
+// several consumers can share the one connection Twitter allows per token.
+// A subscriber with OverflowBlock holds back all subscribers while it falls
+// behind. Once subscribed, the stream delivers to its subscriptions only, so
+// Messages must not be read. Messages of streams configured WithMessagePool
+// must not be released by subscribers.
+func (s *Stream) Subscribe(buffer int, overflow Overflow) *Subscription {
+	return s.subscribe(buffer, overflow, nil)
+}
+
+// subscribe adds a subscription, first replaying the kept messages for
+// which replay is true. Replayed messages are buffered in addition to the
+// buffer, so replaying never blocks.
+func (s *Stream) subscribe(buffer int, overflow Overflow, replay func(replayed) bool) *Subscription {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	var backlog []*StreamData
+	if replay != nil && s.replay != nil {
+		backlog = s.replay.since(replay)
+	}
+	messages := make(chan *StreamData, buffer+len(backlog))
+	for _, msg := range backlog {
+		messages <- msg
+	}
+	sub := &Subscription{
+		Messages: messages,
+		messages: messages,
+		overflow: overflow,
+		done:     make(chan struct{}),
+	}
+	if s.fanOutStopped {
+		sub.close()
+		return sub
+	}
+	s.subs = append(s.subs, sub)
+	s.fanOutOnce.Do(func() { go s.fanOut() })
+	return sub
+}
+
+// Unsubscribe stops delivering to the subscription and closes its Messages.
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.subMu.Lock()
+	for i, other := range s.subs {
+		if other == sub {
+			s.subs = append(s.subs[:i:i], s.subs[i+1:]...)
+			break
+		}
+	}
+	s.subMu.Unlock()
+	sub.close()
+}
+
+// fanOut delivers the messages of the stream to all subscriptions until the
+// stream stopped, then closes them.
+func (s *Stream) fanOut() {
+	var seq uint64
+	for msg := range s.Messages {
+		seq++
+		msg.seq = seq
+		s.subMu.Lock()
+		if s.replay != nil {
+			s.replay.add(msg, time.Now())
+		}
+		subs := s.subs
+		s.subMu.Unlock()
+		for _, sub := range subs {
+			sub.send(msg)
+		}
+	}
+	s.subMu.Lock()
+	subs := s.subs
+	s.subs = nil
+	s.fanOutStopped = true
+	s.subMu.Unlock()
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+// send delivers msg by the overflow policy of the subscription.
+func (sub *Subscription) send(msg *StreamData) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	switch {
+	case sub.overflow == OverflowDropNewest || sub.overflow == OverflowDropOldest && cap(sub.messages) == 0:
+		select {
+		case sub.messages <- msg:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	case sub.overflow == OverflowDropOldest:
+		for {
+			select {
+			case sub.messages <- msg:
+				return
+			default:
+			}
+			select {
+			case <-sub.messages:
+				atomic.AddUint64(&sub.dropped, 1)
+			default:
+				// the subscriber made room meanwhile
+			}
+		}
+	default:
+		select {
+		case sub.messages <- msg:
+		case <-sub.done:
+		}
+	}
+}
+
+// close closes Messages once no send is pending.
+func (sub *Subscription) close() {
+	sub.doneOnce.Do(func() { close(sub.done) })
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.messages)
+	}
+}
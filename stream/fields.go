@@ -0,0 +1,254 @@
+package stream
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Values of StreamFilterParams.TweetFields.
+const (
+	TweetFieldAttachments         = "attachments"
+	TweetFieldAuthorID            = "author_id"
+	TweetFieldContextAnnotations  = "context_annotations"
+	TweetFieldConversationID      = "conversation_id"
+	TweetFieldArticle             = "article"
+	TweetFieldCardURI             = "card_uri"
+	TweetFieldCreatedAt           = "created_at"
+	TweetFieldDisplayTextRange    = "display_text_range"
+	TweetFieldEditControls        = "edit_controls"
+	TweetFieldEditHistoryTweetIDs = "edit_history_tweet_ids"
+	TweetFieldEntities            = "entities"
+	TweetFieldGeo                 = "geo"
+	TweetFieldID                  = "id"
+	TweetFieldInReplyToUserID     = "in_reply_to_user_id"
+	TweetFieldLang                = "lang"
+	TweetFieldMediaMetadata       = "media_metadata"
+	TweetFieldNoteTweet           = "note_tweet"
+	TweetFieldPossiblySensitive   = "possibly_sensitive"
+	TweetFieldPublicMetrics       = "public_metrics"
+	TweetFieldReferencedTweets    = "referenced_tweets"
+	TweetFieldReplySettings       = "reply_settings"
+	TweetFieldScopes              = "scopes"
+	TweetFieldSource              = "source"
+	TweetFieldText                = "text"
+	TweetFieldWithheld            = "withheld"
+	// The private metrics require user context authentication.
+	TweetFieldNonPublicMetrics = "non_public_metrics"
+	TweetFieldOrganicMetrics   = "organic_metrics"
+	TweetFieldPromotedMetrics  = "promoted_metrics"
+)
+
+// Values of StreamFilterParams.UserFields.
+const (
+	UserFieldCreatedAt         = "created_at"
+	UserFieldDescription       = "description"
+	UserFieldEntities          = "entities"
+	UserFieldID                = "id"
+	UserFieldLocation          = "location"
+	UserFieldMostRecentTweetID = "most_recent_tweet_id"
+	UserFieldName              = "name"
+	UserFieldPinnedTweetID     = "pinned_tweet_id"
+	UserFieldProfileImageURL   = "profile_image_url"
+	UserFieldProtected         = "protected"
+	UserFieldPublicMetrics     = "public_metrics"
+	UserFieldURL               = "url"
+	UserFieldUsername          = "username"
+	UserFieldVerified          = "verified"
+	UserFieldVerifiedType      = "verified_type"
+	UserFieldWithheld          = "withheld"
+)
+
+// Values of StreamFilterParams.MediaFields.
+const (
+	MediaFieldAltText         = "alt_text"
+	MediaFieldDurationMS      = "duration_ms"
+	MediaFieldHeight          = "height"
+	MediaFieldMediaKey        = "media_key"
+	MediaFieldPreviewImageURL = "preview_image_url"
+	MediaFieldPublicMetrics   = "public_metrics"
+	MediaFieldType            = "type"
+	MediaFieldURL             = "url"
+	MediaFieldVariants        = "variants"
+	MediaFieldWidth           = "width"
+	// The private metrics require user context authentication.
+	MediaFieldNonPublicMetrics = "non_public_metrics"
+	MediaFieldOrganicMetrics   = "organic_metrics"
+	MediaFieldPromotedMetrics  = "promoted_metrics"
+)
+
+// Values of StreamFilterParams.PlaceFields.
+const (
+	PlaceFieldContainedWithin = "contained_within"
+	PlaceFieldCountry         = "country"
+	PlaceFieldCountryCode     = "country_code"
+	PlaceFieldFullName        = "full_name"
+	PlaceFieldGeo             = "geo"
+	PlaceFieldID              = "id"
+	PlaceFieldName            = "name"
+	PlaceFieldPlaceType       = "place_type"
+)
+
+// Values of StreamFilterParams.PollFields.
+const (
+	PollFieldDurationMinutes = "duration_minutes"
+	PollFieldEndDatetime     = "end_datetime"
+	PollFieldID              = "id"
+	PollFieldOptions         = "options"
+	PollFieldVotingStatus    = "voting_status"
+)
+
+// Values of StreamFilterParams.Expansions.
+const (
+	ExpansionAttachmentsMediaKeys         = "attachments.media_keys"
+	ExpansionAttachmentsPollIDs           = "attachments.poll_ids"
+	ExpansionAuthorID                     = "author_id"
+	ExpansionEditHistoryTweetIDs          = "edit_history_tweet_ids"
+	ExpansionEntitiesMentionsUsername     = "entities.mentions.username"
+	ExpansionEntitiesNoteMentionsUsername = "entities.note.mentions.username"
+	ExpansionGeoPlaceID                   = "geo.place_id"
+	ExpansionInReplyToUserID              = "in_reply_to_user_id"
+	ExpansionReferencedTweetsID           = "referenced_tweets.id"
+	ExpansionReferencedTweetsIDAuthorID   = "referenced_tweets.id.author_id"
+)
+
+// The values of each field parameter available with app-only
+// authentication, and those requiring user context.
+var (
+	tweetFields = []string{
+		TweetFieldArticle, TweetFieldAttachments, TweetFieldAuthorID, TweetFieldCardURI,
+		TweetFieldContextAnnotations, TweetFieldConversationID, TweetFieldCreatedAt,
+		TweetFieldDisplayTextRange, TweetFieldEditControls, TweetFieldEditHistoryTweetIDs,
+		TweetFieldEntities, TweetFieldGeo, TweetFieldID, TweetFieldInReplyToUserID, TweetFieldLang,
+		TweetFieldMediaMetadata, TweetFieldNoteTweet, TweetFieldPossiblySensitive,
+		TweetFieldPublicMetrics, TweetFieldReferencedTweets, TweetFieldReplySettings, TweetFieldScopes,
+		TweetFieldSource, TweetFieldText, TweetFieldWithheld,
+	}
+	userFields = []string{
+		UserFieldCreatedAt, UserFieldDescription, UserFieldEntities, UserFieldID, UserFieldLocation,
+		UserFieldMostRecentTweetID, UserFieldName, UserFieldPinnedTweetID, UserFieldProfileImageURL,
+		UserFieldProtected, UserFieldPublicMetrics, UserFieldURL, UserFieldUsername, UserFieldVerified,
+		UserFieldVerifiedType, UserFieldWithheld,
+	}
+	mediaFields = []string{
+		MediaFieldAltText, MediaFieldDurationMS, MediaFieldHeight, MediaFieldMediaKey,
+		MediaFieldPreviewImageURL, MediaFieldPublicMetrics, MediaFieldType, MediaFieldURL,
+		MediaFieldVariants, MediaFieldWidth,
+	}
+	placeFields = []string{
+		PlaceFieldContainedWithin, PlaceFieldCountry, PlaceFieldCountryCode, PlaceFieldFullName,
+		PlaceFieldGeo, PlaceFieldID, PlaceFieldName, PlaceFieldPlaceType,
+	}
+	pollFields = []string{
+		PollFieldDurationMinutes, PollFieldEndDatetime, PollFieldID, PollFieldOptions, PollFieldVotingStatus,
+	}
+	expansions = []string{
+		ExpansionAttachmentsMediaKeys, ExpansionAttachmentsPollIDs, ExpansionAuthorID,
+		ExpansionEditHistoryTweetIDs, ExpansionEntitiesMentionsUsername,
+		ExpansionEntitiesNoteMentionsUsername, ExpansionGeoPlaceID, ExpansionInReplyToUserID,
+		ExpansionReferencedTweetsID, ExpansionReferencedTweetsIDAuthorID,
+	}
+	privateMetrics = []string{TweetFieldNonPublicMetrics, TweetFieldOrganicMetrics, TweetFieldPromotedMetrics}
+)
+
+// AllTweetFields returns the Tweet fields available with app-only
+// authentication, leaving out the private metrics.
+func AllTweetFields() []string {
+	return append([]string(nil), tweetFields...)
+}
+
+// AllUserFields returns the user fields.
+func AllUserFields() []string {
+	return append([]string(nil), userFields...)
+}
+
+// AllMediaFields returns the media fields available with app-only
+// authentication, leaving out the private metrics.
+func AllMediaFields() []string {
+	return append([]string(nil), mediaFields...)
+}
+
+// AllPlaceFields returns the place fields.
+func AllPlaceFields() []string {
+	return append([]string(nil), placeFields...)
+}
+
+// AllPollFields returns the poll fields.
+func AllPollFields() []string {
+	return append([]string(nil), pollFields...)
+}
+
+// AllExpansions returns the expansions.
+func AllExpansions() []string {
+	return append([]string(nil), expansions...)
+}
+
+// DefaultExpansions returns the expansions most consumers want: the authors
+// of Tweets, the Tweets they reply to, quote or retweet, and attached media.
+func DefaultExpansions() []string {
+	return []string{ExpansionAuthorID, ExpansionReferencedTweetsID, ExpansionAttachmentsMediaKeys}
+}
+
+// WithStrictFields makes Connect fail with a ParameterError on fields and
+// expansions this package does not know, e.g. misspelled ones, instead of
+// logging them and connecting.
+func WithStrictFields() Option {
+	return func(c *config) {
+		c.strictFields = true
+	}
+}
+
+// knownValues are the values Twitter accepts for each parameter.
+var knownValues = map[string][]string{
+	"expansions":   expansions,
+	"media.fields": append(append([]string(nil), mediaFields...), privateMetrics...),
+	"place.fields": placeFields,
+	"poll.fields":  pollFields,
+	"tweet.fields": append(append([]string(nil), tweetFields...), privateMetrics...),
+	"user.fields":  userFields,
+}
+
+// unknownValues returns the ParameterError of the values of a parameter
+// which Twitter does not accept, or nil, suggesting the known value a value
+// differs from only by case or separators.
+func unknownValues(parameter string, values []string) *ParameterError {
+	var unknown, suggestions []string
+	for _, value := range values {
+		if contains(knownValues[parameter], value) {
+			continue
+		}
+		unknown = append(unknown, value)
+		for _, known := range knownValues[parameter] {
+			if normalizeField(known) == normalizeField(value) {
+				suggestions = append(suggestions, fmt.Sprintf("%q for %q", known, value))
+				break
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	msg := "unknown values"
+	if len(suggestions) > 0 {
+		msg += ", did you mean " + strings.Join(suggestions, ", ")
+	} else {
+		known := append([]string(nil), knownValues[parameter]...)
+		sort.Strings(known)
+		msg += ", want one of " + strings.Join(known, ", ")
+	}
+	return &ParameterError{Parameter: parameter, Values: unknown, Message: msg}
+}
+
+// normalizeField returns a field name lowercased and without separators.
+func normalizeField(name string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package stream
+
+import "strings"
+
+// Filter reports whether to deliver a message.
+type Filter func(msg *StreamData) bool
+
+// WithFilter runs the Filter on each message before delivery and skips the
+// messages it rejects, counting them in Stats.Filtered. Filters of several
+// WithFilter options all have to accept a message, and run in order.
+func WithFilter(f Filter) Option {
+	return func(c *config) {
+		c.filters = append(c.filters[:len(c.filters):len(c.filters)], f)
+	}
+}
+
+// accept reports whether all filters of the stream accept msg, and counts
+// rejected messages.
+func (s *Stream) accept(msg *StreamData) bool {
+	for _, f := range s.config.filters {
+		if !f(msg) {
+			s.count(func(stats *Stats) { stats.Filtered++ })
+			return false
+		}
+	}
+	return true
+}
+
+// LangFilter accepts Tweets in one of the languages, e.g. "en". Tweets
+// without a lang field, which needs the lang Tweet field, are rejected.
+func LangFilter(langs ...string) Filter {
+	set := stringSet(langs)
+	return func(msg *StreamData) bool {
+		return msg.Tweet != nil && set[msg.Tweet.Lang]
+	}
+}
+
+// AuthorFilter accepts Tweets by one of the author IDs. It needs the
+// author_id Tweet field.
+func AuthorFilter(authorIDs ...string) Filter {
+	set := stringSet(authorIDs)
+	return func(msg *StreamData) bool {
+		return msg.Tweet != nil && set[msg.Tweet.AuthorID]
+	}
+}
+
+// ExcludeAuthors rejects Tweets by one of the author IDs. It needs the
+// author_id Tweet field.
+func ExcludeAuthors(authorIDs ...string) Filter {
+	set := stringSet(authorIDs)
+	return func(msg *StreamData) bool {
+		return msg.Tweet == nil || !set[msg.Tweet.AuthorID]
+	}
+}
+
+// ExcludeKeywords rejects Tweets whose text contains one of the keywords,
+// ignoring case.
+func ExcludeKeywords(keywords ...string) Filter {
+	lower := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		lower[i] = strings.ToLower(keyword)
+	}
+	return func(msg *StreamData) bool {
+		if msg.Tweet == nil {
+			return true
+		}
+		text := strings.ToLower(msg.Tweet.Text)
+		for _, keyword := range lower {
+			if strings.Contains(text, keyword) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,108 @@
+package stream
+
+import "fmt"
+
+// PayloadGap classifies malformed messages Twitter occasionally sends during
+// API hiccups.
+type PayloadGap int
+
+const (
+	// GapMissingData is a message without a Tweet or without a Tweet ID, e.g.
+	// only matching rules.
+	GapMissingData PayloadGap = iota + 1
+	// GapIncompleteData is a message whose Tweet has an ID but no text.
+	GapIncompleteData
+	// GapMissingRules is a message of the filtered stream whose Tweet has no
+	// matching rules.
+	GapMissingRules
+)
+
+func (g PayloadGap) String() string {
+	switch g {
+	case GapMissingData:
+		return "missing data"
+	case GapIncompleteData:
+		return "incomplete data"
+	case GapMissingRules:
+		return "missing matching rules"
+	default:
+		return fmt.Sprintf("PayloadGap(%d)", int(g))
+	}
+}
+
+// PayloadGapError is sent on the Errors channel for each malformed message,
+// with the raw line in Data. Messages missing or with incomplete data are not
+// delivered, unless the Tweet was looked up, see WithGapLookup; Recovered is
+// set then. Messages missing matching rules are delivered as received.
+type PayloadGapError struct {
+	Gap       PayloadGap
+	TweetID   string
+	Data      []byte
+	Recovered bool
+	// LookupErr is the error of the Tweet lookup, if one failed.
+	LookupErr error
+}
+
+func (e *PayloadGapError) Error() string {
+	msg := "stream: malformed message: " + e.Gap.String()
+	if e.TweetID != "" {
+		msg += " of Tweet " + e.TweetID
+	}
+	switch {
+	case e.Recovered:
+		msg += ", recovered by lookup"
+	case e.LookupErr != nil:
+		msg += fmt.Sprintf(", lookup failed: %v", e.LookupErr)
+	}
+	return msg
+}
+
+func (e *PayloadGapError) Unwrap() error {
+	return e.LookupErr
+}
+
+// WithGapLookup makes streams look up the Tweets of messages with
+// incomplete data by their ID and deliver the looked up Tweet instead.
+func WithGapLookup() Option {
+	return func(c *config) {
+		c.gapLookup = true
+	}
+}
+
+// payloadGap classifies a malformed message.
+func (s *Stream) payloadGap(msg *StreamData) (PayloadGap, bool) {
+	switch {
+	case msg.Tweet == nil || msg.Tweet.ID == "":
+		return GapMissingData, true
+	case msg.Tweet.Text == "" && msg.Tweet.NoteTweet == nil:
+		return GapIncompleteData, true
+	case s.filtered && len(msg.MatchingRules) == 0:
+		return GapMissingRules, true
+	}
+	return 0, false
+}
+
+// handleGap reports a malformed message, looking up its Tweet if enabled,
+// and reports whether to deliver it.
+func (s *Stream) handleGap(msg *StreamData, gap PayloadGap, data []byte) bool {
+	gapErr := &PayloadGapError{Gap: gap, Data: append([]byte(nil), data...)}
+	if msg.Tweet != nil {
+		gapErr.TweetID = msg.Tweet.ID
+	}
+	deliver := gap == GapMissingRules
+	if gap == GapIncompleteData && s.config.gapLookup && s.srv != nil {
+		resp, err := s.srv.LookupTweet(s.req.Context(), msg.Tweet.ID, s.params)
+		if err == nil {
+			msg.Tweet = resp.Data
+			if resp.Includes != nil {
+				msg.Includes = resp.Includes
+			}
+			gapErr.Recovered = true
+			deliver = true
+		} else {
+			gapErr.LookupErr = err
+		}
+	}
+	s.sendError(gapErr)
+	return deliver
+}
@@ -0,0 +1,159 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Handler processes a stream message. Handlers should return promptly once
+// ctx is done.
+type Handler func(ctx context.Context, msg *StreamData) error
+
+// ErrHandlerTimeout is returned for messages whose handler did not finish in
+// time and whose TimeoutPolicy gave up on them.
+var ErrHandlerTimeout = errors.New("stream: handler timed out")
+
+// TimeoutPolicy decides what happens to a message whose handler timed out.
+type TimeoutPolicy int
+
+const (
+	// TimeoutSkip drops the message.
+	TimeoutSkip TimeoutPolicy = iota
+	// TimeoutRetry runs the handler again, up to MaxRetries times, and
+	// returns ErrHandlerTimeout once all attempts timed out.
+	TimeoutRetry
+	// TimeoutDeadLetter passes the message to the DeadLetter function.
+	TimeoutDeadLetter
+)
+
+// HandlerTimeout bounds the time a Handler may spend on a message, so one
+// hung call, e.g. to an enrichment service, cannot stall the consumers of a
+// stream indefinitely. A handler which ignores its context keeps running in
+// the background after it timed out, but no longer blocks the next message;
+// under TimeoutRetry the next attempt waits up to another Timeout for it to
+// return, and the message fails with ErrHandlerTimeout if it does not. Pass
+// it to Handle with WithHandlerTimeout, which counts timeouts in Stats and
+// releases pooled messages only once abandoned runs returned.
+type HandlerTimeout struct {
+	Timeout    time.Duration
+	Policy     TimeoutPolicy
+	MaxRetries int
+	// DeadLetter receives messages timed out under TimeoutDeadLetter.
+	DeadLetter func(msg *StreamData, err error)
+
+	timedOut     uint64
+	retried      uint64
+	skipped      uint64
+	deadLettered uint64
+}
+
+// HandlerTimeoutStats counts the timeouts of a HandlerTimeout.
+type HandlerTimeoutStats struct {
+	TimedOut     uint64
+	Retried      uint64
+	Skipped      uint64
+	DeadLettered uint64
+}
+
+// Stats returns a snapshot of the timeout counters.
+func (t *HandlerTimeout) Stats() HandlerTimeoutStats {
+	return HandlerTimeoutStats{
+		TimedOut:     atomic.LoadUint64(&t.timedOut),
+		Retried:      atomic.LoadUint64(&t.retried),
+		Skipped:      atomic.LoadUint64(&t.skipped),
+		DeadLettered: atomic.LoadUint64(&t.deadLettered),
+	}
+}
+
+// Wrap returns a Handler running h with the timeout and policy, recovering
+// panics as a PanicError. The message may still be in use by an abandoned
+// run when the Handler returns; use WithHandlerTimeout rather than Wrap for
+// streams configured WithMessagePool.
+func (t *HandlerTimeout) Wrap(h Handler) Handler {
+	return func(ctx context.Context, msg *StreamData) error {
+		_, _, err := t.handle(ctx, h, msg)
+		return err
+	}
+}
+
+// handle runs h on msg with the timeout and policy. It returns a channel
+// closed once the last run returned if it was abandoned, else nil, and the
+// number of runs which timed out.
+func (t *HandlerTimeout) handle(ctx context.Context, h Handler, msg *StreamData) (<-chan struct{}, int, error) {
+	attempts := 1
+	if t.Policy == TimeoutRetry {
+		attempts += t.MaxRetries
+	}
+	var pending <-chan struct{}
+	for i := 0; i < attempts; i++ {
+		if i > 0 && pending != nil {
+			// never run the handler twice on the message at once, and give
+			// up on a handler ignoring its context
+			wait := time.NewTimer(t.Timeout)
+			select {
+			case <-pending:
+				wait.Stop()
+			case <-wait.C:
+				return pending, i, ErrHandlerTimeout
+			case <-ctx.Done():
+				wait.Stop()
+				return pending, i, ctx.Err()
+			}
+		}
+		if i > 0 {
+			atomic.AddUint64(&t.retried, 1)
+		}
+		var err error
+		pending, err = t.run(ctx, h, msg)
+		if !errors.Is(err, ErrHandlerTimeout) {
+			return pending, i, err
+		}
+		atomic.AddUint64(&t.timedOut, 1)
+	}
+	switch t.Policy {
+	case TimeoutSkip:
+		atomic.AddUint64(&t.skipped, 1)
+		return pending, attempts, nil
+	case TimeoutDeadLetter:
+		atomic.AddUint64(&t.deadLettered, 1)
+		if t.DeadLetter != nil {
+			t.DeadLetter(msg, ErrHandlerTimeout)
+		}
+		return pending, attempts, nil
+	default:
+		return pending, attempts, ErrHandlerTimeout
+	}
+}
+
+// run runs h with the timeout. It returns ErrHandlerTimeout if the handler
+// did not finish in time, unless ctx itself is done; the run is then
+// abandoned, and the returned channel is closed once it returns.
+func (t *HandlerTimeout) run(ctx context.Context, h Handler, msg *StreamData) (<-chan struct{}, error) {
+	if t.Timeout <= 0 {
+		return nil, callHandler(ctx, h, msg)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+	result := make(chan error, 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		result <- callHandler(timeoutCtx, h, msg)
+	}()
+	select {
+	case err := <-result:
+		if err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			// the handler gave up on its deadline before the timeout was
+			// noticed
+			return nil, ErrHandlerTimeout
+		}
+		return nil, err
+	case <-timeoutCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return returned, err
+		}
+		return returned, ErrHandlerTimeout
+	}
+}
@@ -0,0 +1,125 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+var errHandler = errors.New("handler failed")
+
+// hangs returns a Handler which blocks until its context is done on the
+// calls for which hang returns true, and succeeds on the others.
+func hangs(hang func(call int) bool) stream.Handler {
+	var calls atomic.Int32
+	return func(ctx context.Context, msg *stream.StreamData) error {
+		if hang(int(calls.Add(1))) {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	always := func(int) bool { return true }
+	tests := []struct {
+		name           string
+		policy         stream.TimeoutPolicy
+		maxRetries     int
+		handler        stream.Handler
+		wantErr        error
+		wantStats      stream.HandlerTimeoutStats
+		wantDeadLetter bool
+	}{
+		{
+			name:    "in time",
+			handler: func(ctx context.Context, msg *stream.StreamData) error { return nil },
+		},
+		{
+			name:    "handler error",
+			handler: func(ctx context.Context, msg *stream.StreamData) error { return errHandler },
+			wantErr: errHandler,
+		},
+		{
+			name:      "skip",
+			policy:    stream.TimeoutSkip,
+			handler:   hangs(always),
+			wantStats: stream.HandlerTimeoutStats{TimedOut: 1, Skipped: 1},
+		},
+		{
+			name:       "retry succeeds",
+			policy:     stream.TimeoutRetry,
+			maxRetries: 2,
+			handler:    hangs(func(call int) bool { return call == 1 }),
+			wantStats:  stream.HandlerTimeoutStats{TimedOut: 1, Retried: 1},
+		},
+		{
+			name:       "retries exhausted",
+			policy:     stream.TimeoutRetry,
+			maxRetries: 2,
+			handler:    hangs(always),
+			wantErr:    stream.ErrHandlerTimeout,
+			wantStats:  stream.HandlerTimeoutStats{TimedOut: 3, Retried: 2},
+		},
+		{
+			name:       "retry of a handler ignoring its context",
+			policy:     stream.TimeoutRetry,
+			maxRetries: 2,
+			handler: func(ctx context.Context, msg *stream.StreamData) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+			wantErr:   stream.ErrHandlerTimeout,
+			wantStats: stream.HandlerTimeoutStats{TimedOut: 1},
+		},
+		{
+			name:           "dead letter",
+			policy:         stream.TimeoutDeadLetter,
+			handler:        hangs(always),
+			wantStats:      stream.HandlerTimeoutStats{TimedOut: 1, DeadLettered: 1},
+			wantDeadLetter: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &stream.StreamData{Tweet: &stream.Tweet{ID: "1"}}
+			var deadLettered *stream.StreamData
+			timeout := &stream.HandlerTimeout{
+				Timeout:    20 * time.Millisecond,
+				Policy:     tt.policy,
+				MaxRetries: tt.maxRetries,
+				DeadLetter: func(msg *stream.StreamData, err error) {
+					if !errors.Is(err, stream.ErrHandlerTimeout) {
+						t.Errorf("dead-lettered with %v, want ErrHandlerTimeout", err)
+					}
+					deadLettered = msg
+				},
+			}
+			if err := timeout.Wrap(tt.handler)(context.Background(), msg); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := timeout.Stats(); got != tt.wantStats {
+				t.Errorf("stats %+v, want %+v", got, tt.wantStats)
+			}
+			if (deadLettered == msg) != tt.wantDeadLetter {
+				t.Errorf("dead-lettered %v, want %v", deadLettered != nil, tt.wantDeadLetter)
+			}
+		})
+	}
+}
+
+func TestHandlerTimeoutPanic(t *testing.T) {
+	timeout := &stream.HandlerTimeout{Timeout: time.Second}
+	err := timeout.Wrap(func(ctx context.Context, msg *stream.StreamData) error {
+		panic("enrichment failed")
+	})(context.Background(), &stream.StreamData{})
+	var panicErr *stream.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "enrichment failed" {
+		t.Errorf("err = %v, want a PanicError", err)
+	}
+}
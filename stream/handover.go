@@ -0,0 +1,56 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+)
+
+// connectGraceInterval is the pause between connect attempts while another
+// connection is still holding the stream within the connect grace window.
+const connectGraceInterval = 2 * time.Second
+
+// ConnectionLock coordinates the single connection Twitter allows per app
+// between instances, e.g. the old and new pods of a rolling deploy. A stream
+// acquires the lock before its first connect attempt and releases it once it
+// has stopped. state.Lock implements it on a state.Store shared by the
+// instances, such as state.Redis:
+//
+//	lock, err := state.NewLock(store, "connection", 30*time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	service := stream.NewStreamService(client, token, stream.WithConnectionLock(lock))
+type ConnectionLock interface {
+	// Acquire blocks until the lock is held or the done channel is closed.
+	Acquire(done <-chan struct{}) error
+	// Release gives up the lock.
+	Release() error
+}
+
+var _ ConnectionLock = (*state.Lock)(nil)
+
+// WithConnectionLock makes streams wait for the lock before connecting, so a
+// new instance only connects after the old one released the connection.
+func WithConnectionLock(lock ConnectionLock) Option {
+	return func(c *config) {
+		c.connectionLock = lock
+	}
+}
+
+// WithConnectGrace retries rate limited connect attempts every few seconds
+// for the given window after a stream starts, instead of falling back to the
+// aggressive backoff. During a deploy the old instance usually releases its
+// connection within seconds, so this avoids waiting minutes for the new one.
+func WithConnectGrace(window time.Duration) Option {
+	return func(c *config) {
+		c.connectGrace = window
+	}
+}
+
+// inConnectGrace reports whether a rate limited connect attempt should be
+// retried quickly because the stream has not connected yet and is still
+// within its connect grace window.
+func (s *Stream) inConnectGrace(start time.Time, connected bool) bool {
+	return !connected && s.config.connectGrace > 0 && time.Since(start) < s.config.connectGrace
+}
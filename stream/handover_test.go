@@ -0,0 +1,156 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/state"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// tooManyConnections is the body of the 429 Twitter answers while another
+// connection of the app is open.
+const tooManyConnections = `{"title":"ConnectionException","detail":"This stream is currently at the maximum allowed connection limit.","connection_issue":"TooManyConnections","type":"https://api.twitter.com/2/problems/streaming-connection"}`
+
+func quiet() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// nextTweet returns the ID of the next Tweet delivered on s.
+func nextTweet(t *testing.T, s *stream.Stream) string {
+	t.Helper()
+	select {
+	case msg, ok := <-s.Messages:
+		if !ok {
+			t.Fatal("stream stopped")
+		}
+		return msg.Tweet.ID
+	case <-time.After(5 * time.Second):
+		t.Fatal("no Tweet delivered")
+	}
+	return ""
+}
+
+func TestConnectGrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		grace    time.Duration
+		wantErr  error
+		wantWait time.Duration
+	}{
+		{name: "no grace", wantErr: stream.ErrTooManyConnections},
+		{name: "released within grace", grace: time.Minute, wantWait: 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					// the old instance still holds the connection
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(tooManyConnections))
+					return
+				}
+				w.Write([]byte(`{"data":{"id":"1","text":"hello"}}` + "\r\n"))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+
+			service := stream.NewStreamService(srv.Client(), "token",
+				stream.WithBaseURL(srv.URL),
+				stream.WithLogger(quiet()),
+				stream.WithConnectGrace(tt.grace),
+			)
+			s, err := service.Connect(context.Background(), nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Connect: err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer s.Stop()
+			if id := nextTweet(t, s); id != "1" {
+				t.Fatalf("delivered Tweet %s, want 1", id)
+			}
+			var wait time.Duration
+			for len(s.Events) > 0 {
+				if event := <-s.Events; event.Type == stream.EventBackoff {
+					wait = event.Wait
+				}
+			}
+			if wait != tt.wantWait {
+				t.Errorf("waited %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestConnectionLock(t *testing.T) {
+	var open, maxOpen atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := open.Add(1)
+		defer open.Add(-1)
+		for m := maxOpen.Load(); n > m && !maxOpen.CompareAndSwap(m, n); m = maxOpen.Load() {
+		}
+		w.Write([]byte(`{"data":{"id":"1","text":"hello"}}` + "\r\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	store := state.NewMemory()
+	connect := func() (*stream.Stream, error) {
+		lock, err := state.NewLock(store, "connection", time.Second)
+		if err != nil {
+			return nil, err
+		}
+		service := stream.NewStreamService(srv.Client(), "token",
+			stream.WithBaseURL(srv.URL),
+			stream.WithLogger(quiet()),
+			stream.WithConnectionLock(lock),
+		)
+		return service.Connect(context.Background(), nil)
+	}
+
+	old, err := connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextTweet(t, old)
+	connected := make(chan *stream.Stream, 1)
+	go func() {
+		s, err := connect()
+		if err != nil {
+			t.Error(err)
+		}
+		connected <- s
+	}()
+	select {
+	case <-connected:
+		t.Fatal("new instance connected while the old one held the lock")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	old.Stop()
+	select {
+	case s := <-connected:
+		if s == nil {
+			return
+		}
+		defer s.Stop()
+		nextTweet(t, s)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new instance did not connect after the old one stopped")
+	}
+	if n := maxOpen.Load(); n != 1 {
+		t.Errorf("%d connections open at once, want 1", n)
+	}
+}
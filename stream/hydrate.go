@@ -0,0 +1,116 @@
+package stream
+
+// User returns the user with the ID from the includes of the message, or nil
+// if it was not included.
+func (d *StreamData) User(id string) *User {
+	if d.Includes == nil || id == "" {
+		return nil
+	}
+	for _, user := range d.Includes.Users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+// Author returns the author of the Tweet of the message. Requires the
+// author_id expansion.
+func (d *StreamData) Author() *User {
+	if d.Tweet == nil {
+		return nil
+	}
+	return d.User(d.Tweet.AuthorID)
+}
+
+// AuthorOf returns the author of a Tweet of the message, e.g. of a quoted
+// Tweet. Requires the author_id or referenced_tweets.id.author_id expansion.
+func (d *StreamData) AuthorOf(tweet *Tweet) *User {
+	if tweet == nil {
+		return nil
+	}
+	return d.User(tweet.AuthorID)
+}
+
+// MediaFor returns the media attached to a Tweet of the message, in the order
+// of its attachments. Requires the attachments.media_keys expansion.
+func (d *StreamData) MediaFor(tweet *Tweet) []*Media {
+	if tweet == nil || tweet.Attachments == nil || d.Includes == nil {
+		return nil
+	}
+	var media []*Media
+	for _, key := range tweet.Attachments.MediaKeys {
+		for _, m := range d.Includes.Media {
+			if m.MediaKey == key {
+				media = append(media, m)
+				break
+			}
+		}
+	}
+	return media
+}
+
+// PlaceFor returns the place tagged to a Tweet of the message. Requires the
+// geo.place_id expansion.
+func (d *StreamData) PlaceFor(tweet *Tweet) *Place {
+	if tweet == nil || tweet.Geo == nil || d.Includes == nil {
+		return nil
+	}
+	for _, place := range d.Includes.Places {
+		if place.ID == tweet.Geo.PlaceID {
+			return place
+		}
+	}
+	return nil
+}
+
+// PollFor returns the poll attached to a Tweet of the message. Requires the
+// attachments.poll_ids expansion.
+func (d *StreamData) PollFor(tweet *Tweet) *Poll {
+	if tweet == nil || tweet.Attachments == nil || d.Includes == nil {
+		return nil
+	}
+	for _, id := range tweet.Attachments.PollIDs {
+		for _, poll := range d.Includes.Polls {
+			if poll.ID == id {
+				return poll
+			}
+		}
+	}
+	return nil
+}
+
+// Referenced returns the Tweet the Tweet of the message references with the
+// given type, one of ReferenceRetweeted, ReferenceQuoted or
+// ReferenceRepliedTo. Requires the referenced_tweets.id expansion.
+func (d *StreamData) Referenced(referenceType string) *Tweet {
+	if d.Tweet == nil || d.Includes == nil {
+		return nil
+	}
+	for _, ref := range d.Tweet.ReferencedTweets {
+		if ref.Type != referenceType {
+			continue
+		}
+		for _, tweet := range d.Includes.Tweets {
+			if tweet.ID == ref.ID {
+				return tweet
+			}
+		}
+	}
+	return nil
+}
+
+// Quoted returns the Tweet quoted by the Tweet of the message.
+func (d *StreamData) Quoted() *Tweet {
+	return d.Referenced(ReferenceQuoted)
+}
+
+// Retweeted returns the Tweet retweeted by the Tweet of the message.
+func (d *StreamData) Retweeted() *Tweet {
+	return d.Referenced(ReferenceRetweeted)
+}
+
+// RepliedTo returns the Tweet the Tweet of the message replies to.
+func (d *StreamData) RepliedTo() *Tweet {
+	return d.Referenced(ReferenceRepliedTo)
+}
@@ -0,0 +1,124 @@
+package stream
+
+import "time"
+
+// Includes holds the objects referenced by the Tweet of a message, as
+// requested with StreamFilterParams.Expansions.
+type Includes struct {
+	Users  []*User  `json:"users,omitempty"`
+	Media  []*Media `json:"media,omitempty"`
+	Places []*Place `json:"places,omitempty"`
+	Polls  []*Poll  `json:"polls,omitempty"`
+	Tweets []*Tweet `json:"tweets,omitempty"`
+}
+
+// User is a Twitter user. Fields other than ID, Name and Username are only
+// set when requested with StreamFilterParams.UserFields.
+type User struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	Username        string             `json:"username"`
+	CreatedAt       time.Time          `json:"created_at,omitzero"`
+	Description     string             `json:"description,omitempty"`
+	Location        string             `json:"location,omitempty"`
+	PinnedTweetID   string             `json:"pinned_tweet_id,omitempty"`
+	ProfileImageURL string             `json:"profile_image_url,omitempty"`
+	Protected       bool               `json:"protected,omitempty"`
+	URL             string             `json:"url,omitempty"`
+	Verified        bool               `json:"verified,omitempty"`
+	VerifiedType    string             `json:"verified_type,omitempty"`
+	PublicMetrics   *UserPublicMetrics `json:"public_metrics,omitempty"`
+	Entities        *UserEntities      `json:"entities,omitempty"`
+	Withheld        *Withheld          `json:"withheld,omitempty"`
+}
+
+// UserPublicMetrics are the public counts of a User.
+type UserPublicMetrics struct {
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+	TweetCount     int `json:"tweet_count"`
+	ListedCount    int `json:"listed_count"`
+	LikeCount      int `json:"like_count"`
+}
+
+// UserEntities are the entities of the URL and description of a User.
+type UserEntities struct {
+	URL         *Entities `json:"url,omitempty"`
+	Description *Entities `json:"description,omitempty"`
+}
+
+// Media types.
+const (
+	MediaPhoto       = "photo"
+	MediaVideo       = "video"
+	MediaAnimatedGIF = "animated_gif"
+)
+
+// Media is a photo, video or animated GIF attached to a Tweet. Fields other
+// than MediaKey and Type are only set when requested with
+// StreamFilterParams.MediaFields.
+type Media struct {
+	MediaKey         string              `json:"media_key"`
+	Type             string              `json:"type"`
+	URL              string              `json:"url,omitempty"`
+	PreviewImageURL  string              `json:"preview_image_url,omitempty"`
+	DurationMS       int                 `json:"duration_ms,omitempty"`
+	Height           int                 `json:"height,omitempty"`
+	Width            int                 `json:"width,omitempty"`
+	AltText          string              `json:"alt_text,omitempty"`
+	PublicMetrics    *MediaPublicMetrics `json:"public_metrics,omitempty"`
+	Variants         []*MediaVariant     `json:"variants,omitempty"`
+	NonPublicMetrics map[string]int      `json:"non_public_metrics,omitempty"`
+	OrganicMetrics   map[string]int      `json:"organic_metrics,omitempty"`
+	PromotedMetrics  map[string]int      `json:"promoted_metrics,omitempty"`
+}
+
+// MediaPublicMetrics are the public counts of a Media.
+type MediaPublicMetrics struct {
+	ViewCount int `json:"view_count"`
+}
+
+// MediaVariant is an encoding of a video or animated GIF.
+type MediaVariant struct {
+	BitRate     int    `json:"bit_rate,omitempty"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
+}
+
+// Place is a named location tagged to a Tweet. Fields other than ID and
+// FullName are only set when requested with StreamFilterParams.PlaceFields.
+type Place struct {
+	ID              string    `json:"id"`
+	FullName        string    `json:"full_name"`
+	Name            string    `json:"name,omitempty"`
+	Country         string    `json:"country,omitempty"`
+	CountryCode     string    `json:"country_code,omitempty"`
+	PlaceType       string    `json:"place_type,omitempty"`
+	ContainedWithin []string  `json:"contained_within,omitempty"`
+	Geo             *PlaceGeo `json:"geo,omitempty"`
+}
+
+// PlaceGeo is the GeoJSON feature of a Place. BBox is [west, south, east,
+// north].
+type PlaceGeo struct {
+	Type       string         `json:"type"`
+	BBox       []float64      `json:"bbox"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// Poll is a poll attached to a Tweet. Fields other than ID and Options are
+// only set when requested with StreamFilterParams.PollFields.
+type Poll struct {
+	ID              string        `json:"id"`
+	Options         []*PollOption `json:"options"`
+	DurationMinutes int           `json:"duration_minutes,omitempty"`
+	EndDatetime     time.Time     `json:"end_datetime,omitzero"`
+	VotingStatus    string        `json:"voting_status,omitempty"`
+}
+
+// PollOption is an option of a Poll.
+type PollOption struct {
+	Position int    `json:"position"`
+	Label    string `json:"label"`
+	Votes    int    `json:"votes"`
+}
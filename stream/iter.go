@@ -0,0 +1,44 @@
+package stream
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the messages of the stream, e.g.
+//
+//	for msg, err := range s.All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(msg.Tweet.ID)
+//	}
+//
+// The iteration ends once the stream stopped, yielding the error which
+// terminated it, see Err, if any, or once ctx is done, yielding ctx.Err().
+// Errors the stream recovers from, such as a lost connection, are not
+// yielded but sent on Errors as usual. Breaking the loop leaves the stream
+// running; All reads Messages, so it must not be combined with other
+// consumers or subscriptions.
+func (s *Stream) All(ctx context.Context) iter.Seq2[*StreamData, error] {
+	return func(yield func(*StreamData, error) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			case msg, ok := <-s.Messages:
+				if !ok {
+					<-s.terminated
+					if err := s.Err(); err != nil {
+						yield(nil, err)
+					}
+					return
+				}
+				if !yield(msg, nil) {
+					return
+				}
+			}
+		}
+	}
+}
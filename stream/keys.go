@@ -0,0 +1,34 @@
+package stream
+
+import "strconv"
+
+// KeyFunc derives the key a message is written under by sinks with
+// idempotent writes, such as Kafka message keys, upsert primary keys or
+// Elasticsearch document IDs.
+type KeyFunc func(*StreamData) string
+
+// DeliveryKey is a KeyFunc returning a key which is deterministic for each
+// revision of a Tweet: the ID of the original Tweet and the revision number
+// within its edit history, e.g. "1460323737035677698:2". Redeliveries of the
+// same revision, whether caused by a reconnect, a backfill or a replay, map to
+// the same key, so writing with it never creates downstream duplicates, while
+// each edit of a Tweet still gets a key of its own.
+//
+// DeliveryKey returns an empty string for messages without a Tweet.
+func DeliveryKey(d *StreamData) string {
+	if d == nil || d.Tweet == nil || d.Tweet.ID == "" {
+		return ""
+	}
+	original, revision := d.Tweet.ID, 0
+	if history := d.Tweet.EditHistoryTweetIDs; len(history) > 0 {
+		// edit history is ordered from the original Tweet to the latest edit
+		original = history[0]
+		for i, id := range history {
+			if id == d.Tweet.ID {
+				revision = i
+				break
+			}
+		}
+	}
+	return original + ":" + strconv.Itoa(revision)
+}
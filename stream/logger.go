@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives the leveled, structured logs of streams: connection
+// events, backoff waits, dropped messages and decode failures. Arguments are
+// alternating keys and values, as for slog. *slog.Logger implements it.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// WithLogger logs to the Logger instead of slog.Default(). Pass a Logger
+// with a handler whose level is above slog.LevelError to silence streams.
+func WithLogger(l Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// log logs msg with the arguments and the endpoint of the stream.
+func (s *Stream) log(level slog.Level, msg string, args ...any) {
+	s.config.logger.Log(s.traceCtx, level, msg, append(args, "url", s.req.URL.Path)...)
+}
+
+// logEvent logs a connection event.
+func (s *Stream) logEvent(event Event) {
+	level := slog.LevelInfo
+	switch event.Type {
+	case EventConnecting, EventHeartbeat:
+		level = slog.LevelDebug
+	case EventDisconnected, EventStallDetected, EventNotice:
+		level = slog.LevelWarn
+	case EventCircuitOpen:
+		level = slog.LevelError
+	case EventStopped:
+		if event.Err != nil {
+			level = slog.LevelError
+		}
+	}
+	args := []any{"event", event.Type.String()}
+	if event.Type == EventBackoff || event.Type == EventCircuitOpen {
+		args = append(args, "wait", event.Wait)
+	}
+	if event.Err != nil {
+		args = append(args, "error", event.Err)
+	}
+	s.log(level, "stream "+event.Type.String(), args...)
+}
+
+// drop counts and logs a received message which is not delivered.
+func (s *Stream) drop(reason string, args ...any) {
+	s.count(func(stats *Stats) { stats.Dropped++ })
+	s.log(slog.LevelWarn, "stream dropped message", append([]any{"reason", reason}, args...)...)
+}
+
+// decodeFailed reports an undecodable message on Errors and drops it.
+func (s *Stream) decodeFailed(data []byte, err error) {
+	s.sendError(&DecodeError{Data: append([]byte(nil), data...), Err: err})
+	s.count(func(stats *Stats) { stats.DecodeErrors++ })
+	s.drop("undecodable", "error", err, "size", len(data))
+}
+
+// tweetID returns the ID of the Tweet of msg, or "" if it has none.
+func tweetID(msg *StreamData) string {
+	if msg.Tweet == nil {
+		return ""
+	}
+	return msg.Tweet.ID
+}
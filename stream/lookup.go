@@ -0,0 +1,36 @@
+package stream
+
+import (
+	"context"
+	"net/url"
+)
+
+const tweetsV2Path = "/2/tweets"
+
+// LookupResponse is the response of a Tweet lookup.
+type LookupResponse struct {
+	Data     *Tweet            `json:"data"`
+	Includes *Includes         `json:"includes,omitempty"`
+	Errors   []*APIErrorDetail `json:"errors,omitempty"`
+}
+
+// LookupTweet returns the Tweet with the ID, with the fields and expansions
+// requested by params. Partition and BackfillMinutes of params are ignored.
+// A Tweet which is deleted or not visible to the app is returned as an
+// APIError.
+func (srv *StreamService) LookupTweet(ctx context.Context, id string, params *StreamFilterParams) (*LookupResponse, error) {
+	p := StreamFilterParams{}
+	if params != nil {
+		p = *params
+	}
+	p.Partition = 0
+	p.BackfillMinutes = 0
+	lookupResp := &LookupResponse{}
+	if err := srv.getJSON(ctx, srv.endpoint(tweetsV2Path)+"/"+url.PathEscape(id), &p, lookupResp); err != nil {
+		return nil, err
+	}
+	if lookupResp.Data == nil {
+		return nil, &APIError{Errors: lookupResp.Errors}
+	}
+	return lookupResp, nil
+}
@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Middleware wraps the Handler delivering messages, e.g. to enrich, scrub,
+// sample or rate limit them. It skips a message by returning without calling
+// next, and must call next, if at all, before returning.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware runs each decoded message through the middleware before
+// delivery, after filters and deduplication. The first middleware is the
+// outermost. Messages skipped by a middleware are counted in
+// Stats.Filtered, and a middleware error is sent on Errors as a
+// MiddlewareError and drops the message. Pooled messages which are not
+// delivered are released.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *config) {
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], mw...)
+	}
+}
+
+// MiddlewareError is sent on the Errors channel when a middleware failed a
+// message. The message is dropped.
+type MiddlewareError struct {
+	TweetID string
+	Err     error
+}
+
+func (e *MiddlewareError) Error() string {
+	return fmt.Sprintf("stream: middleware failed tweet %s: %v", e.TweetID, e.Err)
+}
+
+func (e *MiddlewareError) Unwrap() error {
+	return e.Err
+}
+
+// chain returns the middleware of the stream wrapped around delivery, or
+// nil without middleware.
+func (s *Stream) chain() Handler {
+	if len(s.config.middleware) == 0 {
+		return nil
+	}
+	var h Handler = func(ctx context.Context, msg *StreamData) error {
+		s.passed = true
+		return s.deliver(msg)
+	}
+	for i := len(s.config.middleware) - 1; i >= 0; i-- {
+		h = s.config.middleware[i](h)
+	}
+	return h
+}
+
+// process runs msg through the middleware and delivers it. It returns
+// context.Canceled if the stream was stopped first, and the error which
+// dropped the message otherwise, if any.
+func (s *Stream) process(msg *StreamData) error {
+	if s.handler == nil {
+		return s.deliver(msg)
+	}
+	ctx := msg.ctx
+	if ctx == nil {
+		ctx = s.traceCtx
+	}
+	s.passed = false
+	err := s.handler(ctx, msg)
+	switch {
+	case s.passed:
+		// delivered, or dropped and counted by deliver
+		return err
+	case errors.Is(err, context.Canceled) && stopped(s.done):
+		return context.Canceled
+	case err != nil:
+		s.sendError(&MiddlewareError{TweetID: tweetID(msg), Err: err})
+		s.drop("middleware", "tweet_id", tweetID(msg), "error", err)
+	default:
+		s.count(func(stats *Stats) { stats.Filtered++ })
+	}
+	msg.Release()
+	return err
+}
+
+// SampleMiddleware delivers each message with the probability rate, from 0
+// to 1, skipping the others.
+func SampleMiddleware(rate float64) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *StreamData) error {
+			if rand.Float64() >= rate {
+				return nil
+			}
+			return next(ctx, msg)
+		}
+	}
+}
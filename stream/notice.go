@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NoticeKind is the kind of a Notice.
+type NoticeKind int
+
+const (
+	// NoticeError is an in-stream error object of another kind, e.g. about
+	// a Tweet which could not be hydrated. The connection stays open.
+	NoticeError NoticeKind = iota + 1
+	// NoticeOperationalDisconnect is Twitter disconnecting the stream for
+	// operational reasons, e.g. a deploy on their side. The stream
+	// reconnects immediately.
+	NoticeOperationalDisconnect
+	// NoticeTooManyConnections is Twitter closing the connection because the
+	// app is at its connection limit, e.g. since another instance connected.
+	// The stream reconnects, backing off as the limit is likely still
+	// reached.
+	NoticeTooManyConnections
+	// NoticeLimit is a limit notice, counting in Undelivered the Tweets
+	// matching the rules which were not delivered because of rate limits
+	// since the connection was opened. The connection stays open.
+	NoticeLimit
+)
+
+func (k NoticeKind) String() string {
+	switch k {
+	case NoticeError:
+		return "error"
+	case NoticeOperationalDisconnect:
+		return "operational disconnect"
+	case NoticeTooManyConnections:
+		return "too many connections"
+	case NoticeLimit:
+		return "limit"
+	default:
+		return fmt.Sprintf("NoticeKind(%d)", int(k))
+	}
+}
+
+// Notice is a payload which Twitter sent within the stream instead of a
+// Tweet, such as an operational disconnect notice:
+//
+//	{"errors":[{"title":"operational-disconnect",
+//	 "disconnect_type":"UpstreamOperationalDisconnect",
+//	 "detail":"This stream has been disconnected upstream for operational reasons.",
+//	 "type":"https://api.twitter.com/2/problems/operational-disconnect"}]}
+//
+// It is sent on the Errors channel and with an EventNotice rather than as
+// a message. Notices of error objects unwrap to their APIError.
+type Notice struct {
+	Kind NoticeKind
+	// APIError is the error object of the notice, if it is one.
+	APIError *APIError
+	// Undelivered is the number of undelivered Tweets of NoticeLimit.
+	Undelivered int
+}
+
+func (n *Notice) Error() string {
+	msg := "stream: " + n.Kind.String() + " notice"
+	if n.Kind == NoticeLimit {
+		return fmt.Sprintf("%s: %d Tweets undelivered", msg, n.Undelivered)
+	}
+	if n.APIError != nil {
+		msg += ": " + n.APIError.message()
+	}
+	return msg
+}
+
+func (n *Notice) Unwrap() error {
+	if n.APIError == nil {
+		return nil
+	}
+	return n.APIError
+}
+
+// disconnects reports whether the connection ends with the notice.
+func (n *Notice) disconnects() bool {
+	return n.Kind == NoticeOperationalDisconnect || n.Kind == NoticeTooManyConnections
+}
+
+// noticePayload is the union of the payloads of notices: error objects,
+// which are either a single error or a list of errors, and the disconnect
+// and limit messages of the v1.1 stream format.
+type noticePayload struct {
+	APIError
+	Disconnect *struct {
+		Code   int    `json:"code"`
+		Reason string `json:"reason"`
+	} `json:"disconnect"`
+	Limit *struct {
+		Track int `json:"track"`
+	} `json:"limit"`
+}
+
+// parseNotice decodes a message without a Tweet as a Notice, reporting
+// whether it is one.
+func parseNotice(data []byte) (*Notice, bool) {
+	var payload noticePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, false
+	}
+	switch {
+	case payload.Limit != nil:
+		return &Notice{Kind: NoticeLimit, Undelivered: payload.Limit.Track}, true
+	case payload.Disconnect != nil:
+		return &Notice{
+			Kind:     NoticeOperationalDisconnect,
+			APIError: &APIError{Title: "disconnect", Detail: payload.Disconnect.Reason, Status: payload.Disconnect.Code},
+		}, true
+	}
+	apiErr := &payload.APIError
+	if apiErr.Title == "" && apiErr.Detail == "" && len(apiErr.Errors) == 0 {
+		return nil, false
+	}
+	notice := &Notice{Kind: NoticeError, APIError: apiErr}
+	if tooManyConnections(apiErr.Title, apiErr.Type, apiErr.ConnectionIssue) {
+		notice.Kind = NoticeTooManyConnections
+	}
+	if operationalDisconnect(apiErr.Title, apiErr.Type, "") {
+		notice.Kind = NoticeOperationalDisconnect
+	}
+	for _, detail := range apiErr.Errors {
+		if tooManyConnections(detail.Title, detail.Type, "") {
+			notice.Kind = NoticeTooManyConnections
+		}
+		if operationalDisconnect(detail.Title, detail.Type, detail.DisconnectType) {
+			notice.Kind = NoticeOperationalDisconnect
+		}
+	}
+	return notice, true
+}
+
+func operationalDisconnect(title, typ, disconnectType string) bool {
+	return disconnectType != "" || title == "operational-disconnect" || strings.HasSuffix(typ, "/operational-disconnect")
+}
+
+func tooManyConnections(title, typ, connectionIssue string) bool {
+	return connectionIssue == "TooManyConnections" || title == "ConnectionException" || strings.HasSuffix(typ, "/streaming-connection")
+}
+
+// notice reports a notice on Errors and as an EventNotice, returning it if
+// the connection ends with it.
+func (s *Stream) notice(notice *Notice) error {
+	s.sendError(notice)
+	s.emit(Event{Type: EventNotice, Err: notice})
+	if notice.disconnects() {
+		return notice
+	}
+	return nil
+}
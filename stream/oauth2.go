@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const oauth2TokenPath = "/oauth2/token"
+
+// AppToken obtains and caches the app-only bearer token of an app from its
+// consumer key and secret, using the OAuth 2.0 client credentials grant.
+type AppToken struct {
+	// BaseURL is the base URL of the API, DefaultBaseURL if empty.
+	BaseURL string
+	client  *http.Client
+	key     string
+	secret  string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewAppToken returns an AppToken requesting tokens with the http.Client.
+func NewAppToken(client *http.Client, consumerKey, consumerSecret string) *AppToken {
+	return &AppToken{client: client, key: consumerKey, secret: consumerSecret}
+}
+
+// Token returns the cached bearer token, requesting one if there is none.
+func (t *AppToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" {
+		return t.token, nil
+	}
+	return t.fetch(ctx)
+}
+
+// Refresh discards the cached bearer token and requests a new one. It is a
+// TokenRefresher.
+func (t *AppToken) Refresh(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+	return t.fetch(ctx)
+}
+
+// fetch requests a bearer token. t.mu must be held.
+func (t *AppToken) fetch(ctx context.Context) (string, error) {
+	body := strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint(t.BaseURL, oauth2TokenPath), body)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(url.QueryEscape(t.key), url.QueryEscape(t.secret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp)
+	}
+	var tokenResp struct {
+		TokenType   string `json:"token_type"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(tokenResp.TokenType, "bearer") || tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("stream: unexpected token type %q", tokenResp.TokenType)
+	}
+	t.token = tokenResp.AccessToken
+	return t.token, nil
+}
+
+// NewAppStreamService returns a StreamService authenticated with the
+// app-only bearer token of the consumer key and secret. The token is
+// requested before returning and requested again whenever Twitter rejects
+// it, including while streaming; a TokenRefresher passed in opts is
+// replaced.
+func NewAppStreamService(ctx context.Context, client *http.Client, consumerKey, consumerSecret string, opts ...Option) (*StreamService, error) {
+	srv := NewStreamService(client, "", opts...)
+	appToken := NewAppToken(srv.client, consumerKey, consumerSecret)
+	appToken.BaseURL = srv.config.baseURL
+	token, err := appToken.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("stream: obtain bearer token: %w", err)
+	}
+	srv.setBearerToken(token)
+	srv.config.tokenRefresher = func(ctx context.Context) (string, error) {
+		token, err := appToken.Refresh(ctx)
+		if err != nil {
+			return "", err
+		}
+		srv.setBearerToken(token)
+		return token, nil
+	}
+	return srv, nil
+}
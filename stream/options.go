@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kalvin807/twitter-v2-stream/state"
+)
+
+// config holds the settings applied by Options. Each Stream keeps a copy of
+// the config of the service which connected it.
+type config struct {
+	tagMapper       *TagMapper
+	connectGrace    time.Duration
+	connectionLock  ConnectionLock
+	backOffPolicy   BackOffPolicy
+	statusPolicies  map[int]StatusPolicy
+	tokenRefresher  TokenRefresher
+	autoBackfill    bool
+	gapRecovery     time.Duration
+	gapLookup       bool
+	tokenProvider   TokenProvider
+	baseURL         string
+	backOffs        map[ErrorClass]func() backoff.BackOff
+	stallTimeout    time.Duration
+	tracer          Tracer
+	logger          Logger
+	messagesBuffer  int
+	overflow        Overflow
+	rawPayload      bool
+	heartbeats      bool
+	maxMessageSize  int
+	messagePool     bool
+	decoder         Decoder
+	filters         []Filter
+	middleware      []Middleware
+	recorder        *recorder
+	dedupSize       int
+	dedupTTL        time.Duration
+	dedupStore      state.Store
+	dedupStoreTTL   time.Duration
+	checkpointStore state.Store
+	checkpointKey   string
+	replaySize      int
+	transport       transport
+	circuitBreaker  *CircuitBreaker
+	ruleQuota       RuleQuota
+	strictFields    bool
+}
+
+// Option configures a StreamService.
+type Option func(*config)
+
+// WithBaseURL sends requests to the API at baseURL instead of
+// DefaultBaseURL, e.g. a local mock server, a corporate proxy or another API
+// host.
+func WithBaseURL(baseURL string) Option {
+	return func(c *config) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithMessagesBuffer buffers up to size messages in the Messages channel of
+// streams, so a consumer falling briefly behind does not block reading from
+// the connection, which Twitter disconnects when it falls behind. Messages
+// is unbuffered by default.
+func WithMessagesBuffer(size int) Option {
+	return func(c *config) {
+		c.messagesBuffer = size
+	}
+}
+
+// WithMaxMessageSize skips stream messages larger than size bytes, reporting
+// a MessageTooLargeError, so a runaway message cannot exhaust memory.
+// Messages may be of any size by default; Tweets with all expansions can
+// exceed several hundred kilobytes.
+func WithMaxMessageSize(size int) Option {
+	return func(c *config) {
+		c.maxMessageSize = size
+	}
+}
+
+// WithRawPayload keeps the exact bytes of each received message in
+// StreamData.Raw, e.g. for archiving, at the cost of a copy per message.
+func WithRawPayload() Option {
+	return func(c *config) {
+		c.rawPayload = true
+	}
+}
+
+// WithTagMapper rewrites the matching rules of every received message with
+// the given TagMapper before it is delivered.
+func WithTagMapper(m *TagMapper) Option {
+	return func(c *config) {
+		c.tagMapper = m
+	}
+}
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOverflow is the reason a message was dropped by an overflow policy.
+var ErrOverflow = errors.New("stream: consumer fell behind, message dropped")
+
+// Overflow is what a stream does with a message when the consumer falls
+// behind and the Messages buffer is full, see WithMessagesBuffer.
+type Overflow int
+
+const (
+	// OverflowBlock waits for the consumer, stopping reading from the
+	// connection meanwhile. Twitter disconnects streams which fall too far
+	// behind.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest drops the message.
+	OverflowDropNewest
+	// OverflowDropOldest evicts the oldest buffered message to make room
+	// for the message. Without a buffer it drops the message.
+	OverflowDropOldest
+)
+
+// WithOverflow sets the overflow policy of streams, OverflowBlock by
+// default. Dropped messages are counted in Stats.Overflowed.
+func WithOverflow(policy Overflow) Option {
+	return func(c *config) {
+		c.overflow = policy
+	}
+}
+
+// deliver sends msg on Messages by the overflow policy. It returns
+// ErrOverflow if msg was dropped, or context.Canceled if the stream was
+// stopped first.
+func (s *Stream) deliver(msg *StreamData) error {
+	policy := s.config.overflow
+	if policy == OverflowDropOldest && cap(s.Messages) == 0 {
+		// without a buffer, the oldest message is msg
+		policy = OverflowDropNewest
+	}
+	switch policy {
+	case OverflowDropNewest:
+		if stopped(s.done) {
+			return context.Canceled
+		}
+		select {
+		case s.Messages <- msg:
+			s.delivered(msg)
+			return nil
+		default:
+			s.overflow(msg)
+			return ErrOverflow
+		}
+	case OverflowDropOldest:
+		for !stopped(s.done) {
+			select {
+			case s.Messages <- msg:
+				s.delivered(msg)
+				return nil
+			default:
+			}
+			select {
+			case old := <-s.Messages:
+				s.overflow(old)
+			default:
+				// the consumer made room meanwhile
+			}
+		}
+		return context.Canceled
+	default:
+		select {
+		// allow client to Stop(), even if not receiving
+		case <-s.done:
+			return context.Canceled
+		case s.Messages <- msg:
+			s.delivered(msg)
+			return nil
+		}
+	}
+}
+
+// overflow counts and logs a message dropped by the overflow policy.
+func (s *Stream) overflow(msg *StreamData) {
+	s.count(func(stats *Stats) { stats.Overflowed++ })
+	s.drop("overflow", "tweet_id", tweetID(msg))
+	msg.Release()
+}
@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParameterError names a query parameter Twitter rejected, or would reject,
+// and the offending values.
+type ParameterError struct {
+	Parameter string
+	Values    []string
+	Message   string
+}
+
+func (e *ParameterError) Error() string {
+	if len(e.Values) > 0 {
+		return fmt.Sprintf("stream: invalid parameter %s %v: %s", e.Parameter, e.Values, e.Message)
+	}
+	return fmt.Sprintf("stream: invalid parameter %s: %s", e.Parameter, e.Message)
+}
+
+// InvalidRequestError is the error of a StatusError for a request Twitter
+// answered with 400 Bad Request, e.g. for an unknown field name or invalid
+// expansion. Errors name the offending parameters.
+type InvalidRequestError struct {
+	Detail string
+	Errors []*ParameterError
+}
+
+func (e *InvalidRequestError) Error() string {
+	if len(e.Errors) == 0 {
+		return "stream: invalid request: " + e.Detail
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *InvalidRequestError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// fieldExpansions lists, for each field parameter, the expansions which make
+// Twitter return the objects the fields apply to. Without one of them the
+// fields are silently ignored.
+var fieldExpansions = map[string][]string{
+	"media.fields": {"attachments.media_keys"},
+	"place.fields": {"geo.place_id"},
+	"poll.fields":  {"attachments.poll_ids"},
+	"user.fields": {
+		"author_id",
+		"entities.mentions.username",
+		"entities.note.mentions.username",
+		"in_reply_to_user_id",
+		"referenced_tweets.id.author_id",
+	},
+}
+
+// Validate checks the parameters for mistakes Twitter either rejects or
+// silently ignores, such as misspelled fields or fields missing the
+// expansion they apply to, returning a ParameterError for the first
+// parameter with one. Connect validates its parameters before connecting,
+// but only logs unknown values unless the service is WithStrictFields, since
+// Twitter may add fields this package does not know yet.
+func (p *StreamFilterParams) Validate() error {
+	return p.validate(nil)
+}
+
+// validate is Validate, except that the ParameterErrors of unknown values
+// are passed to unknown instead of returned, if it is not nil.
+func (p *StreamFilterParams) validate(unknown func(*ParameterError)) error {
+	if p == nil {
+		return nil
+	}
+	lists := map[string][]string{
+		"expansions":   p.Expansions,
+		"media.fields": p.MediaFields,
+		"place.fields": p.PlaceFields,
+		"poll.fields":  p.PollFields,
+		"tweet.fields": p.TweetFields,
+		"user.fields":  p.UserFields,
+	}
+	for _, name := range []string{"expansions", "media.fields", "place.fields", "poll.fields", "tweet.fields", "user.fields"} {
+		for _, value := range lists[name] {
+			if value == "" || strings.ContainsAny(value, ", \t") {
+				return &ParameterError{
+					Parameter: name,
+					Values:    []string{value},
+					Message:   "values must be non-empty and must not contain commas or whitespace",
+				}
+			}
+		}
+		if err := unknownValues(name, lists[name]); err != nil {
+			if unknown == nil {
+				return err
+			}
+			unknown(err)
+		}
+	}
+	for _, name := range []string{"media.fields", "place.fields", "poll.fields", "user.fields"} {
+		if len(lists[name]) == 0 || containsAny(p.Expansions, fieldExpansions[name]) {
+			continue
+		}
+		return &ParameterError{
+			Parameter: name,
+			Values:    lists[name],
+			Message:   fmt.Sprintf("requires one of the expansions %v", fieldExpansions[name]),
+		}
+	}
+	return nil
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
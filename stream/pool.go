@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// messagePool holds released messages of streams configured
+// WithMessagePool.
+var messagePool = sync.Pool{
+	New: func() any { return &StreamData{} },
+}
+
+// WithMessagePool decodes messages into pooled StreamData, reusing their
+// Tweet and matching rules, to reduce allocations and GC pressure at high
+// volumes. The consumer must call Release on each message once done with it,
+// and must not retain the message, its Tweet or its matching rules after.
+// Messages which are not released are garbage collected as usual.
+func WithMessagePool() Option {
+	return func(c *config) {
+		c.messagePool = true
+	}
+}
+
+// getPooledMessage decodes the token into a message from the pool.
+func getPooledMessage(decoder Decoder, token []byte) (*StreamData, error) {
+	msg := messagePool.Get().(*StreamData)
+	tweet := msg.spareTweet
+	if tweet == nil {
+		tweet = &Tweet{}
+	} else {
+		*tweet = Tweet{}
+	}
+	rules := msg.spareRules[:cap(msg.spareRules)]
+	for _, rule := range rules {
+		if rule != nil {
+			*rule = MatchingRule{}
+		}
+	}
+	*msg = StreamData{
+		Tweet:         tweet,
+		MatchingRules: rules[:0],
+		pooled:        true,
+		spareTweet:    tweet,
+		spareRules:    rules[:0],
+	}
+	// decoding reuses the Tweet and the rules within the capacity of the
+	// slice
+	if err := decoder.Unmarshal(token, msg); err != nil {
+		msg.Release()
+		return nil, err
+	}
+	if msg.Tweet == tweet && tweet.ID == "" {
+		// no data, like an in-stream error object
+		msg.Tweet = nil
+	}
+	if cap(msg.MatchingRules) > cap(msg.spareRules) {
+		msg.spareRules = msg.MatchingRules[:0]
+	}
+	if len(msg.MatchingRules) == 0 {
+		msg.MatchingRules = nil
+	}
+	return msg, nil
+}
+
+// Release returns the message to the pool of streams configured
+// WithMessagePool. The message must not be used after. Release does nothing
+// for other messages.
+func (d *StreamData) Release() {
+	if !d.pooled {
+		return
+	}
+	d.pooled = false
+	messagePool.Put(d)
+}
+
+// Clone returns a deep copy of the message which does not share the Tweet,
+// includes, rules, errors, annotations or raw payload with it, e.g. to hand
+// the same message to consumers which may modify it. The values of the
+// annotations are copied shallowly. The copy is never pooled.
+func (d *StreamData) Clone() *StreamData {
+	c := &StreamData{Recovered: d.Recovered, ctx: d.ctx, seq: d.seq}
+	deepCopy(&c.Tweet, d.Tweet)
+	deepCopy(&c.Includes, d.Includes)
+	if d.MatchingRules != nil {
+		c.MatchingRules = make([]*MatchingRule, len(d.MatchingRules))
+		for i, rule := range d.MatchingRules {
+			copied := *rule
+			if rule.Labels != nil {
+				copied.Labels = make(map[string]string, len(rule.Labels))
+				for k, v := range rule.Labels {
+					copied.Labels[k] = v
+				}
+			}
+			c.MatchingRules[i] = &copied
+		}
+	}
+	deepCopy(&c.Errors, d.Errors)
+	if d.Annotations != nil {
+		c.Annotations = make(map[string]any, len(d.Annotations))
+		for k, v := range d.Annotations {
+			c.Annotations[k] = v
+		}
+	}
+	if d.Raw != nil {
+		c.Raw = append(json.RawMessage(nil), d.Raw...)
+	}
+	return c
+}
+
+// deepCopy sets the variable dst points to, of the type of src, to a deep
+// copy of src.
+func deepCopy(dst, src any) {
+	copyValue(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src))
+}
+
+// copyValue sets dst to a deep copy of src: pointers, slices, maps and
+// interfaces are copied recursively, as are the exported fields of structs,
+// and the values of every other kind are assigned. Unexported fields, like
+// those of time.Time, are assigned with the struct.
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		copyValue(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		copyValue(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for iter := src.MapRange(); iter.Next(); {
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyValue(value, iter.Value())
+			dst.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,58 @@
+package stream_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+const cloneSample = `{
+	"data": {
+		"id": "2", "text": "#go", "created_at": "2023-05-01T12:00:00.000Z",
+		"entities": {"hashtags": [{"start": 0, "end": 3, "tag": "go"}]},
+		"geo": {"place_id": "pl1", "coordinates": {"type": "Point", "coordinates": [-122.4, 37.8]}},
+		"edit_history_tweet_ids": ["2"]
+	},
+	"includes": {"users": [{"id": "100", "username": "alice", "created_at": "2010-01-01T00:00:00.000Z"}]},
+	"matching_rules": [{"id": "10", "tag": "go"}],
+	"errors": [{"title": "partial", "parameters": {"ids": ["1"]}}]
+}`
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *stream.StreamData)
+	}{
+		{"text", func(c *stream.StreamData) { c.Tweet.Text = "changed" }},
+		{"created at", func(c *stream.StreamData) { c.Tweet.CreatedAt = time.Time{} }},
+		{"hashtag", func(c *stream.StreamData) { c.Tweet.Entities.Hashtags[0].Tag = "rust" }},
+		{"coordinates", func(c *stream.StreamData) { c.Tweet.Geo.Coordinates.Coordinates[0] = 0 }},
+		{"edit history", func(c *stream.StreamData) { c.Tweet.EditHistoryTweetIDs[0] = "3" }},
+		{"included user", func(c *stream.StreamData) { c.Includes.Users[0].Username = "bob" }},
+		{"matching rule", func(c *stream.StreamData) { c.MatchingRules[0].Tag = "rust" }},
+		{"error parameters", func(c *stream.StreamData) { c.Errors[0].Parameters["ids"][0] = "2" }},
+		{"annotations", func(c *stream.StreamData) { c.Annotations["note"] = "changed" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg, want stream.StreamData
+			for _, d := range []*stream.StreamData{&msg, &want} {
+				if err := json.Unmarshal([]byte(cloneSample), d); err != nil {
+					t.Fatal(err)
+				}
+				d.Annotations = map[string]any{"note": "kept"}
+			}
+			c := msg.Clone()
+			if !reflect.DeepEqual(c, &want) {
+				t.Fatalf("clone\n%+v\nwant\n%+v", c, &want)
+			}
+			tt.modify(c)
+			if !reflect.DeepEqual(&msg, &want) {
+				t.Errorf("modifying the clone changed the message to\n%+v", &msg)
+			}
+		})
+	}
+}
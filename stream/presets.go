@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the StreamFilterParams presets, see Preset.
+const (
+	PresetMinimal    = "minimal"
+	PresetStandard   = "standard"
+	PresetEverything = "everything"
+	PresetMediaHeavy = "media-heavy"
+)
+
+// presets are combinations of fields and expansions known to be accepted
+// together with app-only authentication. Metrics which require user context
+// are left out.
+var presets = map[string]StreamFilterParams{
+	PresetMinimal: {
+		TweetFields: []string{"author_id", "created_at"},
+	},
+	PresetStandard: {
+		Expansions:  []string{"author_id", "referenced_tweets.id"},
+		TweetFields: []string{"author_id", "conversation_id", "created_at", "entities", "lang", "public_metrics", "referenced_tweets"},
+		UserFields:  []string{"name", "public_metrics", "username", "verified"},
+	},
+	PresetEverything: {
+		Expansions: []string{
+			"attachments.media_keys", "attachments.poll_ids", "author_id", "edit_history_tweet_ids",
+			"entities.mentions.username", "geo.place_id", "in_reply_to_user_id", "referenced_tweets.id",
+			"referenced_tweets.id.author_id",
+		},
+		MediaFields: []string{"alt_text", "duration_ms", "height", "media_key", "preview_image_url", "public_metrics", "type", "url", "variants", "width"},
+		PlaceFields: []string{"contained_within", "country", "country_code", "full_name", "geo", "id", "name", "place_type"},
+		PollFields:  []string{"duration_minutes", "end_datetime", "id", "options", "voting_status"},
+		TweetFields: []string{
+			"attachments", "author_id", "context_annotations", "conversation_id", "created_at",
+			"edit_controls", "edit_history_tweet_ids", "entities", "geo", "id", "in_reply_to_user_id",
+			"lang", "note_tweet", "possibly_sensitive", "public_metrics", "referenced_tweets",
+			"reply_settings", "source", "text", "withheld",
+		},
+		UserFields: []string{
+			"created_at", "description", "entities", "id", "location", "name", "pinned_tweet_id",
+			"profile_image_url", "protected", "public_metrics", "url", "username", "verified",
+			"verified_type", "withheld",
+		},
+	},
+	PresetMediaHeavy: {
+		Expansions:  []string{"attachments.media_keys", "author_id"},
+		MediaFields: []string{"alt_text", "duration_ms", "height", "media_key", "preview_image_url", "public_metrics", "type", "url", "variants", "width"},
+		TweetFields: []string{"attachments", "author_id", "created_at", "entities", "possibly_sensitive"},
+		UserFields:  []string{"name", "username"},
+	},
+}
+
+// Preset returns a copy of the StreamFilterParams of a named preset:
+// PresetMinimal for just the author and time of Tweets, PresetStandard for
+// authors, metrics and referenced Tweets, PresetEverything for every field
+// and expansion available with app-only authentication, and PresetMediaHeavy
+// for attached media with all their fields.
+func Preset(name string) (*StreamFilterParams, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("stream: unknown preset %q, want one of %s", name, strings.Join(PresetNames(), ", "))
+	}
+	return &StreamFilterParams{
+		Expansions:  append([]string(nil), preset.Expansions...),
+		MediaFields: append([]string(nil), preset.MediaFields...),
+		PlaceFields: append([]string(nil), preset.PlaceFields...),
+		PollFields:  append([]string(nil), preset.PollFields...),
+		TweetFields: append([]string(nil), preset.TweetFields...),
+		UserFields:  append([]string(nil), preset.UserFields...),
+	}, nil
+}
+
+// PresetNames returns the names of the presets, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,160 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Names of the access levels of RuleQuotaOf.
+const (
+	AccessEssential = "essential"
+	AccessElevated  = "elevated"
+	AccessAcademic  = "academic"
+	AccessBasic     = "basic"
+	AccessPro       = "pro"
+)
+
+// RuleQuota is the limits an access level of the API puts on the rules of
+// the filtered stream. Zero fields are not checked.
+type RuleQuota struct {
+	// MaxRules is the maximum number of rules of the stream.
+	MaxRules int
+	// MaxLength is the maximum length of a rule value, in characters.
+	MaxLength int
+}
+
+// ruleQuotas are the quotas of the access levels.
+var ruleQuotas = map[string]RuleQuota{
+	AccessEssential: {MaxRules: 5, MaxLength: 512},
+	AccessElevated:  {MaxRules: 25, MaxLength: 512},
+	AccessAcademic:  {MaxRules: 1000, MaxLength: 1024},
+	AccessBasic:     {MaxRules: 50, MaxLength: 512},
+	AccessPro:       {MaxRules: 1000, MaxLength: 1024},
+}
+
+// RuleQuotaOf returns the RuleQuota of a named access level.
+func RuleQuotaOf(access string) (RuleQuota, error) {
+	quota, ok := ruleQuotas[access]
+	if !ok {
+		names := make([]string, 0, len(ruleQuotas))
+		for name := range ruleQuotas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return RuleQuota{}, fmt.Errorf("stream: unknown access level %q, want one of %s", access, strings.Join(names, ", "))
+	}
+	return quota, nil
+}
+
+// WithRuleQuota checks rules against the quota before AddRules sends them,
+// returning a QuotaError instead of letting Twitter reject the whole
+// request. The number of rules of the stream is requested once with
+// GetRules and then kept up to date by the requests of the service; call
+// GetRules to refresh it after rules were changed elsewhere.
+func WithRuleQuota(quota RuleQuota) Option {
+	return func(c *config) {
+		c.ruleQuota = quota
+	}
+}
+
+// QuotaError is returned by AddRules, without sending the request, when the
+// rules exceed the RuleQuota of the service.
+type QuotaError struct {
+	Quota RuleQuota
+	// Rules is the number of rules of the stream, and Adding the number of
+	// rules of the request. Exceeded is the number of rules by which the
+	// request would exceed Quota.MaxRules, zero if it would not.
+	Rules    int
+	Adding   int
+	Exceeded int
+	// TooLong are the rules whose values exceed Quota.MaxLength.
+	TooLong []*Rule
+}
+
+func (e *QuotaError) Error() string {
+	var msgs []string
+	if e.Exceeded > 0 {
+		msgs = append(msgs, fmt.Sprintf("adding %d rules to %d would exceed the quota of %d rules by %d", e.Adding, e.Rules, e.Quota.MaxRules, e.Exceeded))
+	}
+	for _, rule := range e.TooLong {
+		msgs = append(msgs, fmt.Sprintf("rule %q is %d characters long, exceeds the limit of %d", rule.Value, utf8.RuneCountInString(rule.Value), e.Quota.MaxLength))
+	}
+	return "stream: " + strings.Join(msgs, "; ")
+}
+
+// checkQuota returns a QuotaError if adding rules would exceed the quota of
+// the service.
+func (srv *StreamService) checkQuota(ctx context.Context, rules []*Rule) error {
+	quota := srv.config.ruleQuota
+	if quota == (RuleQuota{}) {
+		return nil
+	}
+	quotaErr := &QuotaError{Quota: quota, Adding: len(rules)}
+	if quota.MaxLength > 0 {
+		for _, rule := range rules {
+			if utf8.RuneCountInString(rule.Value) > quota.MaxLength {
+				quotaErr.TooLong = append(quotaErr.TooLong, rule)
+			}
+		}
+	}
+	if quota.MaxRules > 0 {
+		n, err := srv.RuleCount(ctx)
+		if err != nil {
+			return err
+		}
+		quotaErr.Rules = n
+		quotaErr.Exceeded = max(n+len(rules)-quota.MaxRules, 0)
+	}
+	if quotaErr.Exceeded > 0 || len(quotaErr.TooLong) > 0 {
+		return quotaErr
+	}
+	return nil
+}
+
+// RuleCount returns the number of rules of the stream, as of the last rules
+// request of the service, requesting the rules if there was none.
+func (srv *StreamService) RuleCount(ctx context.Context) (int, error) {
+	srv.rulesMu.Lock()
+	n, known := srv.ruleCount, srv.ruleCountKnown
+	srv.rulesMu.Unlock()
+	if known {
+		return n, nil
+	}
+	rules, err := srv.GetRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(rules), nil
+}
+
+// setRuleCount records the number of rules of the stream.
+func (srv *StreamService) setRuleCount(n int) {
+	srv.rulesMu.Lock()
+	defer srv.rulesMu.Unlock()
+	srv.ruleCount, srv.ruleCountKnown = n, true
+}
+
+// countRules updates the number of rules of the stream by the summary of an
+// add or delete request.
+func (srv *StreamService) countRules(resp *RulesResponse, err error) {
+	srv.rulesMu.Lock()
+	defer srv.rulesMu.Unlock()
+	if resp == nil || resp.Meta == nil || resp.Meta.Summary == nil {
+		if err != nil {
+			// the rules may or may not have changed
+			srv.ruleCountKnown = false
+		}
+		return
+	}
+	for _, ruleErr := range resp.Errors {
+		if ruleErr.Type == ProblemRuleCap {
+			// the count is off, or the quota of the service is wrong
+			srv.ruleCountKnown = false
+			return
+		}
+	}
+	srv.ruleCount += resp.Meta.Summary.Created - resp.Meta.Summary.Deleted
+}
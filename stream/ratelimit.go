@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RateLimit is the rate limit of an endpoint as of a response, from its
+// x-rate-limit-limit, x-rate-limit-remaining and x-rate-limit-reset
+// headers.
+type RateLimit struct {
+	// Limit is the number of requests allowed per window, and Remaining
+	// the number left until the window resets at Reset.
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// parseRateLimit returns the RateLimit of a response, or nil if it has no
+// rate limit headers.
+func parseRateLimit(resp *http.Response) *RateLimit {
+	limit, err := strconv.Atoi(resp.Header.Get("x-rate-limit-limit"))
+	if err != nil {
+		return nil
+	}
+	rateLimit := &RateLimit{Limit: limit}
+	if remaining, err := strconv.Atoi(resp.Header.Get("x-rate-limit-remaining")); err == nil {
+		rateLimit.Remaining = remaining
+	}
+	if epoch, err := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(epoch, 0)
+	}
+	return rateLimit
+}
+
+// EndpointRateLimit is the RateLimit of an endpoint, by its URL path such as
+// /2/tweets/search/stream/rules.
+type EndpointRateLimit struct {
+	Endpoint string
+	RateLimit
+}
+
+// RateLimits returns the rate limits of the endpoints the service sent
+// requests to, including stream connect attempts, as of the latest response
+// of each which had rate limit headers, sorted by endpoint.
+func (srv *StreamService) RateLimits() []EndpointRateLimit {
+	srv.rateLimitsMu.Lock()
+	defer srv.rateLimitsMu.Unlock()
+	limits := make([]EndpointRateLimit, 0, len(srv.rateLimits))
+	for endpoint, rateLimit := range srv.rateLimits {
+		limits = append(limits, EndpointRateLimit{Endpoint: endpoint, RateLimit: rateLimit})
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Endpoint < limits[j].Endpoint })
+	return limits
+}
+
+// observeRateLimit records the rate limit of a response of the service, and
+// returns it, or nil if the response has none.
+func (srv *StreamService) observeRateLimit(resp *http.Response) *RateLimit {
+	rateLimit := parseRateLimit(resp)
+	if rateLimit == nil {
+		return nil
+	}
+	srv.rateLimitsMu.Lock()
+	defer srv.rateLimitsMu.Unlock()
+	if srv.rateLimits == nil {
+		srv.rateLimits = make(map[string]RateLimit)
+	}
+	srv.rateLimits[resp.Request.URL.Path] = *rateLimit
+	return rateLimit
+}
+
+// observeRateLimit records the rate limit of a connect attempt in the Stats
+// of the stream and the rate limits of its service.
+func (s *Stream) observeRateLimit(resp *http.Response) {
+	var rateLimit *RateLimit
+	if s.srv != nil {
+		rateLimit = s.srv.observeRateLimit(resp)
+	} else {
+		rateLimit = parseRateLimit(resp)
+	}
+	if rateLimit != nil {
+		s.count(func(stats *Stats) { stats.RateLimit = *rateLimit })
+	}
+}
+
+// retryAfter returns how long a rate limited response asks to wait before
+// retrying, from its Retry-After header, in seconds or as an HTTP date, or
+// else its x-rate-limit-reset header, in epoch seconds. ok is false if
+// neither header gives a time in the future.
+func retryAfter(resp *http.Response, now time.Time) (wait time.Duration, ok bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(value); err == nil {
+			wait = at.Sub(now)
+		}
+		if wait > 0 {
+			return wait, true
+		}
+	}
+	if epoch, err := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		if wait = time.Unix(epoch, 0).Sub(now); wait > 0 {
+			return wait, true
+		}
+	}
+	return 0, false
+}
+
+// rateLimitReset returns when the rate limit of a 429 response resets.
+func rateLimitReset(resp *http.Response) time.Time {
+	now := time.Now()
+	if wait, ok := retryAfter(resp, now); ok {
+		return now.Add(wait)
+	}
+	return now.Add(rateLimitWindow)
+}
@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrReconnectRequested is the error of the EventDisconnected of a
+// connection closed by Reconnect.
+var ErrReconnectRequested = errors.New("stream: reconnect requested")
+
+// Reconnect closes the current connection, which the stream reconnects
+// right away, e.g. to apply changed parameters of the request or to refresh
+// after the rules changed. Tweets matched meanwhile are recovered if the
+// stream was configured WithAutoBackfill or WithGapRecovery; see Rotate to
+// avoid the gap instead. Reconnect does nothing while the stream is not
+// connected.
+func (s *Stream) Reconnect() {
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	if s.body != nil {
+		atomic.StoreInt32(&s.reconnecting, 1)
+		s.body.Close()
+	}
+}
+
+// setBody sets the body of the current connection, closed by Stop and
+// Reconnect.
+func (s *Stream) setBody(body io.Closer) {
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	atomic.StoreInt32(&s.reconnecting, 0)
+	s.body = body
+}
+
+// closeBody closes the body of the current connection, if any.
+func (s *Stream) closeBody() {
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	if s.body != nil {
+		s.body.Close()
+	}
+}
+
+// reconnectRequested reports whether the connection was closed by
+// Reconnect.
+func (s *Stream) reconnectRequested() bool {
+	return atomic.CompareAndSwapInt32(&s.reconnecting, 1, 0)
+}
@@ -0,0 +1,152 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// recordedLine is a line of a recording: a raw stream message, or an empty
+// keep-alive, and when it was received.
+type recordedLine struct {
+	At   time.Time `json:"at"`
+	Line string    `json:"line"`
+}
+
+// recorder writes the recording of the streams of a service.
+type recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithRecorder records the raw messages and keep-alives of streams, as
+// received and before decoding, to w as newline delimited JSON objects with
+// the time each was received, e.g.
+//
+//	{"at":"2024-05-01T12:00:00.123Z","line":"{\"data\":{\"id\":\"1\"}}"}
+//
+// for ConnectReplay to replay them. Streams of the service share w, so
+// their lines interleave. Failed writes are sent on Errors.
+func WithRecorder(w io.Writer) Option {
+	r := &recorder{w: w}
+	return func(c *config) {
+		c.recorder = r
+	}
+}
+
+// record writes a received line to the recording, if any.
+func (s *Stream) record(data []byte) {
+	r := s.config.recorder
+	if r == nil {
+		return
+	}
+	line, err := json.Marshal(recordedLine{At: time.Now().UTC(), Line: string(data)})
+	if err == nil {
+		r.mu.Lock()
+		_, err = r.w.Write(append(line, '\n'))
+		r.mu.Unlock()
+	}
+	if err != nil {
+		s.sendError(fmt.Errorf("stream: record: %w", err))
+	}
+}
+
+// ConnectReplay replays a recording made WithRecorder through a Stream, so
+// its messages are decoded, filtered and delivered as if they were
+// received, e.g. to reproduce a decoding failure or load test a consumer
+// offline. Lines are replayed at speed times their original pace, or as fast
+// as possible if speed is 0. The stream stops once the recording ends, or
+// with a *ReplayError if it is malformed. The token of the service is not
+// used.
+func (srv *StreamService) ConnectReplay(ctx context.Context, recording io.Reader, speed float64) (*Stream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.endpoint(streamV2Path)+"/stream", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: &replayTransport{recording: recording, speed: speed}}
+	s := newStream(ctx, client, req, srv.config)
+	s.srv = srv
+	s.replaying = true
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReplayError is the error of a stream replaying a malformed recording.
+type ReplayError struct {
+	Line int
+	Err  error
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("stream: replay: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
+
+// replayTransport answers the connect attempt of a replaying stream with
+// the recording as the body.
+type replayTransport struct {
+	recording io.Reader
+	speed     float64
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, w := io.Pipe()
+	go t.replay(req.Context(), w)
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+// replay writes the lines of the recording to w at their pace, and closes
+// w once done.
+func (t *replayTransport) replay(ctx context.Context, w *io.PipeWriter) {
+	scanner := bufio.NewScanner(t.recording)
+	scanner.Buffer(nil, 64<<20)
+	var previous time.Time
+	n := 0
+	for scanner.Scan() {
+		n++
+		var line recordedLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			w.CloseWithError(&ReplayError{Line: n, Err: err})
+			return
+		}
+		if t.speed > 0 && !previous.IsZero() && line.At.After(previous) {
+			wait := time.Duration(float64(line.At.Sub(previous)) / t.speed)
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				w.CloseWithError(ctx.Err())
+				return
+			}
+		}
+		previous = line.At
+		if _, err := io.WriteString(w, line.Line+"\r\n"); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		w.CloseWithError(&ReplayError{Line: n + 1, Err: err})
+		return
+	}
+	w.Close()
+}
@@ -0,0 +1,136 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// WithGapRecovery makes filtered streams search the Tweets they missed
+// while disconnected for longer than minGap: after reconnecting, the recent
+// search endpoint is queried with each active rule for Tweets newer than the
+// last Tweet received. The searches run while the new connection delivers,
+// and once they are done the results are delivered on Messages with
+// Recovered set, oldest first and once per Tweet, with the rules of all
+// searches which found it. Recovery is skipped for downtimes which
+// WithAutoBackfill already covers.
+func WithGapRecovery(minGap time.Duration) Option {
+	return func(c *config) {
+		c.gapRecovery = minGap
+	}
+}
+
+// needsRecovery reports whether a reconnect after disconnectedAt should
+// recover the gap by searching.
+func (s *Stream) needsRecovery(disconnectedAt time.Time) bool {
+	if !s.filtered || s.config.gapRecovery <= 0 || disconnectedAt.IsZero() || s.lastTweetID == "" {
+		return false
+	}
+	gap := time.Since(disconnectedAt)
+	if s.config.autoBackfill && gap <= maxBackfillMinutes*time.Minute {
+		return false
+	}
+	return gap >= s.config.gapRecovery
+}
+
+// recoverGap searches the Tweets matching the active rules since the Tweet
+// sinceID and hands them to the stream goroutine, see deliverRecovered. It
+// runs concurrently with the stream goroutine, so it only uses the service
+// and the parameters of the stream, and returns early when the stream is
+// stopped.
+func (s *Stream) recoverGap(sinceID string) {
+	var errs []error
+	found := make(map[string]*StreamData)
+	defer func() {
+		recovered := make([]*StreamData, 0, len(found))
+		for _, msg := range found {
+			recovered = append(recovered, msg)
+		}
+		sort.Slice(recovered, func(i, j int) bool {
+			return olderID(recovered[i].Tweet.ID, recovered[j].Tweet.ID)
+		})
+		s.recoveryMu.Lock()
+		s.recovered = append(s.recovered, recovered...)
+		s.recoveryErrs = append(s.recoveryErrs, errs...)
+		s.recoveryMu.Unlock()
+	}()
+	ctx := s.req.Context()
+	rules, err := s.srv.GetRules(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("stream: gap recovery: %w", err))
+		return
+	}
+	for _, rule := range rules {
+		search := &SearchParams{
+			Query:      rule.Value,
+			SinceID:    sinceID,
+			MaxResults: 100,
+		}
+		if s.params != nil {
+			search.StreamFilterParams = *s.params
+			search.StreamFilterParams.Partition = 0
+			search.StreamFilterParams.BackfillMinutes = 0
+		}
+		for {
+			resp, err := s.srv.Search(ctx, SearchRecent, search)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				errs = append(errs, fmt.Errorf("stream: gap recovery of rule %q: %w", rule.Value, err))
+				break
+			}
+			for _, tweet := range resp.Data {
+				matching := &MatchingRule{Id: rule.ID, Tag: rule.Tag}
+				if msg, ok := found[tweet.ID]; ok {
+					// found by the search of another rule
+					msg.MatchingRules = append(msg.MatchingRules, matching)
+					continue
+				}
+				found[tweet.ID] = &StreamData{
+					Tweet:         tweet,
+					Includes:      resp.Includes,
+					MatchingRules: []*MatchingRule{matching},
+					Recovered:     true,
+				}
+			}
+			if resp.Meta.NextToken == "" {
+				break
+			}
+			search.NextToken = resp.Meta.NextToken
+		}
+	}
+}
+
+// deliverRecovered delivers the Tweets and sends the errors of finished gap
+// recoveries. It returns context.Canceled if the stream was stopped
+// meanwhile.
+func (s *Stream) deliverRecovered() error {
+	s.recoveryMu.Lock()
+	recovered, errs := s.recovered, s.recoveryErrs
+	s.recovered, s.recoveryErrs = nil, nil
+	s.recoveryMu.Unlock()
+	for _, err := range errs {
+		s.sendError(err)
+	}
+	for _, msg := range recovered {
+		s.config.tagMapper.Apply(msg)
+		if !s.accept(msg) || s.duplicate(msg) {
+			continue
+		}
+		if s.process(msg) == context.Canceled {
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// olderID reports whether the Tweet ID a is older than b. IDs are numbers
+// increasing with time, so a shorter ID is older.
+func olderID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,73 @@
+package stream
+
+import "time"
+
+// WithReplay keeps the last size messages fanned out to subscriptions in a
+// ring buffer, so a consumer which restarts can resume with SubscribeFrom or
+// SubscribeSince without asking the API again.
+func WithReplay(size int) Option {
+	return func(c *config) {
+		c.replaySize = size
+	}
+}
+
+// replayed is a message kept for replay.
+type replayed struct {
+	msg *StreamData
+	at  time.Time
+}
+
+// ring is a ring buffer of the last messages of a stream. It is guarded by
+// the subMu of the stream.
+type ring struct {
+	entries []replayed
+	next    int
+	full    bool
+}
+
+func (r *ring) add(msg *StreamData, at time.Time) {
+	r.entries[r.next] = replayed{msg: msg, at: at}
+	r.next = (r.next + 1) % len(r.entries)
+	r.full = r.full || r.next == 0
+}
+
+// since returns the kept messages for which keep is true, oldest first.
+func (r *ring) since(keep func(replayed) bool) []*StreamData {
+	var msgs []*StreamData
+	start, n := 0, r.next
+	if r.full {
+		start, n = r.next, len(r.entries)
+	}
+	for i := 0; i < n; i++ {
+		entry := r.entries[(start+i)%len(r.entries)]
+		if keep(entry) {
+			msgs = append(msgs, entry.msg)
+		}
+	}
+	return msgs
+}
+
+// Sequence returns the sequence number of a message fanned out to
+// subscriptions, counting from 1, for resuming with SubscribeFrom. It is
+// zero for messages read from Messages directly.
+func (d *StreamData) Sequence() uint64 {
+	return d.seq
+}
+
+// SubscribeFrom subscribes like Subscribe, first replaying the kept messages
+// with a sequence number of seq or later, e.g. the Sequence of the last
+// message processed before a restart plus one. Without WithReplay it is
+// Subscribe.
+func (s *Stream) SubscribeFrom(seq uint64, buffer int, overflow Overflow) *Subscription {
+	return s.subscribe(buffer, overflow, func(entry replayed) bool {
+		return entry.msg.seq >= seq
+	})
+}
+
+// SubscribeSince subscribes like Subscribe, first replaying the kept
+// messages fanned out at t or later.
+func (s *Stream) SubscribeSince(t time.Time, buffer int, overflow Overflow) *Subscription {
+	return s.subscribe(buffer, overflow, func(entry replayed) bool {
+		return !entry.at.Before(t)
+	})
+}
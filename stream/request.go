@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+)
+
+// endpoint returns the URL of the API path at baseURL, or at DefaultBaseURL
+// if baseURL is empty.
+func endpoint(baseURL, path string) string {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + path
+}
+
+// endpoint returns the URL of the API path.
+func (srv *StreamService) endpoint(path string) string {
+	return endpoint(srv.config.baseURL, path)
+}
+
+// newRequest returns a request to url authenticated with token, with params
+// encoded as the query and body, if not nil, as the JSON body. A nil params
+// interface adds no query.
+func newRequest(ctx context.Context, method, url, token string, params interface{}, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if params != nil {
+		q, err := query.Values(params)
+		if err != nil {
+			return nil, err
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	return req, nil
+}
+
+// do sends an authenticated request, see newRequest. With a TokenProvider,
+// a request answered with 429 Too Many Requests is retried with the next
+// token, until no other token is available.
+func (srv *StreamService) do(ctx context.Context, method, url string, params interface{}, body interface{}) (*http.Response, error) {
+	tried := map[string]bool{}
+	for {
+		token, err := srv.currentToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req, err := newRequest(ctx, method, url, token, params, body)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := srv.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		srv.observeRateLimit(resp)
+		provider := srv.config.tokenProvider
+		if resp.StatusCode != http.StatusTooManyRequests || provider == nil {
+			return resp, nil
+		}
+		tried[token] = true
+		provider.RateLimited(token, rateLimitReset(resp))
+		next, err := provider.Token(ctx)
+		if err != nil || tried[next] {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+}
+
+// rateLimited is implemented by responses carrying the rate limit of their
+// endpoint.
+type rateLimited interface {
+	setRateLimit(rateLimit *RateLimit)
+}
+
+// getJSON sends an authenticated GET request to url with params as the query
+// and decodes the response into v, setting its rate limit if v is
+// rateLimited. Non-200 responses are returned as a StatusError.
+func (srv *StreamService) getJSON(ctx context.Context, url string, params interface{}, v interface{}) error {
+	resp, err := srv.do(ctx, http.MethodGet, url, params, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp)
+	}
+	if r, ok := v.(rateLimited); ok {
+		r.setRateLimit(parseRateLimit(resp))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,162 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rotationDrain is how long the old connection keeps delivering after
+	// the new one connected, for the Tweets it had sent before to arrive.
+	rotationDrain = 3 * time.Second
+	// rotationBridge is how long after a rotation the Tweets delivered
+	// around it are remembered, and rotationDedupSize how many, to suppress
+	// those sent on both connections when the stream is not WithDedup.
+	rotationBridge    = time.Minute
+	rotationDedupSize = 50000
+)
+
+// ErrNotConnected is returned by Rotate while the stream is not connected.
+var ErrNotConnected = errors.New("stream: not connected")
+
+// errRotated ends the receiving of a connection replaced by Rotate.
+var errRotated = errors.New("stream: connection rotated")
+
+// Rotate replaces the connection without a gap, e.g. after the rules
+// changed: it opens a new connection, and once that is answered with 200 OK
+// the old one keeps delivering for a few seconds before it is closed and the
+// stream switches over. Tweets sent on both connections meanwhile are
+// delivered once, and counted in Stats.Duplicates. The handover is reported
+// with an EventRotated.
+//
+// Rotate needs the app to be allowed a second connection, which only some
+// access levels are; otherwise it returns the StatusError of the rejected
+// attempt, 429 Too Many Requests, and Reconnect is the alternative. It
+// returns ErrNotConnected while the stream is not connected, and ctx bounds
+// the connect attempt.
+func (s *Stream) Rotate(ctx context.Context) error {
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+	if !s.connected() {
+		return ErrNotConnected
+	}
+	// remember delivered Tweets from now on, the new connection may send
+	// them again
+	atomic.StoreInt32(&s.bridging, 1)
+	defer atomic.StoreInt32(&s.bridging, 0)
+	resp, err := s.dialRotation(ctx)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return newStatusError(resp)
+	}
+	s.bodyMu.Lock()
+	if stopped(s.done) || !s.connected() {
+		s.bodyMu.Unlock()
+		resp.Body.Close()
+		return ErrNotConnected
+	}
+	s.next = resp
+	old := s.body
+	s.bodyMu.Unlock()
+	// drain the old connection, whose Tweets the new one does not resend
+	sleepOrDone(rotationDrain, s.done)
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	// the stream goroutine switched over already if the old connection was
+	// lost meanwhile, and the current body is that of the new one
+	if s.body == old && old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// dialRotation makes the connect attempt of a rotation, with the request of
+// the stream but without backfill, which the old connection makes
+// unnecessary. The attempt is aborted if ctx is done before it is answered.
+func (s *Stream) dialRotation(ctx context.Context) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(s.req.Context())
+	stop := context.AfterFunc(ctx, cancel)
+	s.reqMu.Lock()
+	req := s.req.Clone(reqCtx)
+	s.reqMu.Unlock()
+	query := req.URL.Query()
+	if query.Has("backfill_minutes") {
+		query.Del("backfill_minutes")
+		req.URL.RawQuery = query.Encode()
+	}
+	resp, err := s.client.Do(req)
+	if !stop() {
+		// ctx was done, the attempt or its response is aborted
+		if err == nil {
+			resp.Body.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		return nil, &ConnectionError{Err: err}
+	}
+	s.observeRateLimit(resp)
+	// the response body is read until the stream stops, which cancels
+	// reqCtx with the context of the stream
+	return resp, nil
+}
+
+// connected reports whether the last event of the connection was
+// EventConnected.
+func (s *Stream) connected() bool {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats.State == EventConnected
+}
+
+// rotationPending reports whether Rotate handed over a new connection.
+func (s *Stream) rotationPending() bool {
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	return s.next != nil
+}
+
+// takeRotation returns the connection handed over by Rotate, if any.
+func (s *Stream) takeRotation() *http.Response {
+	s.bodyMu.Lock()
+	defer s.bodyMu.Unlock()
+	next := s.next
+	s.next = nil
+	return next
+}
+
+// rotated switches the stream goroutine to the connection handed over by
+// Rotate, if any, and returns it.
+func (s *Stream) rotated() *http.Response {
+	next := s.takeRotation()
+	if next == nil {
+		return nil
+	}
+	s.bridgeUntil = time.Now().Add(rotationBridge)
+	s.count(func(stats *Stats) { stats.Rotations++ })
+	s.emit(Event{Type: EventRotated})
+	return next
+}
+
+// bridgeDedup returns the dedup remembering the Tweets delivered around a
+// rotation, or nil outside of rotations. It is only used by the stream
+// goroutine.
+func (s *Stream) bridgeDedup(now time.Time) *dedup {
+	switch {
+	case atomic.LoadInt32(&s.bridging) == 1:
+		if s.bridge == nil {
+			s.bridge = newDedup(rotationDedupSize, 0)
+		}
+		s.bridgeUntil = now.Add(rotationBridge)
+	case s.bridge != nil && now.After(s.bridgeUntil):
+		s.bridge = nil
+	}
+	return s.bridge
+}
@@ -0,0 +1,119 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name string
+		// rejectSecond makes the server refuse a second connection
+		rejectSecond bool
+		// prepare runs before Rotate, and returns its context
+		prepare       func(s *stream.Stream) context.Context
+		wantErr       error
+		wantTweets    []string
+		wantRotations uint64
+	}{
+		{
+			name:          "handover",
+			wantTweets:    []string{"2", "3"},
+			wantRotations: 1,
+		},
+		{
+			name:         "second connection rejected",
+			rejectSecond: true,
+			wantErr:      stream.ErrTooManyConnections,
+			wantTweets:   []string{"2"},
+		},
+		{
+			name: "context done",
+			prepare: func(s *stream.Stream) context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "stopped",
+			prepare: func(s *stream.Stream) context.Context {
+				s.Stop()
+				return context.Background()
+			},
+			wantErr: stream.ErrNotConnected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			rotating := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch attempts.Add(1) {
+				case 1:
+					w.Write([]byte(`{"data":{"id":"1","text":"first"}}` + "\r\n"))
+					w.(http.Flusher).Flush()
+					// Tweet 2 is sent on both connections
+					select {
+					case <-rotating:
+						w.Write([]byte(`{"data":{"id":"2","text":"both"}}` + "\r\n"))
+						w.(http.Flusher).Flush()
+					case <-r.Context().Done():
+					}
+				case 2:
+					if tt.rejectSecond {
+						w.WriteHeader(http.StatusTooManyRequests)
+						w.Write([]byte(tooManyConnections))
+						close(rotating)
+						return
+					}
+					w.Write([]byte(`{"data":{"id":"2","text":"both"}}` + "\r\n" + `{"data":{"id":"3","text":"second"}}` + "\r\n"))
+					w.(http.Flusher).Flush()
+					close(rotating)
+				}
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+
+			service := stream.NewStreamService(srv.Client(), "token",
+				stream.WithBaseURL(srv.URL),
+				stream.WithLogger(quiet()),
+			)
+			s, err := service.Connect(context.Background(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Stop()
+			if id := nextTweet(t, s); id != "1" {
+				t.Fatalf("delivered Tweet %s, want 1", id)
+			}
+
+			ctx := context.Background()
+			if tt.prepare != nil {
+				ctx = tt.prepare(s)
+			}
+			if err := s.Rotate(ctx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rotate: err = %v, want %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantTweets {
+				if id := nextTweet(t, s); id != want {
+					t.Errorf("delivered Tweet %s, want %s", id, want)
+				}
+			}
+			stats := s.Stats()
+			if stats.Rotations != tt.wantRotations {
+				t.Errorf("%d rotations, want %d", stats.Rotations, tt.wantRotations)
+			}
+			if tt.wantRotations > 0 && stats.Duplicates != 1 {
+				t.Errorf("%d duplicates, want Tweet 2 suppressed once", stats.Duplicates)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Router demultiplexes messages by the tags of their matching rules into the
+// channels registered for each tag, e.g.
+//
+//	r := stream.NewRouter()
+//	news := r.Route("news", 16)
+//	other := r.Default(16)
+//	go r.Run(s.Messages)
+//
+// A message is delivered once to each channel registered for any of its
+// tags, and to the default channel if none of its tags are registered.
+// Sends block, so a slow channel holds back the others.
+type Router struct {
+	mu       sync.Mutex
+	routes   map[string][]chan *StreamData
+	all      []chan *StreamData
+	fallback chan *StreamData
+	unrouted uint64
+}
+
+// NewRouter returns a Router without routes.
+func NewRouter() *Router {
+	return &Router{routes: make(map[string][]chan *StreamData)}
+}
+
+// Route returns a channel receiving the messages matching a rule tagged
+// tag, buffering up to buffer messages. Routes must be registered before
+// Run.
+func (r *Router) Route(tag string, buffer int) <-chan *StreamData {
+	ch := make(chan *StreamData, buffer)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[tag] = append(r.routes[tag], ch)
+	r.all = append(r.all, ch)
+	return ch
+}
+
+// Default returns the channel receiving the messages matching none of the
+// routed tags. Without it, such messages are dropped and counted by
+// Unrouted.
+func (r *Router) Default(buffer int) <-chan *StreamData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fallback == nil {
+		r.fallback = make(chan *StreamData, buffer)
+		r.all = append(r.all, r.fallback)
+	}
+	return r.fallback
+}
+
+// Run routes the messages of in until it is closed, then closes all
+// channels of the Router.
+func (r *Router) Run(in <-chan *StreamData) {
+	r.mu.Lock()
+	routes, fallback, all := r.routes, r.fallback, r.all
+	r.mu.Unlock()
+	defer func() {
+		for _, ch := range all {
+			close(ch)
+		}
+	}()
+	sent := make(map[chan *StreamData]bool)
+	for msg := range in {
+		for ch := range sent {
+			delete(sent, ch)
+		}
+		for _, rule := range msg.MatchingRules {
+			for _, ch := range routes[rule.Tag] {
+				if !sent[ch] {
+					sent[ch] = true
+					ch <- msg
+				}
+			}
+		}
+		if len(sent) > 0 {
+			continue
+		}
+		if fallback == nil {
+			atomic.AddUint64(&r.unrouted, 1)
+			continue
+		}
+		fallback <- msg
+	}
+}
+
+// Unrouted returns the number of messages dropped for matching no route
+// without a default channel.
+func (r *Router) Unrouted() uint64 {
+	return atomic.LoadUint64(&r.unrouted)
+}
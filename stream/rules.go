@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Problem types Twitter reports for rules which could not be created.
+const (
+	ProblemInvalidRules   = "https://api.twitter.com/2/problems/invalid-rules"
+	ProblemDuplicateRules = "https://api.twitter.com/2/problems/duplicate-rules"
+	ProblemRuleCap        = "https://api.twitter.com/2/problems/rule-cap"
+)
+
+// Rule is a filtered stream rule. ID is assigned by Twitter once the rule has
+// been created and is empty for rules that are about to be added.
+type Rule struct {
+	ID    string `json:"id,omitempty"`
+	Value string `json:"value"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// RulesSummary counts the outcome of an add or delete rules request.
+type RulesSummary struct {
+	Created    int `json:"created"`
+	NotCreated int `json:"not_created"`
+	Valid      int `json:"valid"`
+	Invalid    int `json:"invalid"`
+	Deleted    int `json:"deleted"`
+	NotDeleted int `json:"not_deleted"`
+}
+
+// RulesMeta is the meta object of a rules response.
+type RulesMeta struct {
+	Sent        string        `json:"sent"`
+	ResultCount int           `json:"result_count,omitempty"`
+	Summary     *RulesSummary `json:"summary,omitempty"`
+}
+
+// RulesResponse is the response of the rules endpoint.
+type RulesResponse struct {
+	Data   []*Rule      `json:"data,omitempty"`
+	Meta   *RulesMeta   `json:"meta,omitempty"`
+	Errors []*RuleError `json:"errors,omitempty"`
+	// RateLimit is the rate limit of the rules endpoint as of the
+	// response, nil if it had no rate limit headers.
+	RateLimit *RateLimit `json:"-"`
+}
+
+// RuleError describes why Twitter refused a single rule, e.g. an invalid
+// operator, a rule exceeding the length limit or a duplicate of an existing
+// rule.
+type RuleError struct {
+	ID      string   `json:"id,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	Title   string   `json:"title"`
+	Type    string   `json:"type"`
+	Detail  string   `json:"detail,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+func (e *RuleError) Error() string {
+	msg := e.Title
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if len(e.Details) > 0 {
+		msg += ": " + strings.Join(e.Details, "; ")
+	}
+	if e.Value != "" {
+		return fmt.Sprintf("rule %q: %s", e.Value, msg)
+	}
+	return msg
+}
+
+// IsInvalid reports whether the rule was rejected for its syntax, such as an
+// unknown operator or a value exceeding the length limit.
+func (e *RuleError) IsInvalid() bool {
+	return e.Type == ProblemInvalidRules
+}
+
+// IsDuplicate reports whether the rule already exists.
+func (e *RuleError) IsDuplicate() bool {
+	return e.Type == ProblemDuplicateRules
+}
+
+// RuleErrors is returned when Twitter rejected one or more rules of a request.
+type RuleErrors []*RuleError
+
+func (errs RuleErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// RulesParams are the query parameters of an add or delete rules request.
+// With DryRun set, Twitter validates the request without applying it.
+type RulesParams struct {
+	DryRun bool `url:"dry_run,omitempty"`
+}
+
+// GetRules returns the rules currently applied to the filtered stream.
+func (srv *StreamService) GetRules(ctx context.Context) ([]*Rule, error) {
+	resp, err := srv.doRules(ctx, http.MethodGet, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	srv.setRuleCount(len(resp.Data))
+	return resp.Data, nil
+}
+
+// AddRules adds rules to the filtered stream. If Twitter refuses any of the
+// rules, the response is returned together with a RuleErrors error. With
+// WithRuleQuota, rules exceeding the quota are not sent, returning a
+// QuotaError.
+func (srv *StreamService) AddRules(ctx context.Context, rules []*Rule, params *RulesParams) (*RulesResponse, error) {
+	if err := srv.checkQuota(ctx, rules); err != nil {
+		return nil, err
+	}
+	body := struct {
+		Add []*Rule `json:"add"`
+	}{rules}
+	resp, err := srv.doRules(ctx, http.MethodPost, params, body)
+	if params == nil || !params.DryRun {
+		srv.countRules(resp, err)
+	}
+	return resp, err
+}
+
+// ValidateRules checks the syntax of rules with a dry run, without applying
+// them to the stream.
+func (srv *StreamService) ValidateRules(ctx context.Context, rules []*Rule) (*RulesResponse, error) {
+	return srv.AddRules(ctx, rules, &RulesParams{DryRun: true})
+}
+
+// DeleteRules deletes the rules with the given IDs from the filtered stream.
+func (srv *StreamService) DeleteRules(ctx context.Context, ids []string, params *RulesParams) (*RulesResponse, error) {
+	body := struct {
+		Delete struct {
+			IDs []string `json:"ids"`
+		} `json:"delete"`
+	}{}
+	body.Delete.IDs = ids
+	resp, err := srv.doRules(ctx, http.MethodPost, params, body)
+	if params == nil || !params.DryRun {
+		srv.countRules(resp, err)
+	}
+	return resp, err
+}
+
+// doRules sends a rules request and decodes the response. Per-rule errors
+// are returned as RuleErrors alongside the decoded response.
+func (srv *StreamService) doRules(ctx context.Context, method string, params *RulesParams, body interface{}) (*RulesResponse, error) {
+	url := fmt.Sprintf("%s/%s", srv.endpoint(streamV2Path), "stream/rules")
+	var resp *http.Response
+	var err error
+	if params == nil {
+		resp, err = srv.do(ctx, method, url, nil, body)
+	} else {
+		resp, err = srv.do(ctx, method, url, params, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, newStatusError(resp)
+	}
+	rulesResp := &RulesResponse{RateLimit: parseRateLimit(resp)}
+	if err := json.NewDecoder(resp.Body).Decode(rulesResp); err != nil {
+		return nil, err
+	}
+	if len(rulesResp.Errors) > 0 {
+		return rulesResp, RuleErrors(rulesResp.Errors)
+	}
+	return rulesResp, nil
+}
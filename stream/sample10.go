@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Sample10Partitions is the number of partitions of the 10% sampled stream.
+const Sample10Partitions = 2
+
+// ConnectSample10 starts a Stream receiving a partition, from 1 to
+// Sample10Partitions, of the 10% sampled stream. Access to sample10 requires
+// an academic or enterprise project.
+func (srv *StreamService) ConnectSample10(ctx context.Context, partition int, params *StreamFilterParams) (*Stream, error) {
+	if partition < 1 || partition > Sample10Partitions {
+		return nil, &ParameterError{
+			Parameter: "partition",
+			Values:    []string{fmt.Sprint(partition)},
+			Message:   fmt.Sprintf("must be between 1 and %d", Sample10Partitions),
+		}
+	}
+	p := StreamFilterParams{}
+	if params != nil {
+		p = *params
+	}
+	p.Partition = partition
+	return srv.connect(ctx, sample10V2Path, &p)
+}
+
+// ConnectSample10All connects to every partition of the 10% sampled stream
+// and merges them into a single MultiStream.
+func (srv *StreamService) ConnectSample10All(ctx context.Context, params *StreamFilterParams) (*MultiStream, error) {
+	streams := make([]*Stream, 0, Sample10Partitions)
+	for partition := 1; partition <= Sample10Partitions; partition++ {
+		s, err := srv.ConnectSample10(ctx, partition, params)
+		if err != nil {
+			for _, s := range streams {
+				s.Stop()
+			}
+			return nil, fmt.Errorf("stream: connect partition %d: %w", partition, err)
+		}
+		streams = append(streams, s)
+	}
+	return newMultiStream(streams), nil
+}
+
+// MultiStream merges the Messages and Errors of several streams, e.g. the
+// partitions of a partitioned stream. Both channels are closed once all
+// streams stopped.
+type MultiStream struct {
+	Messages <-chan *StreamData
+	Errors   <-chan error
+	Streams  []*Stream
+	group    *sync.WaitGroup
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newMultiStream(streams []*Stream) *MultiStream {
+	messages := make(chan *StreamData)
+	errs := make(chan error, errorsBufferSize)
+	m := &MultiStream{
+		Messages: messages,
+		Errors:   errs,
+		Streams:  streams,
+		group:    &sync.WaitGroup{},
+		done:     make(chan struct{}),
+	}
+	for _, s := range streams {
+		m.group.Add(2)
+		go func(s *Stream) {
+			defer m.group.Done()
+			for msg := range s.Messages {
+				select {
+				case messages <- msg:
+				case <-m.done:
+					// allow Stop(), even if not receiving
+				}
+			}
+		}(s)
+		go func(s *Stream) {
+			defer m.group.Done()
+			for err := range s.Errors {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}(s)
+	}
+	go func() {
+		m.group.Wait()
+		close(messages)
+		close(errs)
+	}()
+	return m
+}
+
+// Stop stops all streams and blocks until they are done.
+func (m *MultiStream) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+	var wg sync.WaitGroup
+	for _, s := range m.Streams {
+		wg.Add(1)
+		go func(s *Stream) {
+			defer wg.Done()
+			s.Stop()
+		}(s)
+	}
+	wg.Wait()
+}
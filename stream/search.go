@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// recentSearchWindow is how far back the recent search endpoint reaches.
+// Older ranges need the full-archive search endpoint.
+const recentSearchWindow = 7 * 24 * time.Hour
+
+// Search archives. SearchRecent covers the last seven days, SearchAll the
+// full archive and requires academic or enterprise access.
+const (
+	SearchRecent = "recent"
+	SearchAll    = "all"
+)
+
+// SearchParams are the query parameters of a search request. The embedded
+// StreamFilterParams request the same fields and expansions as the stream.
+type SearchParams struct {
+	Query      string     `url:"query"`
+	StartTime  *time.Time `url:"start_time,omitempty"`
+	EndTime    *time.Time `url:"end_time,omitempty"`
+	SinceID    string     `url:"since_id,omitempty"`
+	UntilID    string     `url:"until_id,omitempty"`
+	MaxResults int        `url:"max_results,omitempty"`
+	NextToken  string     `url:"next_token,omitempty"`
+	StreamFilterParams
+}
+
+// SearchMeta is the meta object of a search response.
+type SearchMeta struct {
+	NewestID    string `json:"newest_id"`
+	OldestID    string `json:"oldest_id"`
+	ResultCount int    `json:"result_count"`
+	NextToken   string `json:"next_token"`
+}
+
+// SearchResponse is a page of search results.
+type SearchResponse struct {
+	Data     []*Tweet   `json:"data"`
+	Includes *Includes  `json:"includes,omitempty"`
+	Meta     SearchMeta `json:"meta"`
+}
+
+// Search returns a page of Tweets of the archive, SearchRecent or SearchAll,
+// matching params.Query. Pass Meta.NextToken as params.NextToken to request
+// the next page.
+func (srv *StreamService) Search(ctx context.Context, archive string, params *SearchParams) (*SearchResponse, error) {
+	url := fmt.Sprintf("%s/%s", srv.endpoint(streamV2Path), archive)
+	searchResp := &SearchResponse{}
+	if err := srv.getJSON(ctx, url, params, searchResp); err != nil {
+		return nil, err
+	}
+	return searchResp, nil
+}
+
+// Backfill searches the Tweets matching each of the rules which were created
+// within [from, to) and passes them to fn as StreamData, with the rule as the
+// matching rule, so historic Tweets can be processed like streamed ones. The
+// recent search endpoint is used when it covers the range, the full-archive
+// endpoint otherwise. Backfill stops at the first error of fn.
+func (srv *StreamService) Backfill(ctx context.Context, rules []*Rule, from, to time.Time, params *StreamFilterParams, fn func(*StreamData) error) error {
+	archive := SearchRecent
+	if time.Since(from) > recentSearchWindow {
+		archive = SearchAll
+	}
+	if params == nil {
+		params = &StreamFilterParams{}
+	}
+	for _, rule := range rules {
+		search := &SearchParams{
+			Query:              rule.Value,
+			StartTime:          &from,
+			EndTime:            &to,
+			MaxResults:         100,
+			StreamFilterParams: *params,
+		}
+		for {
+			resp, err := srv.Search(ctx, archive, search)
+			if err != nil {
+				return fmt.Errorf("stream: backfill rule %q: %w", rule.Value, err)
+			}
+			for _, tweet := range resp.Data {
+				// the includes of a page are shared by all of its Tweets
+				msg := &StreamData{
+					Tweet:         tweet,
+					Includes:      resp.Includes,
+					MatchingRules: []*MatchingRule{{Id: rule.ID, Tag: rule.Tag}},
+				}
+				srv.config.tagMapper.Apply(msg)
+				if err := fn(msg); err != nil {
+					return err
+				}
+			}
+			if resp.Meta.NextToken == "" {
+				break
+			}
+			search.NextToken = resp.Meta.NextToken
+		}
+	}
+	return nil
+}
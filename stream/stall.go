@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStallTimeout is how long a connection may stay silent before it is
+// considered dead. Twitter sends a keep-alive every 20 seconds.
+const defaultStallTimeout = 30 * time.Second
+
+// WithStallTimeout sets how long a connection may receive neither messages
+// nor keep-alives before it is torn down and reconnected, 30 seconds by
+// default. A negative timeout disables stall detection.
+func WithStallTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.stallTimeout = timeout
+	}
+}
+
+// StallError is sent on the Errors channel when a connection stalled, e.g. a
+// half-open socket, and is reconnected.
+type StallError struct {
+	Timeout time.Duration
+}
+
+func (e *StallError) Error() string {
+	return fmt.Sprintf("stream: connection stalled, nothing received for %v", e.Timeout)
+}
+
+// stallWatch closes the body of a connection which received nothing within
+// the timeout, unblocking the read.
+type stallWatch struct {
+	timeout time.Duration
+	timer   *time.Timer
+	fired   int32
+}
+
+// watchStall starts watching the body for stalls.
+func (s *Stream) watchStall(body io.Closer) *stallWatch {
+	w := &stallWatch{timeout: s.config.stallTimeout}
+	if w.timeout == 0 {
+		w.timeout = defaultStallTimeout
+	}
+	if w.timeout < 0 {
+		return w
+	}
+	w.timer = time.AfterFunc(w.timeout, func() {
+		atomic.StoreInt32(&w.fired, 1)
+		body.Close()
+	})
+	return w
+}
+
+// reset restarts the timeout after receiving data.
+func (w *stallWatch) reset() {
+	if w.timer != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// stop stops watching.
+func (w *stallWatch) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// stalled reports whether the body was closed because of a stall.
+func (w *stallWatch) stalled() bool {
+	return atomic.LoadInt32(&w.fired) == 1
+}
@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// Stats is a snapshot of the counters of a Stream.
+type Stats struct {
+	// Received counts the messages read from the connection, including
+	// malformed messages and in-stream error objects.
+	Received uint64
+	// Delivered counts the messages sent on Messages, including recovered
+	// ones.
+	Delivered uint64
+	// Dropped counts received messages which were not delivered: undecodable
+	// or malformed messages, messages dropped by the overflow policy, and a
+	// message in flight when the stream stopped.
+	Dropped uint64
+	// Overflowed counts the messages dropped by the overflow policy, see
+	// WithOverflow. Messages evicted from the buffer by OverflowDropOldest
+	// were counted as Delivered, too.
+	Overflowed uint64
+	// Filtered counts the messages rejected by filters, see WithFilter.
+	Filtered uint64
+	// Duplicates counts the Tweets suppressed as duplicates, see WithDedup
+	// and Rotate.
+	Duplicates uint64
+	// Handled counts the messages run through the handler of Handle, and
+	// HandlerErrors and HandlerPanics those for which it returned an error
+	// or panicked.
+	Handled       uint64
+	HandlerErrors uint64
+	HandlerPanics uint64
+	// HandlerTimeouts counts the runs of the handler which timed out, see
+	// WithHandlerTimeout.
+	HandlerTimeouts uint64
+	// LastTweetID is the ID of the last delivered Tweet, from which a
+	// restarted consumer can recover, see WithGapRecovery.
+	LastTweetID string
+	// KeepAlives counts the empty keep-alive lines read from the connection.
+	KeepAlives uint64
+	// DecodeErrors counts the messages which could not be decoded.
+	DecodeErrors uint64
+	// Reconnects counts the reconnects by the status code of the attempt
+	// which caused them: 0 for transport errors and 200 for connections
+	// which were lost after connecting.
+	Reconnects map[int]uint64
+	// Rotations counts the connections replaced by Rotate.
+	Rotations uint64
+	// ConnectFailures counts the failed connect attempts since the stream
+	// was last connected.
+	ConnectFailures uint64
+	// RateLimit is the rate limit of connect attempts as of the last one
+	// whose response had rate limit headers, zero before.
+	RateLimit RateLimit
+	// ConnectedAt is when the stream last connected.
+	ConnectedAt time.Time
+	// State is the type of the last event of the connection, such as
+	// EventConnected.
+	State EventType
+	// BytesRead counts the bytes read from connections.
+	BytesRead uint64
+	// LastMessageAt is when the last message, and LastKeepAliveAt when the
+	// last keep-alive, was received.
+	LastMessageAt   time.Time
+	LastKeepAliveAt time.Time
+	// Backoff is the wait before the next connect attempt while the stream
+	// backs off, and zero otherwise.
+	Backoff time.Duration
+	// CircuitOpen reports whether the circuit is open, see
+	// WithCircuitBreaker.
+	CircuitOpen bool
+}
+
+// ReconnectCount returns the total number of reconnects.
+func (s Stats) ReconnectCount() uint64 {
+	var n uint64
+	for _, count := range s.Reconnects {
+		n += count
+	}
+	return n
+}
+
+// Stats returns a snapshot of the counters of the stream.
+func (s *Stream) Stats() Stats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	stats := s.stats
+	stats.Reconnects = make(map[int]uint64, len(s.stats.Reconnects))
+	for status, n := range s.stats.Reconnects {
+		stats.Reconnects[status] = n
+	}
+	return stats
+}
+
+// count updates the counters of the stream.
+func (s *Stream) count(update func(stats *Stats)) {
+	s.statsMu.Lock()
+	update(&s.stats)
+	s.statsMu.Unlock()
+}
+
+// delivered counts a message sent on Messages.
+func (s *Stream) delivered(msg *StreamData) {
+	s.count(func(stats *Stats) {
+		stats.Delivered++
+		if msg.Tweet != nil {
+			stats.LastTweetID = msg.Tweet.ID
+		}
+	})
+}
+
+// reconnect counts a reconnect caused by an attempt with the status code.
+func (s *Stream) reconnect(statusCode int) {
+	s.count(func(stats *Stats) {
+		if stats.Reconnects == nil {
+			stats.Reconnects = make(map[int]uint64)
+		}
+		stats.Reconnects[statusCode]++
+		if statusCode != http.StatusOK {
+			stats.ConnectFailures++
+		}
+	})
+}
+
+// countingReader counts the bytes read from a connection in the stats of
+// its stream.
+type countingReader struct {
+	io.Reader
+	s *Stream
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.s.count(func(stats *Stats) { stats.BytesRead += uint64(n) })
+	}
+	return n, err
+}
@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// StatusAction is what a stream does when a connect attempt is answered with
+// a status other than 200 OK.
+type StatusAction int
+
+const (
+	// StatusFail stops the stream, reporting a StatusError.
+	StatusFail StatusAction = iota + 1
+	// StatusRetry retries with backoff: rate limits are retried once they
+	// reset, as told by the Retry-After or x-rate-limit-reset header, or
+	// else with the aggressive backoff; other statuses with the exponential
+	// backoff.
+	StatusRetry
+	// StatusRefreshToken obtains a new bearer token from the TokenRefresher
+	// and retries once, then fails if the status persists. Without a
+	// TokenRefresher it behaves like StatusFail.
+	StatusRefreshToken
+)
+
+// StatusPolicy is the treatment of a status code. Alert, if set, is called
+// with the StatusError of every response with the status, whatever the
+// action, e.g. to page an operator.
+type StatusPolicy struct {
+	Action StatusAction
+	Alert  func(err *StatusError)
+}
+
+// TokenRefresher returns a new bearer token, e.g. by requesting one with the
+// consumer key and secret of the app.
+type TokenRefresher func(ctx context.Context) (string, error)
+
+// WithStatusPolicy overrides the treatment of a status code. By default 503,
+// 420 and 429 are retried, 401 and 403 refresh the token, then fail, and all
+// other statuses fail.
+func WithStatusPolicy(statusCode int, policy StatusPolicy) Option {
+	return func(c *config) {
+		policies := make(map[int]StatusPolicy, len(c.statusPolicies)+1)
+		for code, p := range c.statusPolicies {
+			policies[code] = p
+		}
+		policies[statusCode] = policy
+		c.statusPolicies = policies
+	}
+}
+
+// WithTokenRefresher sets the TokenRefresher used by StatusRefreshToken.
+func WithTokenRefresher(refresh TokenRefresher) Option {
+	return func(c *config) {
+		c.tokenRefresher = refresh
+	}
+}
+
+// statusPolicy returns the configured or default policy of the status code.
+func (c *config) statusPolicy(statusCode int) StatusPolicy {
+	if policy, ok := c.statusPolicies[statusCode]; ok {
+		return policy
+	}
+	switch statusCode {
+	case http.StatusServiceUnavailable, 420, http.StatusTooManyRequests:
+		return StatusPolicy{Action: StatusRetry}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return StatusPolicy{Action: StatusRefreshToken}
+	default:
+		return StatusPolicy{Action: StatusFail}
+	}
+}
+
+// refreshToken replaces the bearer token of the stream request with one from
+// the TokenRefresher.
+func (s *Stream) refreshToken() error {
+	if s.config.tokenRefresher == nil {
+		return fmt.Errorf("stream: no token refresher")
+	}
+	token, err := s.config.tokenRefresher(s.req.Context())
+	if err != nil {
+		return err
+	}
+	s.reqMu.Lock()
+	s.req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	s.reqMu.Unlock()
+	return nil
+}
@@ -0,0 +1,716 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-querystring/query"
+)
+
+// DefaultBaseURL is the base URL of the Twitter API, see WithBaseURL.
+const DefaultBaseURL = "https://api.twitter.com"
+
+const (
+	streamV2Path       = "/2/tweets/search"
+	sampleStreamV2Path = "/2/tweets/sample"
+	sample10V2Path     = "/2/tweets/sample10"
+)
+
+// StreamService connects to the filtered stream and manages its rules.
+type StreamService struct {
+	client  *http.Client
+	tokenMu sync.RWMutex
+	token   string
+	config  config
+	// the number of rules of the stream, see RuleCount
+	rulesMu        sync.Mutex
+	ruleCount      int
+	ruleCountKnown bool
+	// the latest rate limits by endpoint, see RateLimits
+	rateLimitsMu sync.Mutex
+	rateLimits   map[string]RateLimit
+}
+
+// NewStreamService returns a StreamService which sends requests with the
+// given http.Client, authenticated with the bearer token. A nil client uses
+// NewHTTPClient.
+func NewStreamService(client *http.Client, token string, opts ...Option) *StreamService {
+	if client == nil {
+		client = NewHTTPClient()
+	}
+	srv := &StreamService{
+		client: client,
+		token:  token,
+	}
+	for _, opt := range opts {
+		opt(&srv.config)
+	}
+	srv.client = srv.config.httpClient(client)
+	return srv
+}
+
+// bearerToken returns the current bearer token.
+func (srv *StreamService) bearerToken() string {
+	srv.tokenMu.RLock()
+	defer srv.tokenMu.RUnlock()
+	return srv.token
+}
+
+// setBearerToken replaces the bearer token of subsequent requests.
+func (srv *StreamService) setBearerToken(token string) {
+	srv.tokenMu.Lock()
+	defer srv.tokenMu.Unlock()
+	srv.token = token
+}
+
+func createStreamRequest(ctx context.Context, endpoint string, params *StreamFilterParams, token string) (*http.Request, error) {
+	url := fmt.Sprintf("%s/%s", endpoint, "stream")
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	q, _ := query.Values(params)
+	req.URL.RawQuery = q.Encode()
+	return req, nil
+}
+
+// Connect starts a Stream which receives the Tweets matching the rules of the
+// filtered stream, with the fields and expansions requested by params. The
+// Stream stops when ctx is cancelled or Stop is called, whichever is first.
+//
+// The first connect attempt is made synchronously: if Twitter rejects it, e.g.
+// with ErrUnauthorized for a bad bearer token, Connect returns the error.
+// Transient failures are retried in the background. Parameters are checked
+// for known mistakes before connecting, which are reported as a
+// ParameterError, see StreamFilterParams.Validate.
+func (srv *StreamService) Connect(ctx context.Context, params *StreamFilterParams) (*Stream, error) {
+	return srv.connect(ctx, streamV2Path, params)
+}
+
+// ConnectSample starts a Stream which receives a random sample of about 1%
+// of all Tweets from the sampled stream. Messages have no matching rules;
+// otherwise it behaves like Connect.
+func (srv *StreamService) ConnectSample(ctx context.Context, params *StreamFilterParams) (*Stream, error) {
+	return srv.connect(ctx, sampleStreamV2Path, params)
+}
+
+func (srv *StreamService) connect(ctx context.Context, path string, params *StreamFilterParams) (*Stream, error) {
+	var unknown func(*ParameterError)
+	if !srv.config.strictFields {
+		logger := srv.config.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		unknown = func(err *ParameterError) {
+			logger.Log(ctx, slog.LevelWarn, "stream parameter has unknown values", "error", err)
+		}
+	}
+	if err := params.validate(unknown); err != nil {
+		return nil, err
+	}
+	token, err := srv.currentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := createStreamRequest(ctx, srv.endpoint(path), params, token)
+	if err != nil {
+		return nil, err
+	}
+	s := newStream(ctx, srv.client, req, srv.config)
+	s.srv = srv
+	s.params = params
+	// only the filtered stream has rules, to recover gaps with
+	s.filtered = path == streamV2Path
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// StreamFilterParams are the query parameters of a stream connection. Each
+// field lists the fields or expansions to include in streamed messages.
+// Partition selects the partition of partitioned streams such as sample10.
+// BackfillMinutes, from 1 to 5, redelivers Tweets of the minutes before
+// connecting; it requires academic or enterprise access, see also
+// WithAutoBackfill.
+type StreamFilterParams struct {
+	Expansions      []string `url:"expansions,omitempty,comma"`
+	MediaFields     []string `url:"media.fields,omitempty,comma"`
+	PlaceFields     []string `url:"place.fields,omitempty,comma"`
+	PollFields      []string `url:"poll.fields,omitempty,comma"`
+	TweetFields     []string `url:"tweet.fields,omitempty,comma"`
+	UserFields      []string `url:"user.fields,omitempty,comma"`
+	Partition       int      `url:"partition,omitempty"`
+	BackfillMinutes int      `url:"backfill_minutes,omitempty"`
+}
+
+// StreamData is a message received from the stream. Includes holds the
+// objects of requested expansions and Errors report partial failures, e.g.
+// expansions which could not be resolved. Annotations carry
+// the results of transforms applied after the message was received, see
+// SetAnnotation.
+type StreamData struct {
+	Tweet         *Tweet            `json:"data,omitempty"`
+	Includes      *Includes         `json:"includes,omitempty"`
+	MatchingRules []*MatchingRule   `json:"matching_rules,omitempty"`
+	Errors        []*APIErrorDetail `json:"errors,omitempty"`
+	Annotations   map[string]any    `json:"annotations,omitempty"`
+	// Recovered is set for Tweets which were missed while disconnected and
+	// recovered by searching, see WithGapRecovery.
+	Recovered bool `json:"recovered,omitempty"`
+	// Raw is the message exactly as Twitter sent it, before any tag
+	// mapping, if the stream was configured WithRawPayload. It is nil for
+	// recovered Tweets.
+	Raw json.RawMessage `json:"-"`
+	// ctx carries the span of the message, see Context
+	ctx context.Context
+	// pooled messages are returned to the pool by Release, and keep their
+	// spare Tweet and matching rules for reuse
+	pooled     bool
+	spareTweet *Tweet
+	spareRules []*MatchingRule
+	// seq is the sequence number of the message, see Sequence
+	seq uint64
+}
+
+// MatchingRule is a rule which matched a streamed Tweet. OriginalTag and
+// Labels are only set when a TagMapper rewrote the rule on ingest.
+type MatchingRule struct {
+	Id          string            `json:"id,omitempty"`
+	Tag         string            `json:"tag,omitempty"`
+	OriginalTag string            `json:"original_tag,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Stream maintains a connection to the Twitter Streaming API, receives
+// messages from the streaming response, and sends them on the Messages
+// channel from a goroutine. The stream goroutine stops itself if an EOF is
+// reached or retry errors occur, also closing the Messages channel.
+//
+// Transport failures, undecodable messages and terminal HTTP statuses are
+// reported on the Errors channel, and transitions of the connection on the
+// Events channel. Both are closed together with Messages.
+//
+// The client must Stop() the stream or cancel the context passed to Connect
+// when finished receiving. Stop() waits until the stream is properly stopped,
+// Shutdown until a deadline. Done is closed once the stream stopped, on
+// request or on its own, and Err then tells why.
+type Stream struct {
+	client   *http.Client
+	Messages chan *StreamData
+	Errors   <-chan error
+	errs     chan error
+	Events   <-chan Event
+	events   chan Event
+	done     <-chan struct{}
+	cancel   context.CancelFunc
+	group    *sync.WaitGroup
+	// terminated is closed, and err set, once the channels are closed
+	terminated chan struct{}
+	errMu      sync.Mutex
+	err        error
+	// body is the body of the current connection, guarded by bodyMu
+	bodyMu       sync.Mutex
+	body         io.Closer
+	reconnecting int32
+	// next is the connection handed over by Rotate, guarded by bodyMu;
+	// rotateMu serializes rotations, and while one is under way bridging is
+	// set and bridge remembers the delivered Tweets until bridgeUntil
+	next        *http.Response
+	rotateMu    sync.Mutex
+	bridging    int32
+	bridge      *dedup
+	bridgeUntil time.Time
+	config      config
+	// req is changed only by the stream goroutine, holding reqMu
+	req   *http.Request
+	reqMu sync.Mutex
+	// srv and params are used for gap recovery and Tweet lookups
+	srv         *StreamService
+	params      *StreamFilterParams
+	filtered    bool
+	lastTweetID string
+	// resumedAt is the time of the checkpoint the stream resumed from, and
+	// checkpointed the Tweet of the last checkpoint, see WithCheckpoint
+	resumedAt      time.Time
+	checkpointed   string
+	checkpointedAt time.Time
+	// recovered are the results of gap recoveries, guarded by recoveryMu,
+	// for the stream goroutine to deliver
+	recoveryMu   sync.Mutex
+	recovered    []*StreamData
+	recoveryErrs []error
+	statsMu      sync.Mutex
+	stats        Stats
+	// traceCtx carries span, the span of the life of the stream
+	traceCtx context.Context
+	span     Span
+	// subscriptions fanned out to by Subscribe
+	subMu         sync.Mutex
+	subs          []*Subscription
+	fanOutOnce    sync.Once
+	fanOutStopped bool
+	replay        *ring
+	// dedup remembers delivered Tweets, see WithDedup
+	dedup *dedup
+	// handler delivers messages through the middleware, if any; passed
+	// reports whether the last message reached delivery
+	handler Handler
+	passed  bool
+	// replaying streams stop once their recording ends, see ConnectReplay
+	replaying bool
+	// circuit is the state of the circuit breaker, see WithCircuitBreaker
+	circuit *circuit
+	// handle, if set, receives the messages of streams which are not Tweet
+	// streams, such as compliance streams, and reports whether to continue
+	handle func(data []byte) bool
+}
+
+// newStream creates a Stream for the given request. The stream may be
+// stopped by calling Stop() on the stream or cancelling ctx.
+func newStream(ctx context.Context, client *http.Client, req *http.Request, cfg config) *Stream {
+	ctx, cancel := context.WithCancel(ctx)
+	if cfg.tracer == nil {
+		cfg.tracer = noopTracer{}
+	}
+	if cfg.logger == nil {
+		cfg.logger = slog.Default()
+	}
+	errs := make(chan error, errorsBufferSize)
+	events := make(chan Event, eventsBufferSize)
+	s := &Stream{
+		client:     client,
+		Messages:   make(chan *StreamData, cfg.messagesBuffer),
+		Errors:     errs,
+		errs:       errs,
+		Events:     events,
+		events:     events,
+		done:       ctx.Done(),
+		cancel:     cancel,
+		group:      &sync.WaitGroup{},
+		terminated: make(chan struct{}),
+		config:     cfg,
+	}
+	// requests are aborted, including reads of their bodies, once the stream
+	// is stopped
+	s.req = req.WithContext(ctx)
+	if cfg.replaySize > 0 {
+		s.replay = &ring{entries: make([]replayed, cfg.replaySize)}
+	}
+	if cfg.dedupSize > 0 {
+		s.dedup = newDedup(cfg.dedupSize, cfg.dedupTTL)
+	}
+	if cfg.circuitBreaker != nil {
+		s.circuit = &circuit{CircuitBreaker: cfg.circuitBreaker}
+	}
+	s.handler = s.chain()
+	s.traceCtx, s.span = cfg.tracer.Start(ctx, "twitter.stream", map[string]any{"http.url": req.URL.String()})
+	return s
+}
+
+// start acquires the connection lock and makes the first connect attempt,
+// then starts a goroutine to retry connecting and receive from the stream
+// response. The goroutine may stop due to retry errors. If the first attempt
+// was rejected for a reason retrying cannot fix, the stream is stopped and the
+// error returned.
+func (s *Stream) start() error {
+	lock := s.config.connectionLock
+	if lock != nil {
+		// wait for the previous holder, e.g. the instance being replaced by a
+		// deploy, to release the connection
+		if err := lock.Acquire(s.done); err != nil {
+			s.cancel()
+			s.span.End(err)
+			return err
+		}
+	}
+	// the checkpoint is read after the lock, once the previous holder wrote
+	// its last one
+	s.resume()
+	query := s.req.URL.Query()
+	s.setBackfill(query, s.resumedAt)
+	resp, statusErr, err := s.handshake()
+	s.setBackfill(query, time.Time{})
+	if err != nil {
+		if lock != nil {
+			lock.Release()
+		}
+		s.cancel()
+		s.span.End(err)
+		return err
+	}
+	s.group.Add(1)
+	go s.retry(resp, statusErr, s.config.newBackOff(ClassNetwork), s.config.newBackOff(ClassHTTP), s.config.newBackOff(ClassRateLimit))
+	return nil
+}
+
+// handshake makes the first connect attempt, refreshing the token once if
+// the status policy asks for it. It returns an error if Connect should fail.
+// Otherwise it returns the response, and its StatusError unless it is 200
+// OK, for the goroutine to receive or retry; the response is nil after a
+// transport error, which the goroutine retries.
+func (s *Stream) handshake() (*http.Response, *StatusError, error) {
+	refreshed := false
+	for {
+		resp, err := s.dial()
+		if err != nil {
+			if stopped(s.done) {
+				// the context passed to Connect was cancelled
+				return nil, nil, s.req.Context().Err()
+			}
+			return nil, nil, nil
+		}
+		statusErr, fail := s.handshakeError(resp)
+		if !fail {
+			return resp, statusErr, nil
+		}
+		resp.Body.Close()
+		if !refreshed && s.config.statusPolicy(resp.StatusCode).Action == StatusRefreshToken && s.refreshToken() == nil {
+			refreshed = true
+			continue
+		}
+		return nil, nil, statusErr
+	}
+}
+
+// Stop signals retry and receiver to stop, closes the Messages channel, and
+// blocks until done.
+func (s *Stream) Stop() {
+	s.cancel()
+	// Scanner does not have a Stop() or take a done channel, so for low volume
+	// streams Scan() blocks until the next keep-alive. Close the resp.Body to
+	// escape and stop the stream in a timely fashion.
+	s.closeBody()
+	// block until the retry goroutine stops
+	s.group.Wait()
+}
+
+// Shutdown stops the stream like Stop, but gives up waiting once ctx is
+// done, returning its error. The stream stops in the background after.
+func (s *Stream) Shutdown(ctx context.Context) error {
+	s.cancel()
+	s.closeBody()
+	select {
+	case <-s.terminated:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done returns a channel which is closed once the stream stopped and its
+// channels are closed, whether it was stopped or terminated on its own.
+func (s *Stream) Done() <-chan struct{} {
+	return s.terminated
+}
+
+// Err returns the error which terminated the stream, such as a StatusError
+// for a status which is not retried, or ErrRetriesExhausted. It is nil while
+// the stream runs, and once it was stopped by Stop, Shutdown or its context.
+func (s *Stream) Err() error {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	return s.err
+}
+
+// retry retries making the stream request and receiving the response
+// according to the Twitter backoff policies, starting with the response of
+// the first attempt and its StatusError if there is one. Callers should
+// invoke it in a goroutine since backoffs sleep between retries. The
+// connection lock, if any, must be held and is released on return.
+// https://dev.twitter.com/streaming/overview/connecting
+func (s *Stream) retry(first *http.Response, firstErr *StatusError, netBackOff, expBackOff, aggExpBackOff backoff.BackOff) {
+	var reason error
+	// close Done after the other channels
+	defer func() {
+		s.errMu.Lock()
+		s.err = reason
+		s.errMu.Unlock()
+		close(s.terminated)
+	}()
+	// close Messages, Errors and Events channels and decrement the wait group
+	// counter
+	defer close(s.Messages)
+	defer close(s.errs)
+	defer close(s.events)
+	defer s.group.Done()
+	defer func() {
+		s.emit(Event{Type: EventStopped, Err: reason})
+		s.span.End(reason)
+	}()
+	if lock := s.config.connectionLock; lock != nil {
+		defer lock.Release()
+	}
+	defer s.checkpoint(true)
+	defer func() {
+		if next := s.takeRotation(); next != nil {
+			next.Body.Close()
+		}
+	}()
+
+	start := time.Now()
+	connected := false
+	refreshed := false
+	query := s.req.URL.Query()
+	// a resumed stream recovers the Tweets since its checkpoint like after a
+	// reconnect
+	disconnectedAt := s.resumedAt
+	var wait time.Duration
+	for !stopped(s.done) {
+		var resp *http.Response
+		var statusErr *StatusError
+		var err error
+		if first != nil {
+			// the first attempt was made, and its status alerted, by start()
+			resp, statusErr, first = first, firstErr, nil
+		} else if next := s.rotated(); next != nil {
+			// Rotate connected while the old connection was lost
+			resp = next
+		} else {
+			s.setBackfill(query, disconnectedAt)
+			resp, err = s.dial()
+		}
+		if err != nil && stopped(s.done) {
+			// the request was aborted by Stop() or the context
+			return
+		}
+		if err != nil {
+			// linear backoff for network errors
+			connErr := &ConnectionError{Err: err}
+			s.sendError(connErr)
+			s.emit(Event{Type: EventDisconnected, Err: connErr})
+			s.reconnect(0)
+			wait = s.tripCircuit(connErr, netBackOff.NextBackOff())
+			if wait == backoff.Stop {
+				reason = ErrRetriesExhausted
+				s.sendError(ErrRetriesExhausted)
+				return
+			}
+			s.emit(Event{Type: EventBackoff, Wait: wait})
+			sleepOrDone(wait, s.done)
+			continue
+		}
+		// when err is nil, resp contains a non-nil Body which must be closed
+		defer resp.Body.Close()
+		s.setBody(resp.Body)
+		if resp.StatusCode != http.StatusOK && statusErr == nil {
+			statusErr = newStatusError(resp)
+			if alert := s.config.statusPolicy(resp.StatusCode).Alert; alert != nil {
+				alert(statusErr)
+			}
+		}
+		switch action := s.config.statusPolicy(resp.StatusCode).Action; {
+		case resp.StatusCode == http.StatusOK:
+			// receive stream response Body, handles closing
+			connected = true
+			refreshed = false
+			connectedAt := time.Now()
+			s.emit(Event{Type: EventConnected})
+			s.closeCircuit()
+			if s.needsRecovery(disconnectedAt) {
+				go s.recoverGap(s.lastTweetID)
+			}
+			err := s.receive(resp.Body)
+			if err == errRotated {
+				// switch to the new connection right away
+				resp.Body.Close()
+				disconnectedAt = time.Time{}
+				continue
+			}
+			if err != nil {
+				s.emit(Event{Type: EventDisconnected, Err: err})
+				var replayErr *ReplayError
+				if errors.As(err, &replayErr) {
+					reason = replayErr
+					s.sendError(replayErr)
+				}
+			}
+			if s.replaying {
+				return
+			}
+			disconnectedAt = time.Now()
+			netBackOff.Reset()
+			aggExpBackOff.Reset()
+			if adaptive, ok := expBackOff.(*adaptiveBackOff); ok && !errors.Is(err, ErrReconnectRequested) {
+				// pace the reconnect by how long the connection lasted,
+				// unless it was closed by Reconnect rather than the server
+				adaptive.observe(time.Since(connectedAt))
+				adaptive.Reset()
+				wait = adaptive.NextBackOff()
+				break
+			}
+			expBackOff.Reset()
+			wait = 0
+		case action == StatusRetry && (resp.StatusCode == 420 || resp.StatusCode == http.StatusTooManyRequests):
+			// 420 Enhance Your Calm is unofficial status code by Twitter on being rate limited.
+			if s.inConnectGrace(start, connected) {
+				// another instance may still hold the connection, retry soon
+				wait = connectGraceInterval
+				break
+			}
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				// Twitter told us when the rate limit resets
+				wait = after
+				break
+			}
+			// aggressive exponential backoff
+			wait = aggExpBackOff.NextBackOff()
+		case action == StatusRetry:
+			// exponential backoff, e.g. for 503 Service Unavailable
+			wait = expBackOff.NextBackOff()
+		case action == StatusRefreshToken && !refreshed && s.refreshToken() == nil:
+			// retry once with the new token
+			refreshed = true
+			wait = 0
+		default:
+			// stop retrying for other response codes
+			reason = statusErr
+			s.sendError(statusErr)
+			resp.Body.Close()
+			return
+		}
+		if statusErr != nil {
+			s.emit(Event{Type: EventDisconnected, Err: statusErr})
+			wait = s.tripCircuit(statusErr, wait)
+		}
+		if !stopped(s.done) {
+			s.reconnect(resp.StatusCode)
+		}
+		// close response before each retry
+		resp.Body.Close()
+		if wait == backoff.Stop {
+			reason = ErrRetriesExhausted
+			s.sendError(ErrRetriesExhausted)
+			return
+		}
+		if wait > 0 {
+			s.emit(Event{Type: EventBackoff, Wait: wait})
+		}
+		sleepOrDone(wait, s.done)
+	}
+}
+
+// receive scans a stream response body, JSON decodes tokens to messages, and
+// sends messages to the Messages channel. Receiving continues until an EOF,
+// scan error, stall, or the done channel is closed. It returns why the
+// connection was lost, or nil if the stream was stopped.
+func (s *Stream) receive(body io.ReadCloser) error {
+	reader := newStreamResponseBodyReader(countingReader{Reader: body, s: s}, s.config.maxMessageSize)
+	watch := s.watchStall(body)
+	defer watch.stop()
+	for !stopped(s.done) {
+		data, err := reader.readNext()
+		var tooLarge *MessageTooLargeError
+		if errors.As(err, &tooLarge) {
+			watch.reset()
+			s.count(func(stats *Stats) { stats.Received++ })
+			s.sendError(tooLarge)
+			s.drop("too large", "size", tooLarge.Size)
+			continue
+		}
+		if err != nil {
+			if stopped(s.done) {
+				return nil
+			}
+			if s.rotationPending() {
+				return errRotated
+			}
+			if s.reconnectRequested() {
+				return ErrReconnectRequested
+			}
+			if watch.stalled() {
+				stallErr := &StallError{Timeout: watch.timeout}
+				s.sendError(stallErr)
+				s.emit(Event{Type: EventStallDetected, Err: stallErr})
+				return stallErr
+			}
+			return err
+		}
+		watch.reset()
+		if s.deliverRecovered() == context.Canceled {
+			return nil
+		}
+		s.record(data)
+		if len(data) == 0 {
+			// empty keep-alive
+			s.count(func(stats *Stats) {
+				stats.KeepAlives++
+				stats.LastKeepAliveAt = time.Now()
+			})
+			if s.config.heartbeats {
+				s.emit(Event{Type: EventHeartbeat})
+			}
+			continue
+		}
+		s.count(func(stats *Stats) {
+			stats.Received++
+			stats.LastMessageAt = time.Now()
+		})
+		if s.handle != nil {
+			if !s.handle(data) {
+				return nil
+			}
+			continue
+		}
+		msg, err := s.decode(data)
+		if err != nil {
+			s.decodeFailed(data, err)
+			continue
+		}
+		if s.config.rawPayload {
+			msg.Raw = append(json.RawMessage(nil), data...)
+		}
+		if msg.Tweet == nil {
+			// an in-stream error object or notice rather than a Tweet
+			if notice, ok := parseNotice(data); ok {
+				msg.Release()
+				if err := s.notice(notice); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if gap, ok := s.payloadGap(msg); ok && !s.handleGap(msg, gap, data) {
+			s.drop("malformed", "gap", gap, "tweet_id", tweetID(msg))
+			msg.Release()
+			continue
+		}
+		s.config.tagMapper.Apply(msg)
+		if msg.Tweet != nil {
+			s.lastTweetID = msg.Tweet.ID
+		}
+		if !s.accept(msg) || s.duplicate(msg) {
+			msg.Release()
+			continue
+		}
+		span := s.traceMessage(msg)
+		err = s.process(msg)
+		span.End(err)
+		if err == context.Canceled {
+			s.drop("stopped", "tweet_id", tweetID(msg))
+			msg.Release()
+			return nil
+		}
+		s.checkpoint(false)
+	}
+	return nil
+}
+
+// getMessage unmarshals the token and returns a message struct, if the type
+// can be determined. Otherwise, returns the token unmarshalled into a data
+// map[string]interface{} or the unmarshal error.
+func getMessage(decoder Decoder, token []byte) (*StreamData, error) {
+	// unmarshal JSON encoded token into a map for
+	data := &StreamData{}
+	err := decoder.Unmarshal(token, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
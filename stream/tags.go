@@ -0,0 +1,47 @@
+package stream
+
+// TagRewrite describes how a matching rule is rewritten on ingest.
+type TagRewrite struct {
+	// Tag replaces the tag of the matching rule. An empty Tag keeps the tag
+	// Twitter sent.
+	Tag string
+	// Labels are merged into the labels of the matching rule, e.g. the owning
+	// team of a campaign.
+	Labels map[string]string
+}
+
+// TagMapper rewrites and augments the matching rules of received messages, so
+// downstream routing and metrics can rely on stable business names rather
+// than the raw tags of the rules. Rewrites by rule ID take precedence over
+// rewrites by tag.
+type TagMapper struct {
+	ByID  map[string]TagRewrite
+	ByTag map[string]TagRewrite
+}
+
+// Apply rewrites the matching rules of the message in place. A nil TagMapper
+// leaves the message untouched.
+func (m *TagMapper) Apply(data *StreamData) {
+	if m == nil {
+		return
+	}
+	for _, rule := range data.MatchingRules {
+		rewrite, ok := m.ByID[rule.Id]
+		if !ok {
+			rewrite, ok = m.ByTag[rule.Tag]
+		}
+		if !ok {
+			continue
+		}
+		if rewrite.Tag != "" && rewrite.Tag != rule.Tag {
+			rule.OriginalTag = rule.Tag
+			rule.Tag = rewrite.Tag
+		}
+		if len(rewrite.Labels) > 0 && rule.Labels == nil {
+			rule.Labels = make(map[string]string, len(rewrite.Labels))
+		}
+		for k, v := range rewrite.Labels {
+			rule.Labels[k] = v
+		}
+	}
+}
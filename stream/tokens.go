@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// rateLimitWindow is the length of the rate limit windows of the API, used
+// when a 429 response does not tell when the limit resets.
+const rateLimitWindow = 15 * time.Minute
+
+var errNoTokens = errors.New("stream: no tokens to rotate")
+
+// TokenProvider supplies the bearer tokens of requests. RateLimited is
+// called when a request with token was answered with 429 Too Many Requests,
+// with the time the rate limit resets; Token should then prefer another
+// token.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+	RateLimited(token string, reset time.Time)
+}
+
+// WithTokenProvider authenticates requests with tokens of the provider
+// instead of the token passed to NewStreamService. Rules, search and counts
+// requests answered with 429 are retried with the next token of the
+// provider; streams use the token current when connecting.
+func WithTokenProvider(p TokenProvider) Option {
+	return func(c *config) {
+		c.tokenProvider = p
+	}
+}
+
+// currentToken returns the bearer token of the next request.
+func (srv *StreamService) currentToken(ctx context.Context) (string, error) {
+	if p := srv.config.tokenProvider; p != nil {
+		return p.Token(ctx)
+	}
+	return srv.bearerToken(), nil
+}
+
+// TokenState is the rate limit state of a token of RotatingTokens.
+type TokenState struct {
+	// Index is the position of the token in the list of tokens.
+	Index int
+	// RateLimited counts the 429 responses of the token.
+	RateLimited int
+	// Reset is when the latest rate limit of the token resets.
+	Reset time.Time
+}
+
+// Limited reports whether the token is rate limited at now.
+func (s TokenState) Limited(now time.Time) bool {
+	return now.Before(s.Reset)
+}
+
+// RotatingTokens is a TokenProvider rotating through several bearer tokens,
+// e.g. of different apps of an organization. It keeps using a token until it
+// is rate limited, then fails over to the next token which is not. If all
+// tokens are rate limited, the one resetting first is returned.
+type RotatingTokens struct {
+	tokens []string
+
+	mu      sync.Mutex
+	current int
+	states  []TokenState
+}
+
+// NewRotatingTokens returns a RotatingTokens of the tokens.
+func NewRotatingTokens(tokens ...string) *RotatingTokens {
+	states := make([]TokenState, len(tokens))
+	for i := range states {
+		states[i].Index = i
+	}
+	return &RotatingTokens{tokens: tokens, states: states}
+}
+
+// Token returns the current token.
+func (r *RotatingTokens) Token(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.tokens) == 0 {
+		return "", errNoTokens
+	}
+	now := time.Now()
+	earliest := r.current
+	for i := 0; i < len(r.tokens); i++ {
+		candidate := (r.current + i) % len(r.tokens)
+		if !r.states[candidate].Limited(now) {
+			r.current = candidate
+			return r.tokens[candidate], nil
+		}
+		if r.states[candidate].Reset.Before(r.states[earliest].Reset) {
+			earliest = candidate
+		}
+	}
+	r.current = earliest
+	return r.tokens[earliest], nil
+}
+
+// RateLimited records the rate limit of the token.
+func (r *RotatingTokens) RateLimited(token string, reset time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, t := range r.tokens {
+		if t == token {
+			r.states[i].RateLimited++
+			r.states[i].Reset = reset
+		}
+	}
+}
+
+// States returns the rate limit state of each token, in the order of the
+// tokens.
+func (r *RotatingTokens) States() []TokenState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TokenState(nil), r.states...)
+}
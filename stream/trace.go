@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer starts the spans of streams, e.g. an adapter to an OpenTelemetry
+// trace.Tracer. Streams trace their whole life in a "twitter.stream" span,
+// with an event for each connection Event, such as backoff sleeps, each
+// connect attempt in a child "twitter.stream.connect" span, and each
+// received message in a child "twitter.stream.message" span which ends once
+// the message is delivered.
+type Tracer interface {
+	// Start starts a span named name with the attributes, as a child of the
+	// span in ctx, if any, and returns a context carrying it.
+	Start(ctx context.Context, name string, attrs map[string]any) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	AddEvent(name string, attrs map[string]any)
+	SetAttributes(attrs map[string]any)
+	// End ends the span, with the error which failed it, if any.
+	End(err error)
+}
+
+// WithTracer traces streams with the Tracer.
+func WithTracer(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// noopTracer is the Tracer of streams which are not traced.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, attrs map[string]any) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) AddEvent(name string, attrs map[string]any) {}
+func (noopSpan) SetAttributes(attrs map[string]any)         {}
+func (noopSpan) End(err error)                              {}
+
+// startSpan starts a span as a child of the span of the stream.
+func (s *Stream) startSpan(name string, attrs map[string]any) (context.Context, Span) {
+	return s.config.tracer.Start(s.traceCtx, name, attrs)
+}
+
+// traceMessage starts the "twitter.stream.message" span of msg and sets its
+// context. Untraced streams skip building the attributes.
+func (s *Stream) traceMessage(msg *StreamData) Span {
+	if _, ok := s.config.tracer.(noopTracer); ok {
+		return noopSpan{}
+	}
+	attrs := map[string]any{}
+	if msg.Tweet != nil {
+		attrs["tweet.id"] = msg.Tweet.ID
+	}
+	ctx, span := s.startSpan("twitter.stream.message", attrs)
+	msg.ctx = ctx
+	return span
+}
+
+// dial makes a connect attempt in a "twitter.stream.connect" span.
+func (s *Stream) dial() (*http.Response, error) {
+	s.emit(Event{Type: EventConnecting})
+	_, span := s.startSpan("twitter.stream.connect", map[string]any{
+		"http.method": s.req.Method,
+		"http.url":    s.req.URL.String(),
+	})
+	resp, err := s.client.Do(s.req)
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+	s.observeRateLimit(resp)
+	span.SetAttributes(map[string]any{"http.status_code": resp.StatusCode})
+	if resp.StatusCode != http.StatusOK {
+		span.End(&StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
+	} else {
+		span.End(nil)
+	}
+	return resp, nil
+}
+
+// Context returns the context of the message, carrying its
+// "twitter.stream.message" span if the stream is traced, for downstream
+// processing to continue the trace.
+func (d *StreamData) Context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+	return d.ctx
+}
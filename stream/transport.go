@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// transport holds the settings of WithProxy, WithTLSConfig and WithDialer.
+type transport struct {
+	proxy     func(*http.Request) (*url.URL, error)
+	proxySet  bool
+	tlsConfig *tls.Config
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// WithProxy sends requests through the proxy returned by proxy for each
+// request, such as http.ProxyURL of an HTTP or HTTPS proxy, or
+// http.ProxyFromEnvironment. A nil proxy connects directly, ignoring the
+// HTTP_PROXY and HTTPS_PROXY environment variables.
+//
+// Like WithTLSConfig and WithDialer, it configures a copy of the transport
+// of the http.Client passed to NewStreamService, which is left unchanged.
+// A client whose Transport is not an *http.Transport gets a copy of the
+// transport of NewHTTPClient instead. WithDialer replaces the dialer of
+// WithReadTimeout.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *config) {
+		c.transport.proxy = proxy
+		c.transport.proxySet = true
+	}
+}
+
+// WithTLSConfig configures TLS connections with cfg, e.g. to trust a
+// corporate CA bundle in RootCAs, or to present a client certificate. See
+// WithProxy for the transport it applies to.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *config) {
+		c.transport.tlsConfig = cfg
+	}
+}
+
+// WithDialer opens the connections of requests with dial, such as the
+// DialContext method of a net.Dialer with custom timeouts, local address or
+// resolver. Set the KeepAlive of the dialer, as a stream can go minutes
+// without traffic on a dead connection otherwise. See WithProxy for the
+// transport it applies to.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(c *config) {
+		c.transport.dial = dial
+	}
+}
+
+// httpClient returns client, or a copy of it whose transport has the
+// settings of WithProxy, WithTLSConfig and WithDialer if any is set.
+func (c *config) httpClient(client *http.Client) *http.Client {
+	tr := c.transport
+	if !tr.proxySet && tr.tlsConfig == nil && tr.dial == nil {
+		return client
+	}
+	configured := *client
+	t, ok := configured.Transport.(*http.Transport)
+	if !ok {
+		t = NewHTTPClient().Transport.(*http.Transport)
+	}
+	t = t.Clone()
+	if tr.proxySet {
+		t.Proxy = tr.proxy
+	}
+	if tr.tlsConfig != nil {
+		t.TLSClientConfig = tr.tlsConfig.Clone()
+	}
+	if tr.dial != nil {
+		t.DialContext = tr.dial
+	}
+	configured.Transport = t
+	return &configured
+}
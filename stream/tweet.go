@@ -0,0 +1,182 @@
+package stream
+
+import "time"
+
+// Tweet is the Tweet object of a stream message. Fields other than ID and
+// Text are only set when requested with StreamFilterParams.TweetFields.
+type Tweet struct {
+	ID                 string               `json:"id"`
+	Text               string               `json:"text"`
+	CreatedAt          time.Time            `json:"created_at,omitzero"`
+	AuthorID           string               `json:"author_id,omitempty"`
+	ConversationID     string               `json:"conversation_id,omitempty"`
+	InReplyToUserID    string               `json:"in_reply_to_user_id,omitempty"`
+	Lang               string               `json:"lang,omitempty"`
+	Source             string               `json:"source,omitempty"`
+	ReplySettings      string               `json:"reply_settings,omitempty"`
+	PossiblySensitive  bool                 `json:"possibly_sensitive,omitempty"`
+	Entities           *Entities            `json:"entities,omitempty"`
+	PublicMetrics      *PublicMetrics       `json:"public_metrics,omitempty"`
+	NonPublicMetrics   *NonPublicMetrics    `json:"non_public_metrics,omitempty"`
+	OrganicMetrics     *EngagementMetrics   `json:"organic_metrics,omitempty"`
+	PromotedMetrics    *EngagementMetrics   `json:"promoted_metrics,omitempty"`
+	ReferencedTweets   []*ReferencedTweet   `json:"referenced_tweets,omitempty"`
+	Attachments        *Attachments         `json:"attachments,omitempty"`
+	Geo                *Geo                 `json:"geo,omitempty"`
+	ContextAnnotations []*ContextAnnotation `json:"context_annotations,omitempty"`
+	Withheld           *Withheld            `json:"withheld,omitempty"`
+	NoteTweet          *NoteTweet           `json:"note_tweet,omitempty"`
+	// EditHistoryTweetIDs lists the IDs of all revisions of the Tweet, from
+	// the original Tweet to the latest edit.
+	EditHistoryTweetIDs []string      `json:"edit_history_tweet_ids,omitempty"`
+	EditControls        *EditControls `json:"edit_controls,omitempty"`
+}
+
+// Entities are the entities parsed out of the text of a Tweet. Start and End
+// of each entity are rune offsets into the text.
+type Entities struct {
+	Annotations []*AnnotationEntity `json:"annotations,omitempty"`
+	Cashtags    []*TagEntity        `json:"cashtags,omitempty"`
+	Hashtags    []*TagEntity        `json:"hashtags,omitempty"`
+	Mentions    []*MentionEntity    `json:"mentions,omitempty"`
+	URLs        []*URLEntity        `json:"urls,omitempty"`
+}
+
+// TagEntity is a hashtag or cashtag of a Tweet, without the leading '#' or
+// '$'.
+type TagEntity struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Tag   string `json:"tag"`
+}
+
+// MentionEntity is a mention of a user in a Tweet.
+type MentionEntity struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Username string `json:"username"`
+	ID       string `json:"id,omitempty"`
+}
+
+// URLEntity is a URL in a Tweet. URL is the shortened t.co link,
+// ExpandedURL the link as posted and UnwoundURL the final destination after
+// following redirects, if known.
+type URLEntity struct {
+	Start       int         `json:"start"`
+	End         int         `json:"end"`
+	URL         string      `json:"url"`
+	ExpandedURL string      `json:"expanded_url,omitempty"`
+	DisplayURL  string      `json:"display_url,omitempty"`
+	UnwoundURL  string      `json:"unwound_url,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	MediaKey    string      `json:"media_key,omitempty"`
+	Images      []*URLImage `json:"images,omitempty"`
+}
+
+// URLImage is a preview image of a URLEntity.
+type URLImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// AnnotationEntity is a named entity Twitter recognized in the text of a
+// Tweet, such as a person or place.
+type AnnotationEntity struct {
+	Start          int     `json:"start"`
+	End            int     `json:"end"`
+	Probability    float64 `json:"probability"`
+	Type           string  `json:"type"`
+	NormalizedText string  `json:"normalized_text"`
+}
+
+// PublicMetrics are the public engagement counts of a Tweet.
+type PublicMetrics struct {
+	RetweetCount    int `json:"retweet_count"`
+	ReplyCount      int `json:"reply_count"`
+	LikeCount       int `json:"like_count"`
+	QuoteCount      int `json:"quote_count"`
+	BookmarkCount   int `json:"bookmark_count"`
+	ImpressionCount int `json:"impression_count"`
+}
+
+// NonPublicMetrics are the private engagement counts of a Tweet, only
+// available with user context authentication for Tweets of the user.
+type NonPublicMetrics struct {
+	ImpressionCount   int `json:"impression_count"`
+	URLLinkClicks     int `json:"url_link_clicks"`
+	UserProfileClicks int `json:"user_profile_clicks"`
+}
+
+// EngagementMetrics are the organic or promoted engagement counts of a
+// Tweet, only available with user context authentication.
+type EngagementMetrics struct {
+	ImpressionCount   int `json:"impression_count"`
+	LikeCount         int `json:"like_count"`
+	ReplyCount        int `json:"reply_count"`
+	RetweetCount      int `json:"retweet_count"`
+	URLLinkClicks     int `json:"url_link_clicks"`
+	UserProfileClicks int `json:"user_profile_clicks"`
+}
+
+// Types of ReferencedTweet.
+const (
+	ReferenceRetweeted = "retweeted"
+	ReferenceQuoted    = "quoted"
+	ReferenceRepliedTo = "replied_to"
+)
+
+// ReferencedTweet is a Tweet the Tweet retweets, quotes or replies to.
+type ReferencedTweet struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Attachments lists the media and poll of a Tweet. Request the
+// attachments.media_keys and attachments.poll_ids expansions to receive the
+// objects within the includes of a message.
+type Attachments struct {
+	MediaKeys []string `json:"media_keys,omitempty"`
+	PollIDs   []string `json:"poll_ids,omitempty"`
+}
+
+// Geo is the location tagged to a Tweet.
+type Geo struct {
+	PlaceID     string       `json:"place_id,omitempty"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+}
+
+// Coordinates is a GeoJSON point, with Coordinates as [longitude, latitude].
+type Coordinates struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// ContextAnnotation is a topic Twitter derived from the content of a Tweet.
+type ContextAnnotation struct {
+	Domain *ContextEntity `json:"domain"`
+	Entity *ContextEntity `json:"entity"`
+}
+
+// ContextEntity is the domain or entity of a ContextAnnotation.
+type ContextEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Withheld describes in which countries content is withheld.
+type Withheld struct {
+	Copyright    bool     `json:"copyright"`
+	CountryCodes []string `json:"country_codes"`
+	Scope        string   `json:"scope,omitempty"`
+}
+
+// NoteTweet is the full text of a Tweet longer than 280 characters. Text of
+// the Tweet holds a truncated version.
+type NoteTweet struct {
+	Text     string    `json:"text"`
+	Entities *Entities `json:"entities,omitempty"`
+}
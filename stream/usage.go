@@ -0,0 +1,43 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const usageV2Path = "/2/usage/tweets"
+
+// Usage is the Tweet consumption of the project of the app. Tweets received
+// from the stream and from searches count towards ProjectCap, which resets
+// monthly on CapResetDay.
+type Usage struct {
+	ProjectID    string
+	ProjectCap   int64
+	ProjectUsage int64
+	CapResetDay  int
+}
+
+// Usage returns the Tweet consumption of the project of the app.
+func (srv *StreamService) Usage(ctx context.Context) (*Usage, error) {
+	// counts are sent as strings
+	var resp struct {
+		Data struct {
+			ProjectID    string      `json:"project_id"`
+			ProjectCap   json.Number `json:"project_cap"`
+			ProjectUsage json.Number `json:"project_usage"`
+			CapResetDay  int         `json:"cap_reset_day"`
+		} `json:"data"`
+	}
+	if err := srv.getJSON(ctx, srv.endpoint(usageV2Path), nil, &resp); err != nil {
+		return nil, err
+	}
+	usage := &Usage{ProjectID: resp.Data.ProjectID, CapResetDay: resp.Data.CapResetDay}
+	var err error
+	if usage.ProjectCap, err = resp.Data.ProjectCap.Int64(); err != nil {
+		return nil, err
+	}
+	if usage.ProjectUsage, err = resp.Data.ProjectUsage.Int64(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -34,60 +34,82 @@ func sleepOrDone(d time.Duration, done <-chan struct{}) {
 }
 
 // streamResponseBodyReader is a buffered reader for Twitter stream response
-// body. It can scan the arbitrary length of response body unlike bufio.Scanner.
+// body. It can scan the arbitrary length of response body unlike bufio.Scanner,
+// up to an optional maximum message size.
 type streamResponseBodyReader struct {
 	reader *bufio.Reader
 	buf    bytes.Buffer
+	// maxSize is the maximum size of a message, unlimited if zero
+	maxSize int
 }
 
 // newStreamResponseBodyReader returns an instance of streamResponseBodyReader
 // for the given Twitter stream response body.
-func newStreamResponseBodyReader(body io.Reader) *streamResponseBodyReader {
-	return &streamResponseBodyReader{reader: bufio.NewReader(body)}
+func newStreamResponseBodyReader(body io.Reader, maxSize int) *streamResponseBodyReader {
+	return &streamResponseBodyReader{reader: bufio.NewReader(body), maxSize: maxSize}
 }
 
 // readNext reads Twitter stream response body and returns the next stream
 // content if exists. Returns io.EOF error if we reached the end of the stream
-// and there's no more message to read.
+// and there's no more message to read, and a *MessageTooLargeError if the
+// message exceeded the maximum size, in which case it was skipped.
 func (r *streamResponseBodyReader) readNext() ([]byte, error) {
 	// Discard all the bytes from buf and continue to use the allocated memory
 	// space for reading the next message.
 	r.buf.Truncate(0)
+	size := 0
+	var last byte
 	for {
 		// Twitter stream messages are separated with "\r\n", and a valid
 		// message may sometimes contain '\n' in the middle.
-		// bufio.Reader.Read() can accept one byte delimiter only, so we need to
-		// first break out each line on '\n' and then check whether the line ends
-		// with "\r\n" to find message boundaries.
+		// bufio.Reader.ReadSlice() can accept one byte delimiter only, so we
+		// need to first break out each line on '\n' and then check whether the
+		// line ends with "\r\n" to find message boundaries. Lines longer than
+		// the bufio buffer are read in chunks, so the size of a message can be
+		// checked before it is buffered in full.
 		// https://dev.twitter.com/streaming/overview/processing
-		line, err := r.reader.ReadBytes('\n')
+		chunk, err := r.reader.ReadSlice('\n')
 		// Non-EOF error should be propagated to callers immediately.
-		if err != nil && err != io.EOF {
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
 			return nil, err
 		}
 		// EOF error means that we reached the end of the stream body before finding
-		// delimiter '\n'. If "line" is empty, it means the reader didn't read any
+		// delimiter '\n'. If "chunk" is empty, it means the reader didn't read any
 		// data from the stream before reaching EOF and there's nothing to append to
 		// buf.
-		if err == io.EOF && len(line) == 0 {
-			// if buf has no data, propagate io.EOF to callers and let them know that
-			// we've finished processing the stream.
-			if r.buf.Len() == 0 {
+		if err == io.EOF && len(chunk) == 0 {
+			// if nothing was read, propagate io.EOF to callers and let them know
+			// that we've finished processing the stream.
+			if size == 0 {
 				return nil, err
 			}
 			// Otherwise, we still have a remaining stream message to return.
 			break
 		}
-		// If the line ends with "\r\n", it's the end of one stream message data.
-		if bytes.HasSuffix(line, []byte("\r\n")) {
-			// reader.ReadBytes() returns a slice including the delimiter itself, so
-			// we need to trim '\n' as well as '\r' from the end of the slice.
-			r.buf.Write(bytes.TrimRight(line, "\r\n"))
+		size += len(chunk)
+		tooLarge := r.maxSize > 0 && size > r.maxSize+len("\r\n")
+		if !tooLarge {
+			r.buf.Write(chunk)
+		}
+		// If the line ends with "\r\n", it's the end of one stream message
+		// data. The '\r' may end the previous chunk.
+		if err == nil && (bytes.HasSuffix(chunk, []byte("\r\n")) || len(chunk) == 1 && last == '\r') {
+			size -= len("\r\n")
+			if !tooLarge {
+				r.buf.Truncate(r.buf.Len() - len("\r\n"))
+			}
+			break
+		}
+		last = chunk[len(chunk)-1]
+		if err == io.EOF {
 			break
 		}
-		// Otherwise, the line is not the end of a stream message, so we append
-		// the line to buf and continue to scan lines.
-		r.buf.Write(line)
+		// Otherwise, the line is not the end of a stream message, so we
+		// continue to scan lines.
+	}
+	if r.maxSize > 0 && size > r.maxSize {
+		r.buf.Truncate(0)
+		return nil, &MessageTooLargeError{Size: size, Limit: r.maxSize}
 	}
 
 	// Get the stream message bytes from buf. Not that Bytes() won't mark the
@@ -96,6 +118,32 @@ func (r *streamResponseBodyReader) readNext() ([]byte, error) {
 	return r.buf.Bytes(), nil
 }
 
+// linearBackOff increases the wait by a fixed step on each attempt, up to a
+// maximum. It never returns backoff.Stop.
+type linearBackOff struct {
+	step    time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+func (b *linearBackOff) NextBackOff() time.Duration {
+	b.current += b.step
+	if b.current > b.max {
+		b.current = b.max
+	}
+	return b.current
+}
+
+func (b *linearBackOff) Reset() {
+	b.current = 0
+}
+
+// newLinearBackOff returns the backoff Twitter recommends for TCP/IP level
+// network errors: 250ms more per attempt, up to 16 seconds.
+func newLinearBackOff() *linearBackOff {
+	return &linearBackOff{step: 250 * time.Millisecond, max: 16 * time.Second}
+}
+
 func newExponentialBackOff() *backoff.ExponentialBackOff {
 	b := backoff.NewExponentialBackOff()
 	b.InitialInterval = 5 * time.Second
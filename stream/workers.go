@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// HandleOption configures Handle.
+type HandleOption func(*handleConfig)
+
+type handleConfig struct {
+	workers int
+	onError func(msg *StreamData, err error)
+	timeout *HandlerTimeout
+}
+
+// WithWorkers runs the handler on up to n messages concurrently, one by
+// default. With more than one worker, messages are handled out of order.
+func WithWorkers(n int) HandleOption {
+	return func(c *handleConfig) {
+		c.workers = n
+	}
+}
+
+// WithHandleErrors calls onError, from the worker, with each message whose
+// handler returned an error or panicked. Without it, errors are only
+// counted, see Stats.
+func WithHandleErrors(onError func(msg *StreamData, err error)) HandleOption {
+	return func(c *handleConfig) {
+		c.onError = onError
+	}
+}
+
+// WithHandlerTimeout runs the handler with the timeout and policy of t.
+// Timed out runs are counted in Stats.HandlerTimeouts, and messages are
+// released only once their abandoned runs returned.
+func WithHandlerTimeout(t *HandlerTimeout) HandleOption {
+	return func(c *handleConfig) {
+		c.timeout = t
+	}
+}
+
+// PanicError is the error of a handler which panicked, with the panic value
+// and the stack of the handler.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("stream: handler panicked: %v", e.Value)
+}
+
+// Handle runs h on every message of the stream with a pool of workers, see
+// WithWorkers, until the stream stopped or ctx is done, e.g.
+//
+//	err := s.Handle(ctx, func(ctx context.Context, msg *stream.StreamData) error {
+//		return store(ctx, msg.Tweet)
+//	}, stream.WithWorkers(8))
+//
+// Handlers which panic are recovered and reported as a PanicError. Handled
+// messages, handler errors and panics are counted in Stats. Handle reads
+// Messages, so it must not be combined with other consumers or
+// subscriptions. Messages of streams configured WithMessagePool are
+// released once handled. Handle waits for running handlers before
+// returning ctx.Err() if ctx is done first, or the error which terminated
+// the stream, see Err.
+func (s *Stream) Handle(ctx context.Context, h Handler, opts ...HandleOption) error {
+	cfg := handleConfig{workers: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.workers < 1 {
+		cfg.workers = 1
+	}
+	work := make(chan *StreamData)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range work {
+				s.runHandler(ctx, h, msg, &cfg)
+			}
+		}()
+	}
+	err := s.dispatch(ctx, work)
+	close(work)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	<-s.terminated
+	return s.Err()
+}
+
+// dispatch passes the messages of the stream to the workers until Messages
+// is closed, or ctx is done and its error returned.
+func (s *Stream) dispatch(ctx context.Context, work chan<- *StreamData) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-s.Messages:
+			if !ok {
+				return nil
+			}
+			select {
+			case work <- msg:
+			case <-ctx.Done():
+				msg.Release()
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// callHandler runs h on msg, recovering panics as a PanicError.
+func callHandler(ctx context.Context, h Handler, msg *StreamData) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return h(ctx, msg)
+}
+
+// runHandler runs h on msg, recovering panics, and counts the outcome.
+func (s *Stream) runHandler(ctx context.Context, h Handler, msg *StreamData, cfg *handleConfig) {
+	var pending <-chan struct{}
+	var timeouts int
+	var err error
+	if cfg.timeout != nil {
+		pending, timeouts, err = cfg.timeout.handle(ctx, h, msg)
+	} else {
+		err = callHandler(ctx, h, msg)
+	}
+	_, panicked := err.(*PanicError)
+	s.count(func(stats *Stats) {
+		stats.Handled++
+		stats.HandlerTimeouts += uint64(timeouts)
+		if panicked {
+			stats.HandlerPanics++
+		} else if err != nil {
+			stats.HandlerErrors++
+		}
+	})
+	if panicked {
+		s.log(slog.LevelError, "stream handler panicked", "error", err)
+	}
+	if err != nil && cfg.onError != nil {
+		cfg.onError(msg, err)
+	}
+	if pending != nil {
+		// an abandoned run still uses the message
+		go func() {
+			<-pending
+			msg.Release()
+		}()
+		return
+	}
+	msg.Release()
+}
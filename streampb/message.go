@@ -0,0 +1,246 @@
+package streampb
+
+import (
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ContentType is the media type of protobuf payloads, such as the bodies of
+// webhook requests.
+const ContentType = "application/x-protobuf"
+
+// NewStreamData returns msg as a StreamData message. Annotations and fields
+// which streampb.proto does not define are left out.
+func NewStreamData(msg *stream.StreamData) *StreamData {
+	m := &StreamData{Recovered: msg.Recovered}
+	if msg.Tweet != nil {
+		m.Data = newTweet(msg.Tweet)
+	}
+	if msg.Includes != nil {
+		m.Includes = newIncludes(msg.Includes)
+	}
+	for _, rule := range msg.MatchingRules {
+		m.MatchingRules = append(m.MatchingRules, newRule(rule))
+	}
+	for _, e := range msg.Errors {
+		m.Errors = append(m.Errors, &Error{
+			Title:        e.Title,
+			Detail:       e.Detail,
+			Type:         e.Type,
+			ResourceType: e.ResourceType,
+			ResourceId:   e.ResourceID,
+			Value:        e.Value,
+		})
+	}
+	return m
+}
+
+// MarshalStreamData encodes msg as a StreamData message, see NewStreamData.
+// It returns nil if msg holds strings which are not valid UTF-8, which
+// messages decoded from the stream never do.
+func MarshalStreamData(msg *stream.StreamData) []byte {
+	return marshal(NewStreamData(msg))
+}
+
+// MarshalBatch encodes StreamData messages, as returned by
+// MarshalStreamData, as a StreamDataBatch message.
+func MarshalBatch(messages [][]byte) []byte {
+	var b []byte
+	for _, msg := range messages {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg)
+	}
+	return b
+}
+
+func newTweet(t *stream.Tweet) *Tweet {
+	m := &Tweet{
+		Id:                  t.ID,
+		Text:                t.Text,
+		CreatedAt:           newTimestamp(t.CreatedAt),
+		AuthorId:            t.AuthorID,
+		ConversationId:      t.ConversationID,
+		Lang:                t.Lang,
+		PossiblySensitive:   t.PossiblySensitive,
+		EditHistoryTweetIds: t.EditHistoryTweetIDs,
+		InReplyToUserId:     t.InReplyToUserID,
+		Source:              t.Source,
+		ReplySettings:       t.ReplySettings,
+	}
+	if pm := t.PublicMetrics; pm != nil {
+		m.PublicMetrics = &PublicMetrics{
+			RetweetCount:    int64(pm.RetweetCount),
+			ReplyCount:      int64(pm.ReplyCount),
+			LikeCount:       int64(pm.LikeCount),
+			QuoteCount:      int64(pm.QuoteCount),
+			BookmarkCount:   int64(pm.BookmarkCount),
+			ImpressionCount: int64(pm.ImpressionCount),
+		}
+	}
+	for _, ref := range t.ReferencedTweets {
+		m.ReferencedTweets = append(m.ReferencedTweets, &ReferencedTweet{Type: ref.Type, Id: ref.ID})
+	}
+	if a := t.Attachments; a != nil {
+		m.Attachments = &Attachments{MediaKeys: a.MediaKeys, PollIds: a.PollIDs}
+	}
+	if g := t.Geo; g != nil {
+		m.Geo = &Geo{PlaceId: g.PlaceID}
+		if g.Coordinates != nil {
+			m.Geo.Coordinates = g.Coordinates.Coordinates
+		}
+	}
+	if t.Entities != nil {
+		m.Entities = newEntities(t.Entities)
+	}
+	if c := t.EditControls; c != nil {
+		m.EditControls = &EditControls{
+			EditsRemaining: int64(c.EditsRemaining),
+			IsEditEligible: c.IsEditEligible,
+			EditableUntil:  newTimestamp(c.EditableUntil),
+		}
+	}
+	if n := t.NoteTweet; n != nil {
+		m.NoteTweet = &NoteTweet{Text: n.Text}
+		if n.Entities != nil {
+			m.NoteTweet.Entities = newEntities(n.Entities)
+		}
+	}
+	return m
+}
+
+func newEntities(e *stream.Entities) *Entities {
+	m := &Entities{}
+	for _, tag := range e.Hashtags {
+		m.Hashtags = append(m.Hashtags, newTagEntity(tag))
+	}
+	for _, tag := range e.Cashtags {
+		m.Cashtags = append(m.Cashtags, newTagEntity(tag))
+	}
+	for _, mention := range e.Mentions {
+		m.Mentions = append(m.Mentions, &MentionEntity{
+			Start:    int64(mention.Start),
+			End:      int64(mention.End),
+			Username: mention.Username,
+			Id:       mention.ID,
+		})
+	}
+	for _, u := range e.URLs {
+		m.Urls = append(m.Urls, &URLEntity{
+			Start:       int64(u.Start),
+			End:         int64(u.End),
+			Url:         u.URL,
+			ExpandedUrl: u.ExpandedURL,
+			DisplayUrl:  u.DisplayURL,
+			UnwoundUrl:  u.UnwoundURL,
+			Status:      int64(u.Status),
+			Title:       u.Title,
+			Description: u.Description,
+			MediaKey:    u.MediaKey,
+		})
+	}
+	return m
+}
+
+func newTagEntity(tag *stream.TagEntity) *TagEntity {
+	return &TagEntity{Start: int64(tag.Start), End: int64(tag.End), Tag: tag.Tag}
+}
+
+func newUser(u *stream.User) *User {
+	m := &User{
+		Id:              u.ID,
+		Name:            u.Name,
+		Username:        u.Username,
+		CreatedAt:       newTimestamp(u.CreatedAt),
+		Description:     u.Description,
+		Location:        u.Location,
+		PinnedTweetId:   u.PinnedTweetID,
+		ProfileImageUrl: u.ProfileImageURL,
+		Protected:       u.Protected,
+		Url:             u.URL,
+		Verified:        u.Verified,
+		VerifiedType:    u.VerifiedType,
+	}
+	if pm := u.PublicMetrics; pm != nil {
+		m.PublicMetrics = &UserPublicMetrics{
+			FollowersCount: int64(pm.FollowersCount),
+			FollowingCount: int64(pm.FollowingCount),
+			TweetCount:     int64(pm.TweetCount),
+			ListedCount:    int64(pm.ListedCount),
+			LikeCount:      int64(pm.LikeCount),
+		}
+	}
+	return m
+}
+
+func newIncludes(inc *stream.Includes) *Includes {
+	m := &Includes{}
+	for _, u := range inc.Users {
+		m.Users = append(m.Users, newUser(u))
+	}
+	for _, media := range inc.Media {
+		mm := &Media{
+			MediaKey:        media.MediaKey,
+			Type:            media.Type,
+			Url:             media.URL,
+			PreviewImageUrl: media.PreviewImageURL,
+			DurationMs:      int64(media.DurationMS),
+			Height:          int64(media.Height),
+			Width:           int64(media.Width),
+			AltText:         media.AltText,
+		}
+		if media.PublicMetrics != nil {
+			mm.ViewCount = int64(media.PublicMetrics.ViewCount)
+		}
+		for _, v := range media.Variants {
+			mm.Variants = append(mm.Variants, &MediaVariant{BitRate: int64(v.BitRate), ContentType: v.ContentType, Url: v.URL})
+		}
+		m.Media = append(m.Media, mm)
+	}
+	for _, p := range inc.Places {
+		mp := &Place{
+			Id:              p.ID,
+			FullName:        p.FullName,
+			Name:            p.Name,
+			Country:         p.Country,
+			CountryCode:     p.CountryCode,
+			PlaceType:       p.PlaceType,
+			ContainedWithin: p.ContainedWithin,
+		}
+		if p.Geo != nil {
+			mp.Bbox = p.Geo.BBox
+		}
+		m.Places = append(m.Places, mp)
+	}
+	for _, p := range inc.Polls {
+		mp := &Poll{
+			Id:              p.ID,
+			DurationMinutes: int64(p.DurationMinutes),
+			EndDatetime:     newTimestamp(p.EndDatetime),
+			VotingStatus:    p.VotingStatus,
+		}
+		for _, o := range p.Options {
+			mp.Options = append(mp.Options, &PollOption{Position: int64(o.Position), Label: o.Label, Votes: int64(o.Votes)})
+		}
+		m.Polls = append(m.Polls, mp)
+	}
+	for _, t := range inc.Tweets {
+		m.Tweets = append(m.Tweets, newTweet(t))
+	}
+	return m
+}
+
+func newRule(rule *stream.MatchingRule) *MatchingRule {
+	return &MatchingRule{Id: rule.Id, Tag: rule.Tag}
+}
+
+// newTimestamp returns t as a google.protobuf.Timestamp, or nil for the
+// zero time.
+func newTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,73 @@
+// Package streampb holds the protobuf messages and the Relay gRPC service of
+// streampb.proto, generated with protoc-gen-go and protoc-gen-go-grpc, for
+// consumers preferring typed contracts over JSON, and converts stream
+// messages to and from them: the Tweets of the Relay service, and whole
+// messages as StreamData for sinks writing compact records.
+package streampb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative streampb.proto
+
+import (
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewTweet returns the Tweet of msg, with its author and matching rules, as
+// a Tweet message. It returns nil for messages without a Tweet.
+func NewTweet(msg *stream.StreamData) *Tweet {
+	if msg.Tweet == nil {
+		return nil
+	}
+	t := newTweet(msg.Tweet)
+	if u := author(msg); u != nil {
+		t.Author = newUser(u)
+	}
+	for _, rule := range msg.MatchingRules {
+		t.MatchingRules = append(t.MatchingRules, newRule(rule))
+	}
+	return t
+}
+
+// MarshalTweet encodes the Tweet of msg, with its author and matching
+// rules, as a Tweet message. It returns nil for messages without a Tweet,
+// and for invalid messages, see MarshalStreamData.
+func MarshalTweet(msg *stream.StreamData) []byte {
+	t := NewTweet(msg)
+	if t == nil {
+		return nil
+	}
+	return marshal(t)
+}
+
+// author returns the expanded author of the Tweet of msg, if included.
+func author(msg *stream.StreamData) *stream.User {
+	if msg.Includes == nil || msg.Tweet.AuthorID == "" {
+		return nil
+	}
+	for _, u := range msg.Includes.Users {
+		if u.ID == msg.Tweet.AuthorID {
+			return u
+		}
+	}
+	return nil
+}
+
+// UnmarshalFilterRequest decodes a FilterRequest message and returns its
+// tags.
+func UnmarshalFilterRequest(data []byte) (tags []string, err error) {
+	var req FilterRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return req.Tags, nil
+}
+
+// marshal encodes m, or returns nil if it holds strings which are not valid
+// UTF-8, which strings decoded from JSON always are.
+func marshal(m proto.Message) []byte {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,2179 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: streampb.proto
+
+package streampb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FilterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterRequest) Reset() {
+	*x = FilterRequest{}
+	mi := &file_streampb_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterRequest) ProtoMessage() {}
+
+func (x *FilterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterRequest.ProtoReflect.Descriptor instead.
+func (*FilterRequest) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FilterRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type Tweet struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text                string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	AuthorId            string                 `protobuf:"bytes,4,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	ConversationId      string                 `protobuf:"bytes,5,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Lang                string                 `protobuf:"bytes,6,opt,name=lang,proto3" json:"lang,omitempty"`
+	PossiblySensitive   bool                   `protobuf:"varint,7,opt,name=possibly_sensitive,json=possiblySensitive,proto3" json:"possibly_sensitive,omitempty"`
+	PublicMetrics       *PublicMetrics         `protobuf:"bytes,8,opt,name=public_metrics,json=publicMetrics,proto3" json:"public_metrics,omitempty"`
+	EditHistoryTweetIds []string               `protobuf:"bytes,9,rep,name=edit_history_tweet_ids,json=editHistoryTweetIds,proto3" json:"edit_history_tweet_ids,omitempty"`
+	// author is the expanded author, if requested with the author_id
+	// expansion.
+	Author           *User              `protobuf:"bytes,10,opt,name=author,proto3" json:"author,omitempty"`
+	MatchingRules    []*MatchingRule    `protobuf:"bytes,11,rep,name=matching_rules,json=matchingRules,proto3" json:"matching_rules,omitempty"`
+	InReplyToUserId  string             `protobuf:"bytes,12,opt,name=in_reply_to_user_id,json=inReplyToUserId,proto3" json:"in_reply_to_user_id,omitempty"`
+	Source           string             `protobuf:"bytes,13,opt,name=source,proto3" json:"source,omitempty"`
+	ReplySettings    string             `protobuf:"bytes,14,opt,name=reply_settings,json=replySettings,proto3" json:"reply_settings,omitempty"`
+	ReferencedTweets []*ReferencedTweet `protobuf:"bytes,15,rep,name=referenced_tweets,json=referencedTweets,proto3" json:"referenced_tweets,omitempty"`
+	Attachments      *Attachments       `protobuf:"bytes,16,opt,name=attachments,proto3" json:"attachments,omitempty"`
+	Geo              *Geo               `protobuf:"bytes,17,opt,name=geo,proto3" json:"geo,omitempty"`
+	Entities         *Entities          `protobuf:"bytes,18,opt,name=entities,proto3" json:"entities,omitempty"`
+	EditControls     *EditControls      `protobuf:"bytes,19,opt,name=edit_controls,json=editControls,proto3" json:"edit_controls,omitempty"`
+	NoteTweet        *NoteTweet         `protobuf:"bytes,20,opt,name=note_tweet,json=noteTweet,proto3" json:"note_tweet,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Tweet) Reset() {
+	*x = Tweet{}
+	mi := &file_streampb_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tweet) ProtoMessage() {}
+
+func (x *Tweet) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tweet.ProtoReflect.Descriptor instead.
+func (*Tweet) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Tweet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Tweet) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Tweet) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Tweet) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *Tweet) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *Tweet) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *Tweet) GetPossiblySensitive() bool {
+	if x != nil {
+		return x.PossiblySensitive
+	}
+	return false
+}
+
+func (x *Tweet) GetPublicMetrics() *PublicMetrics {
+	if x != nil {
+		return x.PublicMetrics
+	}
+	return nil
+}
+
+func (x *Tweet) GetEditHistoryTweetIds() []string {
+	if x != nil {
+		return x.EditHistoryTweetIds
+	}
+	return nil
+}
+
+func (x *Tweet) GetAuthor() *User {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Tweet) GetMatchingRules() []*MatchingRule {
+	if x != nil {
+		return x.MatchingRules
+	}
+	return nil
+}
+
+func (x *Tweet) GetInReplyToUserId() string {
+	if x != nil {
+		return x.InReplyToUserId
+	}
+	return ""
+}
+
+func (x *Tweet) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Tweet) GetReplySettings() string {
+	if x != nil {
+		return x.ReplySettings
+	}
+	return ""
+}
+
+func (x *Tweet) GetReferencedTweets() []*ReferencedTweet {
+	if x != nil {
+		return x.ReferencedTweets
+	}
+	return nil
+}
+
+func (x *Tweet) GetAttachments() *Attachments {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *Tweet) GetGeo() *Geo {
+	if x != nil {
+		return x.Geo
+	}
+	return nil
+}
+
+func (x *Tweet) GetEntities() *Entities {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *Tweet) GetEditControls() *EditControls {
+	if x != nil {
+		return x.EditControls
+	}
+	return nil
+}
+
+func (x *Tweet) GetNoteTweet() *NoteTweet {
+	if x != nil {
+		return x.NoteTweet
+	}
+	return nil
+}
+
+type PublicMetrics struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RetweetCount    int64                  `protobuf:"varint,1,opt,name=retweet_count,json=retweetCount,proto3" json:"retweet_count,omitempty"`
+	ReplyCount      int64                  `protobuf:"varint,2,opt,name=reply_count,json=replyCount,proto3" json:"reply_count,omitempty"`
+	LikeCount       int64                  `protobuf:"varint,3,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`
+	QuoteCount      int64                  `protobuf:"varint,4,opt,name=quote_count,json=quoteCount,proto3" json:"quote_count,omitempty"`
+	BookmarkCount   int64                  `protobuf:"varint,5,opt,name=bookmark_count,json=bookmarkCount,proto3" json:"bookmark_count,omitempty"`
+	ImpressionCount int64                  `protobuf:"varint,6,opt,name=impression_count,json=impressionCount,proto3" json:"impression_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PublicMetrics) Reset() {
+	*x = PublicMetrics{}
+	mi := &file_streampb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublicMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublicMetrics) ProtoMessage() {}
+
+func (x *PublicMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublicMetrics.ProtoReflect.Descriptor instead.
+func (*PublicMetrics) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PublicMetrics) GetRetweetCount() int64 {
+	if x != nil {
+		return x.RetweetCount
+	}
+	return 0
+}
+
+func (x *PublicMetrics) GetReplyCount() int64 {
+	if x != nil {
+		return x.ReplyCount
+	}
+	return 0
+}
+
+func (x *PublicMetrics) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+func (x *PublicMetrics) GetQuoteCount() int64 {
+	if x != nil {
+		return x.QuoteCount
+	}
+	return 0
+}
+
+func (x *PublicMetrics) GetBookmarkCount() int64 {
+	if x != nil {
+		return x.BookmarkCount
+	}
+	return 0
+}
+
+func (x *PublicMetrics) GetImpressionCount() int64 {
+	if x != nil {
+		return x.ImpressionCount
+	}
+	return 0
+}
+
+type User struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Username        string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Description     string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Location        string                 `protobuf:"bytes,6,opt,name=location,proto3" json:"location,omitempty"`
+	PinnedTweetId   string                 `protobuf:"bytes,7,opt,name=pinned_tweet_id,json=pinnedTweetId,proto3" json:"pinned_tweet_id,omitempty"`
+	ProfileImageUrl string                 `protobuf:"bytes,8,opt,name=profile_image_url,json=profileImageUrl,proto3" json:"profile_image_url,omitempty"`
+	Protected       bool                   `protobuf:"varint,9,opt,name=protected,proto3" json:"protected,omitempty"`
+	Url             string                 `protobuf:"bytes,10,opt,name=url,proto3" json:"url,omitempty"`
+	Verified        bool                   `protobuf:"varint,11,opt,name=verified,proto3" json:"verified,omitempty"`
+	VerifiedType    string                 `protobuf:"bytes,12,opt,name=verified_type,json=verifiedType,proto3" json:"verified_type,omitempty"`
+	PublicMetrics   *UserPublicMetrics     `protobuf:"bytes,13,opt,name=public_metrics,json=publicMetrics,proto3" json:"public_metrics,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_streampb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *User) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *User) GetPinnedTweetId() string {
+	if x != nil {
+		return x.PinnedTweetId
+	}
+	return ""
+}
+
+func (x *User) GetProfileImageUrl() string {
+	if x != nil {
+		return x.ProfileImageUrl
+	}
+	return ""
+}
+
+func (x *User) GetProtected() bool {
+	if x != nil {
+		return x.Protected
+	}
+	return false
+}
+
+func (x *User) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *User) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *User) GetVerifiedType() string {
+	if x != nil {
+		return x.VerifiedType
+	}
+	return ""
+}
+
+func (x *User) GetPublicMetrics() *UserPublicMetrics {
+	if x != nil {
+		return x.PublicMetrics
+	}
+	return nil
+}
+
+type UserPublicMetrics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FollowersCount int64                  `protobuf:"varint,1,opt,name=followers_count,json=followersCount,proto3" json:"followers_count,omitempty"`
+	FollowingCount int64                  `protobuf:"varint,2,opt,name=following_count,json=followingCount,proto3" json:"following_count,omitempty"`
+	TweetCount     int64                  `protobuf:"varint,3,opt,name=tweet_count,json=tweetCount,proto3" json:"tweet_count,omitempty"`
+	ListedCount    int64                  `protobuf:"varint,4,opt,name=listed_count,json=listedCount,proto3" json:"listed_count,omitempty"`
+	LikeCount      int64                  `protobuf:"varint,5,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UserPublicMetrics) Reset() {
+	*x = UserPublicMetrics{}
+	mi := &file_streampb_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserPublicMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserPublicMetrics) ProtoMessage() {}
+
+func (x *UserPublicMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserPublicMetrics.ProtoReflect.Descriptor instead.
+func (*UserPublicMetrics) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UserPublicMetrics) GetFollowersCount() int64 {
+	if x != nil {
+		return x.FollowersCount
+	}
+	return 0
+}
+
+func (x *UserPublicMetrics) GetFollowingCount() int64 {
+	if x != nil {
+		return x.FollowingCount
+	}
+	return 0
+}
+
+func (x *UserPublicMetrics) GetTweetCount() int64 {
+	if x != nil {
+		return x.TweetCount
+	}
+	return 0
+}
+
+func (x *UserPublicMetrics) GetListedCount() int64 {
+	if x != nil {
+		return x.ListedCount
+	}
+	return 0
+}
+
+func (x *UserPublicMetrics) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+type MatchingRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchingRule) Reset() {
+	*x = MatchingRule{}
+	mi := &file_streampb_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchingRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchingRule) ProtoMessage() {}
+
+func (x *MatchingRule) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchingRule.ProtoReflect.Descriptor instead.
+func (*MatchingRule) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MatchingRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MatchingRule) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+// StreamData is a message of the stream, as written by sinks encoding
+// messages as protobuf. The author and matching_rules of data are left
+// unset, the author being one of the users of includes.
+type StreamData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          *Tweet                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Includes      *Includes              `protobuf:"bytes,2,opt,name=includes,proto3" json:"includes,omitempty"`
+	MatchingRules []*MatchingRule        `protobuf:"bytes,3,rep,name=matching_rules,json=matchingRules,proto3" json:"matching_rules,omitempty"`
+	Errors        []*Error               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	Recovered     bool                   `protobuf:"varint,5,opt,name=recovered,proto3" json:"recovered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamData) Reset() {
+	*x = StreamData{}
+	mi := &file_streampb_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamData) ProtoMessage() {}
+
+func (x *StreamData) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamData.ProtoReflect.Descriptor instead.
+func (*StreamData) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StreamData) GetData() *Tweet {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *StreamData) GetIncludes() *Includes {
+	if x != nil {
+		return x.Includes
+	}
+	return nil
+}
+
+func (x *StreamData) GetMatchingRules() []*MatchingRule {
+	if x != nil {
+		return x.MatchingRules
+	}
+	return nil
+}
+
+func (x *StreamData) GetErrors() []*Error {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *StreamData) GetRecovered() bool {
+	if x != nil {
+		return x.Recovered
+	}
+	return false
+}
+
+// StreamDataBatch is a batch of messages, as posted by webhook sinks
+// batching protobuf messages.
+type StreamDataBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*StreamData          `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamDataBatch) Reset() {
+	*x = StreamDataBatch{}
+	mi := &file_streampb_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamDataBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDataBatch) ProtoMessage() {}
+
+func (x *StreamDataBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDataBatch.ProtoReflect.Descriptor instead.
+func (*StreamDataBatch) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamDataBatch) GetMessages() []*StreamData {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type Includes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Media         []*Media               `protobuf:"bytes,2,rep,name=media,proto3" json:"media,omitempty"`
+	Places        []*Place               `protobuf:"bytes,3,rep,name=places,proto3" json:"places,omitempty"`
+	Polls         []*Poll                `protobuf:"bytes,4,rep,name=polls,proto3" json:"polls,omitempty"`
+	Tweets        []*Tweet               `protobuf:"bytes,5,rep,name=tweets,proto3" json:"tweets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Includes) Reset() {
+	*x = Includes{}
+	mi := &file_streampb_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Includes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Includes) ProtoMessage() {}
+
+func (x *Includes) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Includes.ProtoReflect.Descriptor instead.
+func (*Includes) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Includes) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *Includes) GetMedia() []*Media {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+func (x *Includes) GetPlaces() []*Place {
+	if x != nil {
+		return x.Places
+	}
+	return nil
+}
+
+func (x *Includes) GetPolls() []*Poll {
+	if x != nil {
+		return x.Polls
+	}
+	return nil
+}
+
+func (x *Includes) GetTweets() []*Tweet {
+	if x != nil {
+		return x.Tweets
+	}
+	return nil
+}
+
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Detail        string                 `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ResourceType  string                 `protobuf:"bytes,4,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	ResourceId    string                 `protobuf:"bytes,5,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Value         string                 `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_streampb_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Error) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Error) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *Error) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Error) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *Error) GetResourceId() string {
+	if x != nil {
+		return x.ResourceId
+	}
+	return ""
+}
+
+func (x *Error) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type ReferencedTweet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReferencedTweet) Reset() {
+	*x = ReferencedTweet{}
+	mi := &file_streampb_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReferencedTweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReferencedTweet) ProtoMessage() {}
+
+func (x *ReferencedTweet) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReferencedTweet.ProtoReflect.Descriptor instead.
+func (*ReferencedTweet) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ReferencedTweet) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ReferencedTweet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Attachments struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MediaKeys     []string               `protobuf:"bytes,1,rep,name=media_keys,json=mediaKeys,proto3" json:"media_keys,omitempty"`
+	PollIds       []string               `protobuf:"bytes,2,rep,name=poll_ids,json=pollIds,proto3" json:"poll_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachments) Reset() {
+	*x = Attachments{}
+	mi := &file_streampb_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachments) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachments) ProtoMessage() {}
+
+func (x *Attachments) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachments.ProtoReflect.Descriptor instead.
+func (*Attachments) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Attachments) GetMediaKeys() []string {
+	if x != nil {
+		return x.MediaKeys
+	}
+	return nil
+}
+
+func (x *Attachments) GetPollIds() []string {
+	if x != nil {
+		return x.PollIds
+	}
+	return nil
+}
+
+type Geo struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	PlaceId string                 `protobuf:"bytes,1,opt,name=place_id,json=placeId,proto3" json:"place_id,omitempty"`
+	// coordinates are the longitude and latitude of the point, if tagged.
+	Coordinates   []float64 `protobuf:"fixed64,2,rep,packed,name=coordinates,proto3" json:"coordinates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Geo) Reset() {
+	*x = Geo{}
+	mi := &file_streampb_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Geo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Geo) ProtoMessage() {}
+
+func (x *Geo) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Geo.ProtoReflect.Descriptor instead.
+func (*Geo) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Geo) GetPlaceId() string {
+	if x != nil {
+		return x.PlaceId
+	}
+	return ""
+}
+
+func (x *Geo) GetCoordinates() []float64 {
+	if x != nil {
+		return x.Coordinates
+	}
+	return nil
+}
+
+type Entities struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hashtags      []*TagEntity           `protobuf:"bytes,1,rep,name=hashtags,proto3" json:"hashtags,omitempty"`
+	Cashtags      []*TagEntity           `protobuf:"bytes,2,rep,name=cashtags,proto3" json:"cashtags,omitempty"`
+	Mentions      []*MentionEntity       `protobuf:"bytes,3,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	Urls          []*URLEntity           `protobuf:"bytes,4,rep,name=urls,proto3" json:"urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entities) Reset() {
+	*x = Entities{}
+	mi := &file_streampb_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entities) ProtoMessage() {}
+
+func (x *Entities) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entities.ProtoReflect.Descriptor instead.
+func (*Entities) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Entities) GetHashtags() []*TagEntity {
+	if x != nil {
+		return x.Hashtags
+	}
+	return nil
+}
+
+func (x *Entities) GetCashtags() []*TagEntity {
+	if x != nil {
+		return x.Cashtags
+	}
+	return nil
+}
+
+func (x *Entities) GetMentions() []*MentionEntity {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+func (x *Entities) GetUrls() []*URLEntity {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type TagEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagEntity) Reset() {
+	*x = TagEntity{}
+	mi := &file_streampb_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagEntity) ProtoMessage() {}
+
+func (x *TagEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagEntity.ProtoReflect.Descriptor instead.
+func (*TagEntity) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TagEntity) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *TagEntity) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *TagEntity) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type MentionEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Id            string                 `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MentionEntity) Reset() {
+	*x = MentionEntity{}
+	mi := &file_streampb_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MentionEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MentionEntity) ProtoMessage() {}
+
+func (x *MentionEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MentionEntity.ProtoReflect.Descriptor instead.
+func (*MentionEntity) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MentionEntity) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *MentionEntity) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *MentionEntity) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *MentionEntity) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type URLEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	ExpandedUrl   string                 `protobuf:"bytes,4,opt,name=expanded_url,json=expandedUrl,proto3" json:"expanded_url,omitempty"`
+	DisplayUrl    string                 `protobuf:"bytes,5,opt,name=display_url,json=displayUrl,proto3" json:"display_url,omitempty"`
+	UnwoundUrl    string                 `protobuf:"bytes,6,opt,name=unwound_url,json=unwoundUrl,proto3" json:"unwound_url,omitempty"`
+	Status        int64                  `protobuf:"varint,7,opt,name=status,proto3" json:"status,omitempty"`
+	Title         string                 `protobuf:"bytes,8,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	MediaKey      string                 `protobuf:"bytes,10,opt,name=media_key,json=mediaKey,proto3" json:"media_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLEntity) Reset() {
+	*x = URLEntity{}
+	mi := &file_streampb_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLEntity) ProtoMessage() {}
+
+func (x *URLEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLEntity.ProtoReflect.Descriptor instead.
+func (*URLEntity) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *URLEntity) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *URLEntity) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *URLEntity) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *URLEntity) GetExpandedUrl() string {
+	if x != nil {
+		return x.ExpandedUrl
+	}
+	return ""
+}
+
+func (x *URLEntity) GetDisplayUrl() string {
+	if x != nil {
+		return x.DisplayUrl
+	}
+	return ""
+}
+
+func (x *URLEntity) GetUnwoundUrl() string {
+	if x != nil {
+		return x.UnwoundUrl
+	}
+	return ""
+}
+
+func (x *URLEntity) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *URLEntity) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *URLEntity) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *URLEntity) GetMediaKey() string {
+	if x != nil {
+		return x.MediaKey
+	}
+	return ""
+}
+
+type EditControls struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EditsRemaining int64                  `protobuf:"varint,1,opt,name=edits_remaining,json=editsRemaining,proto3" json:"edits_remaining,omitempty"`
+	IsEditEligible bool                   `protobuf:"varint,2,opt,name=is_edit_eligible,json=isEditEligible,proto3" json:"is_edit_eligible,omitempty"`
+	EditableUntil  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=editable_until,json=editableUntil,proto3" json:"editable_until,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EditControls) Reset() {
+	*x = EditControls{}
+	mi := &file_streampb_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditControls) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditControls) ProtoMessage() {}
+
+func (x *EditControls) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditControls.ProtoReflect.Descriptor instead.
+func (*EditControls) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EditControls) GetEditsRemaining() int64 {
+	if x != nil {
+		return x.EditsRemaining
+	}
+	return 0
+}
+
+func (x *EditControls) GetIsEditEligible() bool {
+	if x != nil {
+		return x.IsEditEligible
+	}
+	return false
+}
+
+func (x *EditControls) GetEditableUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EditableUntil
+	}
+	return nil
+}
+
+type NoteTweet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Entities      *Entities              `protobuf:"bytes,2,opt,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NoteTweet) Reset() {
+	*x = NoteTweet{}
+	mi := &file_streampb_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NoteTweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteTweet) ProtoMessage() {}
+
+func (x *NoteTweet) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteTweet.ProtoReflect.Descriptor instead.
+func (*NoteTweet) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *NoteTweet) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *NoteTweet) GetEntities() *Entities {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+type Media struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MediaKey        string                 `protobuf:"bytes,1,opt,name=media_key,json=mediaKey,proto3" json:"media_key,omitempty"`
+	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Url             string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	PreviewImageUrl string                 `protobuf:"bytes,4,opt,name=preview_image_url,json=previewImageUrl,proto3" json:"preview_image_url,omitempty"`
+	DurationMs      int64                  `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Height          int64                  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	Width           int64                  `protobuf:"varint,7,opt,name=width,proto3" json:"width,omitempty"`
+	AltText         string                 `protobuf:"bytes,8,opt,name=alt_text,json=altText,proto3" json:"alt_text,omitempty"`
+	ViewCount       int64                  `protobuf:"varint,9,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`
+	Variants        []*MediaVariant        `protobuf:"bytes,10,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Media) Reset() {
+	*x = Media{}
+	mi := &file_streampb_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Media) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Media) ProtoMessage() {}
+
+func (x *Media) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Media.ProtoReflect.Descriptor instead.
+func (*Media) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Media) GetMediaKey() string {
+	if x != nil {
+		return x.MediaKey
+	}
+	return ""
+}
+
+func (x *Media) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Media) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Media) GetPreviewImageUrl() string {
+	if x != nil {
+		return x.PreviewImageUrl
+	}
+	return ""
+}
+
+func (x *Media) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *Media) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Media) GetWidth() int64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Media) GetAltText() string {
+	if x != nil {
+		return x.AltText
+	}
+	return ""
+}
+
+func (x *Media) GetViewCount() int64 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *Media) GetVariants() []*MediaVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type MediaVariant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BitRate       int64                  `protobuf:"varint,1,opt,name=bit_rate,json=bitRate,proto3" json:"bit_rate,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MediaVariant) Reset() {
+	*x = MediaVariant{}
+	mi := &file_streampb_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediaVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediaVariant) ProtoMessage() {}
+
+func (x *MediaVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediaVariant.ProtoReflect.Descriptor instead.
+func (*MediaVariant) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *MediaVariant) GetBitRate() int64 {
+	if x != nil {
+		return x.BitRate
+	}
+	return 0
+}
+
+func (x *MediaVariant) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *MediaVariant) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type Place struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FullName        string                 `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Country         string                 `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	CountryCode     string                 `protobuf:"bytes,5,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	PlaceType       string                 `protobuf:"bytes,6,opt,name=place_type,json=placeType,proto3" json:"place_type,omitempty"`
+	ContainedWithin []string               `protobuf:"bytes,7,rep,name=contained_within,json=containedWithin,proto3" json:"contained_within,omitempty"`
+	// bbox is the west, south, east and north bound of the place.
+	Bbox          []float64 `protobuf:"fixed64,8,rep,packed,name=bbox,proto3" json:"bbox,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Place) Reset() {
+	*x = Place{}
+	mi := &file_streampb_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Place) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Place) ProtoMessage() {}
+
+func (x *Place) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Place.ProtoReflect.Descriptor instead.
+func (*Place) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Place) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Place) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *Place) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Place) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Place) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *Place) GetPlaceType() string {
+	if x != nil {
+		return x.PlaceType
+	}
+	return ""
+}
+
+func (x *Place) GetContainedWithin() []string {
+	if x != nil {
+		return x.ContainedWithin
+	}
+	return nil
+}
+
+func (x *Place) GetBbox() []float64 {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+type Poll struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Options         []*PollOption          `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	DurationMinutes int64                  `protobuf:"varint,3,opt,name=duration_minutes,json=durationMinutes,proto3" json:"duration_minutes,omitempty"`
+	EndDatetime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_datetime,json=endDatetime,proto3" json:"end_datetime,omitempty"`
+	VotingStatus    string                 `protobuf:"bytes,5,opt,name=voting_status,json=votingStatus,proto3" json:"voting_status,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Poll) Reset() {
+	*x = Poll{}
+	mi := &file_streampb_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Poll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Poll) ProtoMessage() {}
+
+func (x *Poll) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Poll.ProtoReflect.Descriptor instead.
+func (*Poll) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Poll) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Poll) GetOptions() []*PollOption {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *Poll) GetDurationMinutes() int64 {
+	if x != nil {
+		return x.DurationMinutes
+	}
+	return 0
+}
+
+func (x *Poll) GetEndDatetime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDatetime
+	}
+	return nil
+}
+
+func (x *Poll) GetVotingStatus() string {
+	if x != nil {
+		return x.VotingStatus
+	}
+	return ""
+}
+
+type PollOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      int64                  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Votes         int64                  `protobuf:"varint,3,opt,name=votes,proto3" json:"votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollOption) Reset() {
+	*x = PollOption{}
+	mi := &file_streampb_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollOption) ProtoMessage() {}
+
+func (x *PollOption) ProtoReflect() protoreflect.Message {
+	mi := &file_streampb_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollOption.ProtoReflect.Descriptor instead.
+func (*PollOption) Descriptor() ([]byte, []int) {
+	return file_streampb_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PollOption) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *PollOption) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *PollOption) GetVotes() int64 {
+	if x != nil {
+		return x.Votes
+	}
+	return 0
+}
+
+var File_streampb_proto protoreflect.FileDescriptor
+
+const file_streampb_proto_rawDesc = "" +
+	"\n" +
+	"\x0estreampb.proto\x12\x11twitter.stream.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"#\n" +
+	"\rFilterRequest\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"\xcc\a\n" +
+	"\x05Tweet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1b\n" +
+	"\tauthor_id\x18\x04 \x01(\tR\bauthorId\x12'\n" +
+	"\x0fconversation_id\x18\x05 \x01(\tR\x0econversationId\x12\x12\n" +
+	"\x04lang\x18\x06 \x01(\tR\x04lang\x12-\n" +
+	"\x12possibly_sensitive\x18\a \x01(\bR\x11possiblySensitive\x12G\n" +
+	"\x0epublic_metrics\x18\b \x01(\v2 .twitter.stream.v1.PublicMetricsR\rpublicMetrics\x123\n" +
+	"\x16edit_history_tweet_ids\x18\t \x03(\tR\x13editHistoryTweetIds\x12/\n" +
+	"\x06author\x18\n" +
+	" \x01(\v2\x17.twitter.stream.v1.UserR\x06author\x12F\n" +
+	"\x0ematching_rules\x18\v \x03(\v2\x1f.twitter.stream.v1.MatchingRuleR\rmatchingRules\x12,\n" +
+	"\x13in_reply_to_user_id\x18\f \x01(\tR\x0finReplyToUserId\x12\x16\n" +
+	"\x06source\x18\r \x01(\tR\x06source\x12%\n" +
+	"\x0ereply_settings\x18\x0e \x01(\tR\rreplySettings\x12O\n" +
+	"\x11referenced_tweets\x18\x0f \x03(\v2\".twitter.stream.v1.ReferencedTweetR\x10referencedTweets\x12@\n" +
+	"\vattachments\x18\x10 \x01(\v2\x1e.twitter.stream.v1.AttachmentsR\vattachments\x12(\n" +
+	"\x03geo\x18\x11 \x01(\v2\x16.twitter.stream.v1.GeoR\x03geo\x127\n" +
+	"\bentities\x18\x12 \x01(\v2\x1b.twitter.stream.v1.EntitiesR\bentities\x12D\n" +
+	"\redit_controls\x18\x13 \x01(\v2\x1f.twitter.stream.v1.EditControlsR\feditControls\x12;\n" +
+	"\n" +
+	"note_tweet\x18\x14 \x01(\v2\x1c.twitter.stream.v1.NoteTweetR\tnoteTweet\"\xe7\x01\n" +
+	"\rPublicMetrics\x12#\n" +
+	"\rretweet_count\x18\x01 \x01(\x03R\fretweetCount\x12\x1f\n" +
+	"\vreply_count\x18\x02 \x01(\x03R\n" +
+	"replyCount\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\x03 \x01(\x03R\tlikeCount\x12\x1f\n" +
+	"\vquote_count\x18\x04 \x01(\x03R\n" +
+	"quoteCount\x12%\n" +
+	"\x0ebookmark_count\x18\x05 \x01(\x03R\rbookmarkCount\x12)\n" +
+	"\x10impression_count\x18\x06 \x01(\x03R\x0fimpressionCount\"\xd1\x03\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1a\n" +
+	"\blocation\x18\x06 \x01(\tR\blocation\x12&\n" +
+	"\x0fpinned_tweet_id\x18\a \x01(\tR\rpinnedTweetId\x12*\n" +
+	"\x11profile_image_url\x18\b \x01(\tR\x0fprofileImageUrl\x12\x1c\n" +
+	"\tprotected\x18\t \x01(\bR\tprotected\x12\x10\n" +
+	"\x03url\x18\n" +
+	" \x01(\tR\x03url\x12\x1a\n" +
+	"\bverified\x18\v \x01(\bR\bverified\x12#\n" +
+	"\rverified_type\x18\f \x01(\tR\fverifiedType\x12K\n" +
+	"\x0epublic_metrics\x18\r \x01(\v2$.twitter.stream.v1.UserPublicMetricsR\rpublicMetrics\"\xc8\x01\n" +
+	"\x11UserPublicMetrics\x12'\n" +
+	"\x0ffollowers_count\x18\x01 \x01(\x03R\x0efollowersCount\x12'\n" +
+	"\x0ffollowing_count\x18\x02 \x01(\x03R\x0efollowingCount\x12\x1f\n" +
+	"\vtweet_count\x18\x03 \x01(\x03R\n" +
+	"tweetCount\x12!\n" +
+	"\flisted_count\x18\x04 \x01(\x03R\vlistedCount\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\x05 \x01(\x03R\tlikeCount\"0\n" +
+	"\fMatchingRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"\x8b\x02\n" +
+	"\n" +
+	"StreamData\x12,\n" +
+	"\x04data\x18\x01 \x01(\v2\x18.twitter.stream.v1.TweetR\x04data\x127\n" +
+	"\bincludes\x18\x02 \x01(\v2\x1b.twitter.stream.v1.IncludesR\bincludes\x12F\n" +
+	"\x0ematching_rules\x18\x03 \x03(\v2\x1f.twitter.stream.v1.MatchingRuleR\rmatchingRules\x120\n" +
+	"\x06errors\x18\x04 \x03(\v2\x18.twitter.stream.v1.ErrorR\x06errors\x12\x1c\n" +
+	"\trecovered\x18\x05 \x01(\bR\trecovered\"L\n" +
+	"\x0fStreamDataBatch\x129\n" +
+	"\bmessages\x18\x01 \x03(\v2\x1d.twitter.stream.v1.StreamDataR\bmessages\"\xfc\x01\n" +
+	"\bIncludes\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.twitter.stream.v1.UserR\x05users\x12.\n" +
+	"\x05media\x18\x02 \x03(\v2\x18.twitter.stream.v1.MediaR\x05media\x120\n" +
+	"\x06places\x18\x03 \x03(\v2\x18.twitter.stream.v1.PlaceR\x06places\x12-\n" +
+	"\x05polls\x18\x04 \x03(\v2\x17.twitter.stream.v1.PollR\x05polls\x120\n" +
+	"\x06tweets\x18\x05 \x03(\v2\x18.twitter.stream.v1.TweetR\x06tweets\"\xa5\x01\n" +
+	"\x05Error\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x16\n" +
+	"\x06detail\x18\x02 \x01(\tR\x06detail\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12#\n" +
+	"\rresource_type\x18\x04 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x05 \x01(\tR\n" +
+	"resourceId\x12\x14\n" +
+	"\x05value\x18\x06 \x01(\tR\x05value\"5\n" +
+	"\x0fReferencedTweet\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"G\n" +
+	"\vAttachments\x12\x1d\n" +
+	"\n" +
+	"media_keys\x18\x01 \x03(\tR\tmediaKeys\x12\x19\n" +
+	"\bpoll_ids\x18\x02 \x03(\tR\apollIds\"B\n" +
+	"\x03Geo\x12\x19\n" +
+	"\bplace_id\x18\x01 \x01(\tR\aplaceId\x12 \n" +
+	"\vcoordinates\x18\x02 \x03(\x01R\vcoordinates\"\xee\x01\n" +
+	"\bEntities\x128\n" +
+	"\bhashtags\x18\x01 \x03(\v2\x1c.twitter.stream.v1.TagEntityR\bhashtags\x128\n" +
+	"\bcashtags\x18\x02 \x03(\v2\x1c.twitter.stream.v1.TagEntityR\bcashtags\x12<\n" +
+	"\bmentions\x18\x03 \x03(\v2 .twitter.stream.v1.MentionEntityR\bmentions\x120\n" +
+	"\x04urls\x18\x04 \x03(\v2\x1c.twitter.stream.v1.URLEntityR\x04urls\"E\n" +
+	"\tTagEntity\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x03R\x03end\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\"c\n" +
+	"\rMentionEntity\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x03R\x03end\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x0e\n" +
+	"\x02id\x18\x04 \x01(\tR\x02id\"\x97\x02\n" +
+	"\tURLEntity\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x03R\x03end\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12!\n" +
+	"\fexpanded_url\x18\x04 \x01(\tR\vexpandedUrl\x12\x1f\n" +
+	"\vdisplay_url\x18\x05 \x01(\tR\n" +
+	"displayUrl\x12\x1f\n" +
+	"\vunwound_url\x18\x06 \x01(\tR\n" +
+	"unwoundUrl\x12\x16\n" +
+	"\x06status\x18\a \x01(\x03R\x06status\x12\x14\n" +
+	"\x05title\x18\b \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\t \x01(\tR\vdescription\x12\x1b\n" +
+	"\tmedia_key\x18\n" +
+	" \x01(\tR\bmediaKey\"\xa4\x01\n" +
+	"\fEditControls\x12'\n" +
+	"\x0fedits_remaining\x18\x01 \x01(\x03R\x0eeditsRemaining\x12(\n" +
+	"\x10is_edit_eligible\x18\x02 \x01(\bR\x0eisEditEligible\x12A\n" +
+	"\x0eeditable_until\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\reditableUntil\"X\n" +
+	"\tNoteTweet\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x127\n" +
+	"\bentities\x18\x02 \x01(\v2\x1b.twitter.stream.v1.EntitiesR\bentities\"\xbc\x02\n" +
+	"\x05Media\x12\x1b\n" +
+	"\tmedia_key\x18\x01 \x01(\tR\bmediaKey\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12*\n" +
+	"\x11preview_image_url\x18\x04 \x01(\tR\x0fpreviewImageUrl\x12\x1f\n" +
+	"\vduration_ms\x18\x05 \x01(\x03R\n" +
+	"durationMs\x12\x16\n" +
+	"\x06height\x18\x06 \x01(\x03R\x06height\x12\x14\n" +
+	"\x05width\x18\a \x01(\x03R\x05width\x12\x19\n" +
+	"\balt_text\x18\b \x01(\tR\aaltText\x12\x1d\n" +
+	"\n" +
+	"view_count\x18\t \x01(\x03R\tviewCount\x12;\n" +
+	"\bvariants\x18\n" +
+	" \x03(\v2\x1f.twitter.stream.v1.MediaVariantR\bvariants\"^\n" +
+	"\fMediaVariant\x12\x19\n" +
+	"\bbit_rate\x18\x01 \x01(\x03R\abitRate\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"\xe3\x01\n" +
+	"\x05Place\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tfull_name\x18\x02 \x01(\tR\bfullName\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\acountry\x18\x04 \x01(\tR\acountry\x12!\n" +
+	"\fcountry_code\x18\x05 \x01(\tR\vcountryCode\x12\x1d\n" +
+	"\n" +
+	"place_type\x18\x06 \x01(\tR\tplaceType\x12)\n" +
+	"\x10contained_within\x18\a \x03(\tR\x0fcontainedWithin\x12\x12\n" +
+	"\x04bbox\x18\b \x03(\x01R\x04bbox\"\xde\x01\n" +
+	"\x04Poll\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\aoptions\x18\x02 \x03(\v2\x1d.twitter.stream.v1.PollOptionR\aoptions\x12)\n" +
+	"\x10duration_minutes\x18\x03 \x01(\x03R\x0fdurationMinutes\x12=\n" +
+	"\fend_datetime\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vendDatetime\x12#\n" +
+	"\rvoting_status\x18\x05 \x01(\tR\fvotingStatus\"T\n" +
+	"\n" +
+	"PollOption\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x03R\bposition\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x14\n" +
+	"\x05votes\x18\x03 \x01(\x03R\x05votes2R\n" +
+	"\x05Relay\x12I\n" +
+	"\tSubscribe\x12 .twitter.stream.v1.FilterRequest\x1a\x18.twitter.stream.v1.Tweet0\x01B1Z/github.com/kalvin807/twitter-v2-stream/streampbb\x06proto3"
+
+var (
+	file_streampb_proto_rawDescOnce sync.Once
+	file_streampb_proto_rawDescData []byte
+)
+
+func file_streampb_proto_rawDescGZIP() []byte {
+	file_streampb_proto_rawDescOnce.Do(func() {
+		file_streampb_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_streampb_proto_rawDesc), len(file_streampb_proto_rawDesc)))
+	})
+	return file_streampb_proto_rawDescData
+}
+
+var file_streampb_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_streampb_proto_goTypes = []any{
+	(*FilterRequest)(nil),         // 0: twitter.stream.v1.FilterRequest
+	(*Tweet)(nil),                 // 1: twitter.stream.v1.Tweet
+	(*PublicMetrics)(nil),         // 2: twitter.stream.v1.PublicMetrics
+	(*User)(nil),                  // 3: twitter.stream.v1.User
+	(*UserPublicMetrics)(nil),     // 4: twitter.stream.v1.UserPublicMetrics
+	(*MatchingRule)(nil),          // 5: twitter.stream.v1.MatchingRule
+	(*StreamData)(nil),            // 6: twitter.stream.v1.StreamData
+	(*StreamDataBatch)(nil),       // 7: twitter.stream.v1.StreamDataBatch
+	(*Includes)(nil),              // 8: twitter.stream.v1.Includes
+	(*Error)(nil),                 // 9: twitter.stream.v1.Error
+	(*ReferencedTweet)(nil),       // 10: twitter.stream.v1.ReferencedTweet
+	(*Attachments)(nil),           // 11: twitter.stream.v1.Attachments
+	(*Geo)(nil),                   // 12: twitter.stream.v1.Geo
+	(*Entities)(nil),              // 13: twitter.stream.v1.Entities
+	(*TagEntity)(nil),             // 14: twitter.stream.v1.TagEntity
+	(*MentionEntity)(nil),         // 15: twitter.stream.v1.MentionEntity
+	(*URLEntity)(nil),             // 16: twitter.stream.v1.URLEntity
+	(*EditControls)(nil),          // 17: twitter.stream.v1.EditControls
+	(*NoteTweet)(nil),             // 18: twitter.stream.v1.NoteTweet
+	(*Media)(nil),                 // 19: twitter.stream.v1.Media
+	(*MediaVariant)(nil),          // 20: twitter.stream.v1.MediaVariant
+	(*Place)(nil),                 // 21: twitter.stream.v1.Place
+	(*Poll)(nil),                  // 22: twitter.stream.v1.Poll
+	(*PollOption)(nil),            // 23: twitter.stream.v1.PollOption
+	(*timestamppb.Timestamp)(nil), // 24: google.protobuf.Timestamp
+}
+var file_streampb_proto_depIdxs = []int32{
+	24, // 0: twitter.stream.v1.Tweet.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 1: twitter.stream.v1.Tweet.public_metrics:type_name -> twitter.stream.v1.PublicMetrics
+	3,  // 2: twitter.stream.v1.Tweet.author:type_name -> twitter.stream.v1.User
+	5,  // 3: twitter.stream.v1.Tweet.matching_rules:type_name -> twitter.stream.v1.MatchingRule
+	10, // 4: twitter.stream.v1.Tweet.referenced_tweets:type_name -> twitter.stream.v1.ReferencedTweet
+	11, // 5: twitter.stream.v1.Tweet.attachments:type_name -> twitter.stream.v1.Attachments
+	12, // 6: twitter.stream.v1.Tweet.geo:type_name -> twitter.stream.v1.Geo
+	13, // 7: twitter.stream.v1.Tweet.entities:type_name -> twitter.stream.v1.Entities
+	17, // 8: twitter.stream.v1.Tweet.edit_controls:type_name -> twitter.stream.v1.EditControls
+	18, // 9: twitter.stream.v1.Tweet.note_tweet:type_name -> twitter.stream.v1.NoteTweet
+	24, // 10: twitter.stream.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	4,  // 11: twitter.stream.v1.User.public_metrics:type_name -> twitter.stream.v1.UserPublicMetrics
+	1,  // 12: twitter.stream.v1.StreamData.data:type_name -> twitter.stream.v1.Tweet
+	8,  // 13: twitter.stream.v1.StreamData.includes:type_name -> twitter.stream.v1.Includes
+	5,  // 14: twitter.stream.v1.StreamData.matching_rules:type_name -> twitter.stream.v1.MatchingRule
+	9,  // 15: twitter.stream.v1.StreamData.errors:type_name -> twitter.stream.v1.Error
+	6,  // 16: twitter.stream.v1.StreamDataBatch.messages:type_name -> twitter.stream.v1.StreamData
+	3,  // 17: twitter.stream.v1.Includes.users:type_name -> twitter.stream.v1.User
+	19, // 18: twitter.stream.v1.Includes.media:type_name -> twitter.stream.v1.Media
+	21, // 19: twitter.stream.v1.Includes.places:type_name -> twitter.stream.v1.Place
+	22, // 20: twitter.stream.v1.Includes.polls:type_name -> twitter.stream.v1.Poll
+	1,  // 21: twitter.stream.v1.Includes.tweets:type_name -> twitter.stream.v1.Tweet
+	14, // 22: twitter.stream.v1.Entities.hashtags:type_name -> twitter.stream.v1.TagEntity
+	14, // 23: twitter.stream.v1.Entities.cashtags:type_name -> twitter.stream.v1.TagEntity
+	15, // 24: twitter.stream.v1.Entities.mentions:type_name -> twitter.stream.v1.MentionEntity
+	16, // 25: twitter.stream.v1.Entities.urls:type_name -> twitter.stream.v1.URLEntity
+	24, // 26: twitter.stream.v1.EditControls.editable_until:type_name -> google.protobuf.Timestamp
+	13, // 27: twitter.stream.v1.NoteTweet.entities:type_name -> twitter.stream.v1.Entities
+	20, // 28: twitter.stream.v1.Media.variants:type_name -> twitter.stream.v1.MediaVariant
+	23, // 29: twitter.stream.v1.Poll.options:type_name -> twitter.stream.v1.PollOption
+	24, // 30: twitter.stream.v1.Poll.end_datetime:type_name -> google.protobuf.Timestamp
+	0,  // 31: twitter.stream.v1.Relay.Subscribe:input_type -> twitter.stream.v1.FilterRequest
+	1,  // 32: twitter.stream.v1.Relay.Subscribe:output_type -> twitter.stream.v1.Tweet
+	32, // [32:33] is the sub-list for method output_type
+	31, // [31:32] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_streampb_proto_init() }
+func file_streampb_proto_init() {
+	if File_streampb_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_streampb_proto_rawDesc), len(file_streampb_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_streampb_proto_goTypes,
+		DependencyIndexes: file_streampb_proto_depIdxs,
+		MessageInfos:      file_streampb_proto_msgTypes,
+	}.Build()
+	File_streampb_proto = out.File
+	file_streampb_proto_goTypes = nil
+	file_streampb_proto_depIdxs = nil
+}
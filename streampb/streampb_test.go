@@ -0,0 +1,176 @@
+package streampb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"google.golang.org/protobuf/proto"
+)
+
+// roundTrips are messages using only fields streampb.proto defines, which
+// survive encoding and decoding unchanged.
+var roundTrips = []struct {
+	name string
+	json string
+}{
+	{"bare", `{"data":{"id":"1","text":"hello","edit_history_tweet_ids":["1"]},"matching_rules":[{"id":"10","tag":"greetings"}]}`},
+	{"full", `{
+		"data": {
+			"id": "2", "text": "@bob look https://t.co/x #go $TWTR", "created_at": "2023-05-01T12:00:00.5Z",
+			"author_id": "100", "conversation_id": "2", "in_reply_to_user_id": "101", "lang": "en",
+			"source": "Twitter Web App", "reply_settings": "everyone", "possibly_sensitive": true,
+			"entities": {
+				"hashtags": [{"start": 28, "end": 31, "tag": "go"}],
+				"cashtags": [{"start": 32, "end": 37, "tag": "TWTR"}],
+				"mentions": [{"start": 0, "end": 4, "username": "bob", "id": "101"}],
+				"urls": [{"start": 10, "end": 27, "url": "https://t.co/x", "expanded_url": "https://example.com", "display_url": "example.com", "status": 200}]
+			},
+			"public_metrics": {"retweet_count": 1, "reply_count": 2, "like_count": 3, "quote_count": 4, "bookmark_count": 5, "impression_count": 6},
+			"referenced_tweets": [{"type": "replied_to", "id": "1"}],
+			"attachments": {"media_keys": ["3_1"], "poll_ids": ["p1"]},
+			"geo": {"place_id": "pl1", "coordinates": {"type": "Point", "coordinates": [-122.4, 37.8]}},
+			"edit_history_tweet_ids": ["2"],
+			"edit_controls": {"edits_remaining": 5, "is_edit_eligible": true, "editable_until": "2023-05-01T12:30:00Z"},
+			"note_tweet": {"text": "a longer text", "entities": {"hashtags": [{"start": 0, "end": 3, "tag": "go"}]}}
+		},
+		"includes": {
+			"users": [{"id": "100", "name": "Alice", "username": "alice", "created_at": "2010-01-01T00:00:00Z", "protected": true, "public_metrics": {"followers_count": 1, "following_count": 2, "tweet_count": 3, "listed_count": 4}}],
+			"media": [{"media_key": "3_1", "type": "video", "duration_ms": 1500, "width": 10, "height": 20, "public_metrics": {"view_count": 7}, "variants": [{"bit_rate": 832000, "content_type": "video/mp4", "url": "https://video.twimg.com/x.mp4"}]}],
+			"places": [{"id": "pl1", "full_name": "San Francisco, CA", "contained_within": ["us"], "geo": {"type": "Feature", "bbox": [-122.5, 37.7, -122.3, 37.9]}}],
+			"polls": [{"id": "p1", "options": [{"position": 1, "label": "yes", "votes": 3}], "duration_minutes": 60, "end_datetime": "2023-05-01T13:00:00Z", "voting_status": "open"}],
+			"tweets": [{"id": "1", "text": "hello", "edit_history_tweet_ids": ["1"]}]
+		},
+		"matching_rules": [{"id": "10", "tag": "greetings"}, {"id": "11"}]
+	}`},
+	{"error", `{"errors":[{"title":"operational-disconnect","detail":"This stream has been disconnected for operational reasons.","type":"https://api.twitter.com/2/problems/operational-disconnect"}]}`},
+}
+
+func decodeJSON(t *testing.T, data string) *stream.StreamData {
+	t.Helper()
+	msg := &stream.StreamData{}
+	if err := json.Unmarshal([]byte(data), msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestStreamDataRoundTrip(t *testing.T) {
+	for _, tt := range roundTrips {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := decodeJSON(t, tt.json)
+			msg.Recovered = true
+			data := MarshalStreamData(msg)
+			if data == nil {
+				t.Fatal("MarshalStreamData returned nil")
+			}
+			got, err := UnmarshalStreamData(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := json.Marshal(msg)
+			gotJSON, _ := json.Marshal(got)
+			if string(gotJSON) != string(want) {
+				t.Errorf("round trip\n got %s\nwant %s", gotJSON, want)
+			}
+		})
+	}
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	var records [][]byte
+	var want []*stream.StreamData
+	for _, tt := range roundTrips {
+		msg := decodeJSON(t, tt.json)
+		want = append(want, msg)
+		records = append(records, MarshalStreamData(msg))
+	}
+	got, err := UnmarshalBatch(MarshalBatch(records))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("decoded %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		gotJSON, _ := json.Marshal(got[i])
+		wantJSON, _ := json.Marshal(want[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("message %d\n got %s\nwant %s", i, gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestMarshalTweet(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantNil    bool
+		wantAuthor string
+		wantRules  []string
+	}{
+		{"no tweet", roundTrips[2].json, true, "", nil},
+		{"without author", roundTrips[0].json, false, "", []string{"greetings"}},
+		{"with author", roundTrips[1].json, false, "alice", []string{"greetings", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := MarshalTweet(decodeJSON(t, tt.json))
+			if tt.wantNil {
+				if data != nil {
+					t.Fatalf("MarshalTweet = %x, want nil", data)
+				}
+				return
+			}
+			var tweet Tweet
+			if err := proto.Unmarshal(data, &tweet); err != nil {
+				t.Fatal(err)
+			}
+			if got := tweet.GetAuthor().GetUsername(); got != tt.wantAuthor {
+				t.Errorf("author %q, want %q", got, tt.wantAuthor)
+			}
+			var tags []string
+			for _, rule := range tweet.GetMatchingRules() {
+				tags = append(tags, rule.GetTag())
+			}
+			if !reflect.DeepEqual(tags, tt.wantRules) {
+				t.Errorf("rule tags %q, want %q", tags, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestUnmarshalFilterRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []string
+		wantErr bool
+	}{
+		{"empty", nil, nil, false},
+		{"tags", mustMarshal(t, &FilterRequest{Tags: []string{"news", "sports"}}), []string{"news", "sports"}, false},
+		{"truncated", []byte{0x0a, 0x05, 'n'}, nil, true},
+		{"field of another wire type skipped", []byte{0x08, 0x01}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalFilterRequest(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tags %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
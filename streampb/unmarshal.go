@@ -0,0 +1,242 @@
+package streampb
+
+import (
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UnmarshalStreamData decodes a StreamData message, as encoded by
+// MarshalStreamData, skipping unknown fields.
+func UnmarshalStreamData(data []byte) (*stream.StreamData, error) {
+	var m StreamData
+	if err := proto.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m.StreamData(), nil
+}
+
+// UnmarshalBatch decodes a StreamDataBatch message.
+func UnmarshalBatch(data []byte) ([]*stream.StreamData, error) {
+	var batch StreamDataBatch
+	if err := proto.Unmarshal(data, &batch); err != nil {
+		return nil, err
+	}
+	messages := make([]*stream.StreamData, len(batch.Messages))
+	for i, m := range batch.Messages {
+		messages[i] = m.StreamData()
+	}
+	return messages, nil
+}
+
+// StreamData returns the stream message of m.
+func (m *StreamData) StreamData() *stream.StreamData {
+	msg := &stream.StreamData{Recovered: m.GetRecovered()}
+	if m.GetData() != nil {
+		msg.Tweet = m.Data.tweet()
+	}
+	if m.GetIncludes() != nil {
+		msg.Includes = m.Includes.includes()
+	}
+	for _, rule := range m.GetMatchingRules() {
+		msg.MatchingRules = append(msg.MatchingRules, &stream.MatchingRule{Id: rule.Id, Tag: rule.Tag})
+	}
+	for _, e := range m.GetErrors() {
+		msg.Errors = append(msg.Errors, &stream.APIErrorDetail{
+			Title:        e.Title,
+			Detail:       e.Detail,
+			Type:         e.Type,
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceId,
+			Value:        e.Value,
+		})
+	}
+	return msg
+}
+
+func (m *Tweet) tweet() *stream.Tweet {
+	t := &stream.Tweet{
+		ID:                  m.Id,
+		Text:                m.Text,
+		CreatedAt:           timeOf(m.CreatedAt),
+		AuthorID:            m.AuthorId,
+		ConversationID:      m.ConversationId,
+		Lang:                m.Lang,
+		PossiblySensitive:   m.PossiblySensitive,
+		EditHistoryTweetIDs: m.EditHistoryTweetIds,
+		InReplyToUserID:     m.InReplyToUserId,
+		Source:              m.Source,
+		ReplySettings:       m.ReplySettings,
+	}
+	if pm := m.PublicMetrics; pm != nil {
+		t.PublicMetrics = &stream.PublicMetrics{
+			RetweetCount:    int(pm.RetweetCount),
+			ReplyCount:      int(pm.ReplyCount),
+			LikeCount:       int(pm.LikeCount),
+			QuoteCount:      int(pm.QuoteCount),
+			BookmarkCount:   int(pm.BookmarkCount),
+			ImpressionCount: int(pm.ImpressionCount),
+		}
+	}
+	for _, ref := range m.ReferencedTweets {
+		t.ReferencedTweets = append(t.ReferencedTweets, &stream.ReferencedTweet{Type: ref.Type, ID: ref.Id})
+	}
+	if a := m.Attachments; a != nil {
+		t.Attachments = &stream.Attachments{MediaKeys: a.MediaKeys, PollIDs: a.PollIds}
+	}
+	if g := m.Geo; g != nil {
+		t.Geo = &stream.Geo{PlaceID: g.PlaceId}
+		if len(g.Coordinates) > 0 {
+			t.Geo.Coordinates = &stream.Coordinates{Type: "Point", Coordinates: g.Coordinates}
+		}
+	}
+	if m.Entities != nil {
+		t.Entities = m.Entities.entities()
+	}
+	if c := m.EditControls; c != nil {
+		t.EditControls = &stream.EditControls{
+			EditsRemaining: int(c.EditsRemaining),
+			IsEditEligible: c.IsEditEligible,
+			EditableUntil:  timeOf(c.EditableUntil),
+		}
+	}
+	if n := m.NoteTweet; n != nil {
+		t.NoteTweet = &stream.NoteTweet{Text: n.Text}
+		if n.Entities != nil {
+			t.NoteTweet.Entities = n.Entities.entities()
+		}
+	}
+	return t
+}
+
+func (m *Entities) entities() *stream.Entities {
+	e := &stream.Entities{}
+	for _, tag := range m.Hashtags {
+		e.Hashtags = append(e.Hashtags, tag.tagEntity())
+	}
+	for _, tag := range m.Cashtags {
+		e.Cashtags = append(e.Cashtags, tag.tagEntity())
+	}
+	for _, mention := range m.Mentions {
+		e.Mentions = append(e.Mentions, &stream.MentionEntity{
+			Start:    int(mention.Start),
+			End:      int(mention.End),
+			Username: mention.Username,
+			ID:       mention.Id,
+		})
+	}
+	for _, u := range m.Urls {
+		e.URLs = append(e.URLs, &stream.URLEntity{
+			Start:       int(u.Start),
+			End:         int(u.End),
+			URL:         u.Url,
+			ExpandedURL: u.ExpandedUrl,
+			DisplayURL:  u.DisplayUrl,
+			UnwoundURL:  u.UnwoundUrl,
+			Status:      int(u.Status),
+			Title:       u.Title,
+			Description: u.Description,
+			MediaKey:    u.MediaKey,
+		})
+	}
+	return e
+}
+
+func (m *TagEntity) tagEntity() *stream.TagEntity {
+	return &stream.TagEntity{Start: int(m.Start), End: int(m.End), Tag: m.Tag}
+}
+
+func (m *User) user() *stream.User {
+	u := &stream.User{
+		ID:              m.Id,
+		Name:            m.Name,
+		Username:        m.Username,
+		CreatedAt:       timeOf(m.CreatedAt),
+		Description:     m.Description,
+		Location:        m.Location,
+		PinnedTweetID:   m.PinnedTweetId,
+		ProfileImageURL: m.ProfileImageUrl,
+		Protected:       m.Protected,
+		URL:             m.Url,
+		Verified:        m.Verified,
+		VerifiedType:    m.VerifiedType,
+	}
+	if pm := m.PublicMetrics; pm != nil {
+		u.PublicMetrics = &stream.UserPublicMetrics{
+			FollowersCount: int(pm.FollowersCount),
+			FollowingCount: int(pm.FollowingCount),
+			TweetCount:     int(pm.TweetCount),
+			ListedCount:    int(pm.ListedCount),
+			LikeCount:      int(pm.LikeCount),
+		}
+	}
+	return u
+}
+
+func (m *Includes) includes() *stream.Includes {
+	inc := &stream.Includes{}
+	for _, u := range m.Users {
+		inc.Users = append(inc.Users, u.user())
+	}
+	for _, mm := range m.Media {
+		media := &stream.Media{
+			MediaKey:        mm.MediaKey,
+			Type:            mm.Type,
+			URL:             mm.Url,
+			PreviewImageURL: mm.PreviewImageUrl,
+			DurationMS:      int(mm.DurationMs),
+			Height:          int(mm.Height),
+			Width:           int(mm.Width),
+			AltText:         mm.AltText,
+		}
+		if mm.ViewCount != 0 {
+			media.PublicMetrics = &stream.MediaPublicMetrics{ViewCount: int(mm.ViewCount)}
+		}
+		for _, v := range mm.Variants {
+			media.Variants = append(media.Variants, &stream.MediaVariant{BitRate: int(v.BitRate), ContentType: v.ContentType, URL: v.Url})
+		}
+		inc.Media = append(inc.Media, media)
+	}
+	for _, mp := range m.Places {
+		p := &stream.Place{
+			ID:              mp.Id,
+			FullName:        mp.FullName,
+			Name:            mp.Name,
+			Country:         mp.Country,
+			CountryCode:     mp.CountryCode,
+			PlaceType:       mp.PlaceType,
+			ContainedWithin: mp.ContainedWithin,
+		}
+		if len(mp.Bbox) > 0 {
+			p.Geo = &stream.PlaceGeo{Type: "Feature", BBox: mp.Bbox}
+		}
+		inc.Places = append(inc.Places, p)
+	}
+	for _, mp := range m.Polls {
+		p := &stream.Poll{
+			ID:              mp.Id,
+			DurationMinutes: int(mp.DurationMinutes),
+			EndDatetime:     timeOf(mp.EndDatetime),
+			VotingStatus:    mp.VotingStatus,
+		}
+		for _, o := range mp.Options {
+			p.Options = append(p.Options, &stream.PollOption{Position: int(o.Position), Label: o.Label, Votes: int(o.Votes)})
+		}
+		inc.Polls = append(inc.Polls, p)
+	}
+	for _, t := range m.Tweets {
+		inc.Tweets = append(inc.Tweets, t.tweet())
+	}
+	return inc
+}
+
+// timeOf returns the time of a google.protobuf.Timestamp, or the zero time
+// if it is unset.
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,35 @@
+package streamtest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// LoadFixture reads the events of an NDJSON fixture file, such as one
+// archived by sink.File, one per line, with blank lines as keep-alives.
+func LoadFixture(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFixture(f)
+}
+
+// ReadFixture reads the events of an NDJSON fixture, see LoadFixture.
+func ReadFixture(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			events = append(events, KeepAlive())
+			continue
+		}
+		events = append(events, Event{Line: append([]byte(nil), line...)})
+	}
+	return events, scanner.Err()
+}
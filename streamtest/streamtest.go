@@ -0,0 +1,354 @@
+// Package streamtest provides a fake of the Twitter API for tests of stream
+// consumers: a Server answers the connect attempts of streams by a script
+// of Connections, each sending Events such as Tweets, keep-alives and
+// in-stream errors on a schedule, and keeps the rules of the filtered
+// stream in memory, e.g.
+//
+//	srv := streamtest.NewServer(
+//		streamtest.Disconnect(streamtest.Tweet("1", "hello", "news")),
+//		streamtest.RateLimit(time.Second),
+//		streamtest.Connected(streamtest.KeepAlive(), streamtest.Tweet("2", "again", "news")),
+//	)
+//	defer srv.Close()
+//	s, err := srv.Service().Connect(ctx, nil)
+package streamtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Token is the bearer token a Server accepts by default.
+const Token = "streamtest-token"
+
+// Event is a line sent on a stream connection after Delay. An empty Line is
+// a keep-alive.
+type Event struct {
+	Delay time.Duration
+	Line  []byte
+}
+
+// After returns the event sent after d.
+func (e Event) After(d time.Duration) Event {
+	e.Delay = d
+	return e
+}
+
+// Tweet returns the event of a Tweet matching a rule for each tag, or a
+// single untagged rule without tags.
+func Tweet(id, text string, tags ...string) Event {
+	msg := &stream.StreamData{Tweet: &stream.Tweet{ID: id, Text: text}}
+	for i, tag := range tags {
+		msg.MatchingRules = append(msg.MatchingRules, &stream.MatchingRule{Id: strconv.Itoa(i + 1), Tag: tag})
+	}
+	if len(tags) == 0 {
+		msg.MatchingRules = []*stream.MatchingRule{{Id: "1"}}
+	}
+	return Message(msg)
+}
+
+// Message returns the event of a message, encoded as JSON.
+func Message(msg *stream.StreamData) Event {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		panic(fmt.Sprintf("streamtest: encode message: %v", err))
+	}
+	return Event{Line: line}
+}
+
+// KeepAlive returns the event of a keep-alive.
+func KeepAlive() Event {
+	return Event{}
+}
+
+// StreamError returns the event of an in-stream error object.
+func StreamError(title, detail string) Event {
+	return Message(&stream.StreamData{Errors: []*stream.APIErrorDetail{{Title: title, Detail: detail}}})
+}
+
+// OperationalDisconnect returns the event of the notice Twitter sends
+// before disconnecting a stream for operational reasons. The stream
+// reconnects on receiving it, even if the connection is held open.
+func OperationalDisconnect() Event {
+	return Message(&stream.StreamData{Errors: []*stream.APIErrorDetail{{
+		Title:          "operational-disconnect",
+		DisconnectType: "UpstreamOperationalDisconnect",
+		Detail:         "This stream has been disconnected upstream for operational reasons.",
+		Type:           "https://api.twitter.com/2/problems/operational-disconnect",
+	}}})
+}
+
+// Raw returns the event of a line sent as is, e.g. malformed JSON.
+func Raw(line string) Event {
+	return Event{Line: []byte(line)}
+}
+
+// Connection is the scripted answer to a connect attempt: the Status, 200
+// OK by default, with the Header, then for 200 OK the Events, each Interval
+// apart in addition to their Delay. The connection is then closed,
+// simulating a disconnect, unless Hold keeps it open until the client
+// disconnects. Other statuses are answered with an API error body.
+type Connection struct {
+	Status   int
+	Header   map[string]string
+	Events   []Event
+	Interval time.Duration
+	Hold     bool
+}
+
+// Disconnect returns a connection sending the events, then disconnecting.
+func Disconnect(events ...Event) Connection {
+	return Connection{Status: http.StatusOK, Events: events}
+}
+
+// Connected returns a connection sending the events, then staying open.
+func Connected(events ...Event) Connection {
+	return Connection{Status: http.StatusOK, Events: events, Hold: true}
+}
+
+// Status returns a connection answered with the status code.
+func Status(code int) Connection {
+	return Connection{Status: code}
+}
+
+// RateLimit returns a connection answered with 429 Too Many Requests and a
+// Retry-After of wait, rounded to seconds.
+func RateLimit(wait time.Duration) Connection {
+	return Connection{
+		Status: http.StatusTooManyRequests,
+		Header: map[string]string{"Retry-After": strconv.Itoa(int(wait.Round(time.Second).Seconds()))},
+	}
+}
+
+// Server is a fake Twitter API serving the filtered and sample stream
+// endpoints by its script, and the rules endpoint. Connect attempts beyond
+// the script are held open without events.
+type Server struct {
+	// URL is the base URL of the server, see stream.WithBaseURL.
+	URL string
+	// Token is the bearer token the server accepts, Token by default.
+	// Requests with another are answered with 401 Unauthorized.
+	Token string
+
+	server   *httptest.Server
+	closed   chan struct{}
+	mu       sync.Mutex
+	script   []Connection
+	requests []*url.URL
+	rules    []*stream.Rule
+	ruleID   int
+}
+
+// NewServer starts a Server answering connect attempts by the script.
+func NewServer(script ...Connection) *Server {
+	s := &Server{
+		Token:  Token,
+		script: script,
+		closed: make(chan struct{}),
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close closes held connections and shuts the server down.
+func (s *Server) Close() {
+	close(s.closed)
+	s.server.Close()
+}
+
+// Service returns a StreamService connecting to the server with its token.
+func (s *Server) Service(opts ...stream.Option) *stream.StreamService {
+	opts = append([]stream.Option{stream.WithBaseURL(s.URL)}, opts...)
+	return stream.NewStreamService(s.server.Client(), s.Token, opts...)
+}
+
+// Enqueue appends connections to the script.
+func (s *Server) Enqueue(script ...Connection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, script...)
+}
+
+// Connects returns the number of connect attempts served.
+func (s *Server) Connects() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// Requests returns the URLs of the connect attempts served, e.g. to check
+// their query parameters.
+func (s *Server) Requests() []*url.URL {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*url.URL(nil), s.requests...)
+}
+
+// Rules returns the rules of the filtered stream.
+func (s *Server) Rules() []*stream.Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*stream.Rule(nil), s.rules...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/2/tweets/search/stream", "/2/tweets/sample/stream":
+		s.serveStream(w, r)
+	case "/2/tweets/search/stream/rules":
+		s.serveRules(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	conn := Connection{Status: http.StatusOK, Hold: true}
+	if attempt := len(s.requests); attempt < len(s.script) {
+		conn = s.script[attempt]
+	}
+	s.requests = append(s.requests, r.URL)
+	s.mu.Unlock()
+
+	for key, value := range conn.Header {
+		w.Header().Set(key, value)
+	}
+	if conn.Status == 0 {
+		conn.Status = http.StatusOK
+	}
+	if conn.Status != http.StatusOK {
+		writeError(w, conn.Status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	for _, event := range conn.Events {
+		if !s.sleep(r, conn.Interval+event.Delay) {
+			return
+		}
+		w.Write(event.Line)
+		w.Write([]byte("\r\n"))
+		w.(http.Flusher).Flush()
+	}
+	if conn.Hold {
+		select {
+		case <-r.Context().Done():
+		case <-s.closed:
+		}
+	}
+}
+
+// sleep waits for d, and reports whether the client is still connected.
+func (s *Server) sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	case <-s.closed:
+		return false
+	}
+}
+
+func (s *Server) serveRules(w http.ResponseWriter, r *http.Request) {
+	sent := time.Now().UTC().Format(time.RFC3339)
+	if r.Method == http.MethodGet {
+		rules := s.Rules()
+		writeJSON(w, http.StatusOK, &stream.RulesResponse{
+			Data: rules,
+			Meta: &stream.RulesMeta{Sent: sent, ResultCount: len(rules)},
+		})
+		return
+	}
+	var body struct {
+		Add    []*stream.Rule `json:"add"`
+		Delete *struct {
+			IDs []string `json:"ids"`
+		} `json:"delete"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := &stream.RulesSummary{}
+	resp := &stream.RulesResponse{Meta: &stream.RulesMeta{Sent: sent, Summary: summary}}
+	if body.Delete != nil {
+		for _, id := range body.Delete.IDs {
+			i := s.ruleIndex(func(rule *stream.Rule) bool { return rule.ID == id })
+			if i < 0 {
+				summary.NotDeleted++
+				resp.Errors = append(resp.Errors, &stream.RuleError{ID: id, Title: "Not Found Error", Type: "https://api.twitter.com/2/problems/resource-not-found"})
+				continue
+			}
+			summary.Deleted++
+			if !dryRun {
+				s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	for _, add := range body.Add {
+		if s.ruleIndex(func(rule *stream.Rule) bool { return rule.Value == add.Value }) >= 0 {
+			summary.NotCreated++
+			summary.Invalid++
+			resp.Errors = append(resp.Errors, &stream.RuleError{Value: add.Value, Title: "DuplicateRule", Type: "https://api.twitter.com/2/problems/duplicate-rules"})
+			continue
+		}
+		s.ruleID++
+		rule := &stream.Rule{ID: strconv.Itoa(s.ruleID), Value: add.Value, Tag: add.Tag}
+		summary.Created++
+		summary.Valid++
+		resp.Data = append(resp.Data, rule)
+		if !dryRun {
+			s.rules = append(s.rules, rule)
+		}
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) ruleIndex(match func(*stream.Rule) bool) int {
+	for i, rule := range s.rules {
+		if match(rule) {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the status and an API error body.
+func writeError(w http.ResponseWriter, status int) {
+	writeJSON(w, status, &stream.APIError{
+		Title:  http.StatusText(status),
+		Detail: "streamtest",
+		Type:   "about:blank",
+	})
+}
@@ -0,0 +1,272 @@
+// Package tenant isolates the internal customers sharing one deployment.
+// Rule tags map to tenants; each tenant has its own fan-out token, sinks,
+// quota and statistics, and only sees the matching rules of its own tags.
+package tenant
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/broadcast"
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// Tenant is an internal customer of the deployment.
+type Tenant struct {
+	Name string
+	// Tags are the rule tags whose matches are delivered to the tenant.
+	Tags []string
+	// Token authenticates the tenant at fan-out endpoints.
+	Token string
+	// Sinks receive the messages of the tenant.
+	Sinks []sink.Sink
+	// Quota limits the messages delivered per QuotaWindow, which defaults to
+	// a minute. Messages over the quota are dropped. Zero means no limit.
+	Quota       int
+	QuotaWindow time.Duration
+
+	stats       counters
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+}
+
+// Stats are the delivery counts of a tenant.
+type Stats struct {
+	Delivered uint64
+	Throttled uint64
+	Failed    uint64
+}
+
+type counters struct {
+	delivered uint64
+	throttled uint64
+	failed    uint64
+}
+
+// Stats returns the delivery counts of the tenant.
+func (t *Tenant) Stats() Stats {
+	return Stats{
+		Delivered: atomic.LoadUint64(&t.stats.delivered),
+		Throttled: atomic.LoadUint64(&t.stats.throttled),
+		Failed:    atomic.LoadUint64(&t.stats.failed),
+	}
+}
+
+// allow reports whether a message at now is within the quota.
+func (t *Tenant) allow(now time.Time) bool {
+	if t.Quota <= 0 {
+		return true
+	}
+	window := t.QuotaWindow
+	if window <= 0 {
+		window = time.Minute
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.windowStart) >= window {
+		t.windowStart = now
+		t.windowCount = 0
+	}
+	if t.windowCount >= t.Quota {
+		return false
+	}
+	t.windowCount++
+	return true
+}
+
+// Registry routes stream messages to tenants by the tags of their matching
+// rules. It is a sink.Sink, so it can be used wherever a single sink is.
+type Registry struct {
+	tenants []*Tenant
+	byTag   map[string]*Tenant
+	byName  map[string]*Tenant
+}
+
+// NewRegistry returns a Registry of the tenants. A tag or token must not be
+// shared by two tenants.
+func NewRegistry(tenants ...*Tenant) (*Registry, error) {
+	r := &Registry{
+		tenants: tenants,
+		byTag:   map[string]*Tenant{},
+		byName:  map[string]*Tenant{},
+	}
+	tokens := map[string]string{}
+	for _, t := range tenants {
+		if _, ok := r.byName[t.Name]; ok {
+			return nil, fmt.Errorf("tenant: duplicate tenant %q", t.Name)
+		}
+		r.byName[t.Name] = t
+		for _, tag := range t.Tags {
+			if other, ok := r.byTag[tag]; ok {
+				return nil, fmt.Errorf("tenant: tag %q of %q is already assigned to %q", tag, t.Name, other.Name)
+			}
+			r.byTag[tag] = t
+		}
+		if t.Token != "" {
+			if other, ok := tokens[t.Token]; ok {
+				return nil, fmt.Errorf("tenant: token of %q is already assigned to %q", t.Name, other)
+			}
+			tokens[t.Token] = t.Name
+		}
+	}
+	return r, nil
+}
+
+// Tenant returns the tenant with the name, or nil.
+func (r *Registry) Tenant(name string) *Tenant {
+	return r.byName[name]
+}
+
+// ForTag returns the tenant of the rule tag, or nil.
+func (r *Registry) ForTag(tag string) *Tenant {
+	return r.byTag[tag]
+}
+
+// Authenticate returns the tenant of the fan-out token, or nil.
+func (r *Registry) Authenticate(token string) *Tenant {
+	if token == "" {
+		return nil
+	}
+	for _, t := range r.tenants {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return t
+		}
+	}
+	return nil
+}
+
+// Authorizer returns the broadcast.Authorizer authorizing the fan-out
+// clients of each tenant by its token, sent as broadcast.BearerToken, to the
+// tags of the tenant:
+//
+//	hub.Authorize(registry.Authorizer())
+func (r *Registry) Authorizer() broadcast.Authorizer {
+	return func(req *http.Request) ([]string, bool) {
+		t := r.Authenticate(broadcast.BearerToken(req))
+		if t == nil {
+			return nil, false
+		}
+		return t.Tags, true
+	}
+}
+
+// Tenants returns the tenants of the registry.
+func (r *Registry) Tenants() []*Tenant {
+	return r.tenants
+}
+
+// Split returns the views of msg for each tenant with a matching rule. A
+// view is a deep copy of msg which only lists the matching rules of the
+// tenant, also in its raw payload, so tenants neither see nor modify each
+// other's data.
+func (r *Registry) Split(msg *stream.StreamData) map[*Tenant]*stream.StreamData {
+	views := map[*Tenant]*stream.StreamData{}
+	for _, rule := range msg.MatchingRules {
+		if t := r.byTag[rule.Tag]; t != nil && views[t] == nil {
+			views[t] = msg.Clone()
+		}
+	}
+	for t, view := range views {
+		kept := view.MatchingRules[:0]
+		for _, rule := range view.MatchingRules {
+			if r.byTag[rule.Tag] == t {
+				kept = append(kept, rule)
+			}
+		}
+		view.MatchingRules = kept
+		if view.Raw != nil {
+			view.Raw = stripRules(view.Raw, view.MatchingRules)
+		}
+	}
+	return views
+}
+
+// stripRules returns the raw payload of a message without the matching
+// rules other than rules, or nil if it cannot be decoded.
+func stripRules(raw json.RawMessage, rules []*stream.MatchingRule) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	if fields["matching_rules"] == nil {
+		return raw
+	}
+	var matching []json.RawMessage
+	if err := json.Unmarshal(fields["matching_rules"], &matching); err != nil {
+		return nil
+	}
+	kept := matching[:0]
+	for _, m := range matching {
+		var rule struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(m, &rule); err != nil {
+			return nil
+		}
+		for _, r := range rules {
+			if r.Id == rule.ID {
+				kept = append(kept, m)
+				break
+			}
+		}
+	}
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return nil
+	}
+	fields["matching_rules"] = data
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return stripped
+}
+
+// Write delivers msg to the sinks of each tenant with a matching rule. A
+// failing sink does not keep other tenants from receiving msg; the first
+// error is returned.
+func (r *Registry) Write(msg *stream.StreamData) error {
+	var firstErr error
+	now := time.Now()
+	for t, view := range r.Split(msg) {
+		if !t.allow(now) {
+			atomic.AddUint64(&t.stats.throttled, 1)
+			continue
+		}
+		failed := false
+		for _, s := range t.Sinks {
+			if err := s.Write(view); err != nil {
+				failed = true
+				if firstErr == nil {
+					firstErr = fmt.Errorf("tenant %s: %w", t.Name, err)
+				}
+			}
+		}
+		if failed {
+			atomic.AddUint64(&t.stats.failed, 1)
+		} else {
+			atomic.AddUint64(&t.stats.delivered, 1)
+		}
+	}
+	return firstErr
+}
+
+// Close closes the sinks of all tenants and returns the first error.
+func (r *Registry) Close() error {
+	var firstErr error
+	for _, t := range r.tenants {
+		for _, s := range t.Sinks {
+			if err := s.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("tenant %s: %w", t.Name, err)
+			}
+		}
+	}
+	return firstErr
+}
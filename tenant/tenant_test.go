@@ -0,0 +1,177 @@
+package tenant_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/sink"
+	"github.com/kalvin807/twitter-v2-stream/stream"
+	"github.com/kalvin807/twitter-v2-stream/tenant"
+)
+
+// recorder is a sink keeping the messages written to it, failing with err
+// if set.
+type recorder struct {
+	mu       sync.Mutex
+	messages []*stream.StreamData
+	err      error
+}
+
+func (r *recorder) Write(msg *stream.StreamData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *recorder) Close() error { return nil }
+
+// tweet returns a message of the Tweet matching rules with the tags, whose
+// IDs are their positions.
+func tweet(id string, tags ...string) *stream.StreamData {
+	msg := &stream.StreamData{Tweet: &stream.Tweet{ID: id}}
+	for i, tag := range tags {
+		msg.MatchingRules = append(msg.MatchingRules, &stream.MatchingRule{Id: string(rune('1' + i)), Tag: tag})
+	}
+	return msg
+}
+
+func TestQuota(t *testing.T) {
+	tests := []struct {
+		name   string
+		quota  int
+		window time.Duration
+		// writes are the numbers of messages written in consecutive
+		// windows
+		writes        []int
+		wantDelivered uint64
+		wantThrottled uint64
+	}{
+		{name: "unlimited", writes: []int{5}, wantDelivered: 5},
+		{name: "within quota", quota: 3, writes: []int{3}, wantDelivered: 3},
+		{name: "over quota", quota: 2, writes: []int{5}, wantDelivered: 2, wantThrottled: 3},
+		{name: "next window", quota: 2, window: 50 * time.Millisecond, writes: []int{3, 3}, wantDelivered: 4, wantThrottled: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, other := &recorder{}, &recorder{}
+			news := &tenant.Tenant{Name: "news", Tags: []string{"news"}, Sinks: []sink.Sink{out}, Quota: tt.quota, QuotaWindow: tt.window}
+			sports := &tenant.Tenant{Name: "sports", Tags: []string{"sports"}, Sinks: []sink.Sink{other}, Quota: 1}
+			registry, err := tenant.NewRegistry(news, sports)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, n := range tt.writes {
+				if i > 0 {
+					time.Sleep(tt.window)
+				}
+				for j := 0; j < n; j++ {
+					if err := registry.Write(tweet("1", "news")); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if got := news.Stats(); got.Delivered != tt.wantDelivered || got.Throttled != tt.wantThrottled {
+				t.Errorf("stats %+v, want %d delivered and %d throttled", got, tt.wantDelivered, tt.wantThrottled)
+			}
+			if n := uint64(len(out.messages)); n != tt.wantDelivered {
+				t.Errorf("sink received %d messages, want %d", n, tt.wantDelivered)
+			}
+			// the quota of one tenant leaves the others alone
+			if err := registry.Write(tweet("2", "sports")); err != nil {
+				t.Fatal(err)
+			}
+			if got := sports.Stats(); got.Delivered != 1 || got.Throttled != 0 {
+				t.Errorf("other tenant stats %+v, want 1 delivered", got)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	news := &tenant.Tenant{Name: "news", Tags: []string{"news", "politics"}}
+	sports := &tenant.Tenant{Name: "sports", Tags: []string{"sports"}}
+	registry, err := tenant.NewRegistry(news, sports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := tweet("1", "news", "sports", "politics", "untracked")
+	msg.Raw = json.RawMessage(`{"data":{"id":"1"},"matching_rules":[{"id":"1","tag":"news"},{"id":"2","tag":"sports"},{"id":"3","tag":"politics"},{"id":"4","tag":"untracked"}]}`)
+	views := registry.Split(msg)
+
+	tests := []struct {
+		tenant    *tenant.Tenant
+		wantRules string
+		wantRaw   string
+	}{
+		{news, "news politics", `[{"id":"1","tag":"news"},{"id":"3","tag":"politics"}]`},
+		{sports, "sports", `[{"id":"2","tag":"sports"}]`},
+	}
+	if len(views) != len(tests) {
+		t.Fatalf("%d views, want %d", len(views), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.tenant.Name, func(t *testing.T) {
+			view := views[tt.tenant]
+			var tags []string
+			for _, rule := range view.MatchingRules {
+				tags = append(tags, rule.Tag)
+			}
+			if got := strings.Join(tags, " "); got != tt.wantRules {
+				t.Errorf("rules %s, want %s", got, tt.wantRules)
+			}
+			if !strings.Contains(string(view.Raw), `"matching_rules":`+tt.wantRaw) {
+				t.Errorf("raw payload %s, want matching rules %s", view.Raw, tt.wantRaw)
+			}
+			view.Tweet.Text = "modified by " + tt.tenant.Name
+		})
+	}
+	if msg.Tweet.Text != "" || len(msg.MatchingRules) != 4 {
+		t.Errorf("views share data with the message: %+v", msg)
+	}
+}
+
+func TestWriteFailingSink(t *testing.T) {
+	failing := &tenant.Tenant{Name: "failing", Tags: []string{"a"}, Sinks: []sink.Sink{&recorder{err: errors.New("down")}}}
+	out := &recorder{}
+	working := &tenant.Tenant{Name: "working", Tags: []string{"b"}, Sinks: []sink.Sink{out}}
+	registry, err := tenant.NewRegistry(failing, working)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Write(tweet("1", "a", "b")); err == nil || !strings.Contains(err.Error(), "tenant failing") {
+		t.Errorf("err = %v, want the error of the failing tenant", err)
+	}
+	if failing.Stats().Failed != 1 || working.Stats().Delivered != 1 || len(out.messages) != 1 {
+		t.Errorf("stats %+v and %+v, want the working tenant delivered", failing.Stats(), working.Stats())
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants []*tenant.Tenant
+		wantErr string
+	}{
+		{"distinct", []*tenant.Tenant{{Name: "a", Tags: []string{"x"}, Token: "t1"}, {Name: "b", Tags: []string{"y"}, Token: "t2"}}, ""},
+		{"without tokens", []*tenant.Tenant{{Name: "a"}, {Name: "b"}}, ""},
+		{"duplicate name", []*tenant.Tenant{{Name: "a"}, {Name: "a"}}, `duplicate tenant "a"`},
+		{"shared tag", []*tenant.Tenant{{Name: "a", Tags: []string{"x"}}, {Name: "b", Tags: []string{"x"}}}, `tag "x" of "b" is already assigned to "a"`},
+		{"shared token", []*tenant.Tenant{{Name: "a", Token: "t"}, {Name: "b", Token: "t"}}, `token of "b" is already assigned to "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tenant.NewRegistry(tt.tenants...)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package watchdog monitors the goroutine count, heap usage and channel
+// depths of the process against ceilings, warning and shedding load during
+// sustained bursts before the process runs out of memory.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kalvin807/twitter-v2-stream/stream"
+)
+
+// resumeRatio is the share of each ceiling usage must fall below for an
+// overloaded Watchdog to recover, so it does not flap around a ceiling.
+const resumeRatio = 0.8
+
+// Limits are the ceilings of a Watchdog. Zero disables a ceiling.
+type Limits struct {
+	Goroutines int
+	HeapBytes  uint64
+	// ChannelDepth is the ceiling of each watched channel.
+	ChannelDepth int
+}
+
+// Warning reports a resource over its ceiling.
+type Warning struct {
+	Resource string
+	Value    uint64
+	Limit    uint64
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("watchdog: %s at %d exceeds %d", w.Resource, w.Value, w.Limit)
+}
+
+// Watchdog checks resource usage periodically. While any resource is over
+// its ceiling the Watchdog is overloaded: OnOverload is called, e.g. to pause
+// consumers, and Shed drops messages.
+type Watchdog struct {
+	limits Limits
+	// OnWarning is called with each resource over its ceiling on every
+	// check. Warnings are logged if it is nil.
+	OnWarning func(Warning)
+	// OnOverload, if set, is called with true when the Watchdog becomes
+	// overloaded and with false when it recovers.
+	OnOverload func(overloaded bool)
+
+	mu         sync.Mutex
+	channels   map[string]func() int
+	overloaded int32
+	shed       uint64
+}
+
+// New returns a Watchdog with the limits.
+func New(limits Limits) *Watchdog {
+	return &Watchdog{limits: limits, channels: make(map[string]func() int)}
+}
+
+// WatchChannel watches the depth of a channel, e.g.
+//
+//	w.WatchChannel("messages", func() int { return len(s.Messages) })
+func (w *Watchdog) WatchChannel(name string, depth func() int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.channels[name] = depth
+}
+
+// Run checks every interval until ctx is done.
+func (w *Watchdog) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check measures resource usage, emits warnings and updates whether the
+// Watchdog is overloaded. It returns the warnings.
+func (w *Watchdog) Check() []Warning {
+	var warnings []Warning
+	recovered := true
+	check := func(resource string, value, limit uint64) {
+		if limit == 0 {
+			return
+		}
+		if value > limit {
+			warnings = append(warnings, Warning{Resource: resource, Value: value, Limit: limit})
+		}
+		if float64(value) > float64(limit)*resumeRatio {
+			recovered = false
+		}
+	}
+
+	check("goroutines", uint64(runtime.NumGoroutine()), uint64(w.limits.Goroutines))
+	if w.limits.HeapBytes > 0 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		check("heap bytes", stats.HeapAlloc, w.limits.HeapBytes)
+	}
+	w.mu.Lock()
+	names := make([]string, 0, len(w.channels))
+	for name := range w.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("channel "+name, uint64(w.channels[name]()), uint64(w.limits.ChannelDepth))
+	}
+	w.mu.Unlock()
+
+	for _, warning := range warnings {
+		if w.OnWarning != nil {
+			w.OnWarning(warning)
+		} else {
+			log.Println(warning)
+		}
+	}
+	switch {
+	case len(warnings) > 0 && atomic.CompareAndSwapInt32(&w.overloaded, 0, 1):
+		if w.OnOverload != nil {
+			w.OnOverload(true)
+		}
+	case recovered && atomic.CompareAndSwapInt32(&w.overloaded, 1, 0):
+		if w.OnOverload != nil {
+			w.OnOverload(false)
+		}
+	}
+	return warnings
+}
+
+// Overloaded reports whether a resource exceeded its ceiling and has not
+// recovered yet.
+func (w *Watchdog) Overloaded() bool {
+	return atomic.LoadInt32(&w.overloaded) == 1
+}
+
+// Shed forwards the messages of in to the returned channel, dropping them
+// while the Watchdog is overloaded. The returned channel is closed when in
+// is.
+func (w *Watchdog) Shed(in <-chan *stream.StreamData) <-chan *stream.StreamData {
+	out := make(chan *stream.StreamData)
+	go func() {
+		defer close(out)
+		for msg := range in {
+			if w.Overloaded() {
+				atomic.AddUint64(&w.shed, 1)
+				continue
+			}
+			out <- msg
+		}
+	}()
+	return out
+}
+
+// Shedded returns the number of messages dropped by Shed.
+func (w *Watchdog) Shedded() uint64 {
+	return atomic.LoadUint64(&w.shed)
+}